@@ -2,9 +2,12 @@
 package address
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
+	"net/netip"
 	"strconv"
 	"strings"
 )
@@ -13,49 +16,224 @@ const DefaultPort uint16 = 25565
 
 // Address represents a Minecraft server address with a host, port and srv record.
 type Address struct {
-	host    string
-	port    uint16
-	srvHost string
-	srvPort uint16
-	srv     bool
-	portSet bool
+	host     string
+	port     uint16
+	srvHost  string
+	srvPort  uint16
+	srv      bool
+	portSet  bool
+	resolver *net.Resolver
+	lookup   LookupSRVFunc
+	records  []*net.SRV
+	ips      []net.IPAddr
+	resolved bool
+
+	srvAttempted bool
+	srvDeclined  bool
+	srvErr       error
+
+	// rng is the random source used to order SRV records, the global source is used if it is nil.
+	rng *rand.Rand
+}
+
+// Option represents a functional option for configuring an Address created by New.
+type Option func(*Address) error
+
+// WithNormalize normalizes the address after parsing it (see Address.Normalize).
+func WithNormalize() Option {
+	return func(a *Address) error {
+		a.Normalize()
+		return nil
+	}
+}
+
+// WithStrict rejects hosts that are neither an IP address nor a syntactically valid hostname
+// (see ValidateHostname).
+func WithStrict() Option {
+	return func(a *Address) error {
+		if isIP(a.host) {
+			return nil
+		}
+		return ValidateHostname(a.host)
+	}
+}
+
+// WithDefaultPort sets the port used if the address string does not contain a port,
+// e.g. for servers with a nonstandard default port. It does not mark the port as explicitly set.
+func WithDefaultPort(port uint16) Option {
+	return func(a *Address) error {
+		if !a.portSet {
+			a.port = port
+		}
+		return nil
+	}
+}
+
+// WithExplicitPort sets whether the port counts as explicitly set, which skips the SRV lookup
+// (see ResolveSRV). By default, the port counts as explicitly set if the address string contains one.
+func WithExplicitPort(explicit bool) Option {
+	return func(a *Address) error {
+		a.portSet = explicit
+		return nil
+	}
+}
+
+// NewFromParts creates a new Address from a host and a port without parsing an address string.
+// The port counts as explicitly set. IPv6 addresses may be given with or without brackets.
+func NewFromParts(host string, port uint16) *Address {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+
+	return &Address{
+		host:    host,
+		port:    port,
+		portSet: true,
+	}
 }
 
 // New creates a new Address from a given address string,
 // which can include the host and port separated by a colon (e.g., "example.com:25565").
-// If no port is specified, it uses the default Minecraft port.
-func New(addr string) (*Address, error) {
+// IPv6 addresses with a port have to be enclosed in brackets (e.g., "[2001:db8::1]:25565").
+// If no port is specified, it uses the default Minecraft port. Options are applied in order after parsing.
+func New(addr string, opts ...Option) (*Address, error) {
+	a, err := parse(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// parse parses an address string.
+func parse(addr string) (*Address, error) {
 	if addr == "" {
 		return nil, errors.New("address is empty")
 	}
 
-	if !strings.Contains(addr, ":") {
+	if host, ok := hostWithoutPort(addr); ok {
 		return &Address{
-			host: addr,
+			host: host,
 			port: DefaultPort,
 		}, nil
 	}
 
-	splitAddr := strings.Split(addr, ":")
-	if len(splitAddr) != 2 {
+	host, rawPort, err := net.SplitHostPort(addr)
+	if err != nil {
 		return nil, fmt.Errorf("invalid address: %s", addr)
 	}
 
-	port, err := strconv.ParseUint(splitAddr[1], 10, 16)
-	if err != nil {
-		return nil, fmt.Errorf("invalid port: %s", splitAddr[1])
+	if host == "" {
+		return nil, fmt.Errorf("host is empty: %s", addr)
+	}
+
+	if rawPort == "" {
+		return nil, fmt.Errorf("port is empty: %s", addr)
+	}
+
+	port, err := strconv.ParseUint(rawPort, 10, 16)
+	if err != nil || port == 0 {
+		return nil, fmt.Errorf("invalid port: %s (must be between 1 and 65535)", rawPort)
 	}
 
 	return &Address{
-		host:    splitAddr[0],
+		host:    host,
 		port:    uint16(port),
 		portSet: true,
 	}, nil
 }
 
+// hostWithoutPort returns the host of an address string that does not contain a port:
+// a host without a colon, a bare IPv6 address (e.g., "::1") or a bracketed IPv6 address (e.g., "[::1]").
+func hostWithoutPort(addr string) (string, bool) {
+	if strings.HasPrefix(addr, "[") && strings.HasSuffix(addr, "]") {
+		return addr[1 : len(addr)-1], true
+	}
+
+	if !strings.Contains(addr, ":") {
+		return addr, true
+	}
+
+	// a port cannot be told apart from the last group of an IPv6 address without brackets
+	if strings.Count(addr, ":") > 1 && isIP(addr) {
+		return addr, true
+	}
+
+	return "", false
+}
+
+// SRVAttempted reports whether ResolveSRV looked up an SRV record.
+// No lookup is done for IP addresses and addresses with an explicit port.
+func (a *Address) SRVAttempted() bool {
+	return a.srvAttempted
+}
+
+// SRVDeclined reports whether the SRV lookup found a record with the target ".",
+// which means that the domain decidedly does not offer the service (see RFC 2782).
+// The address falls back to its host and port, callers may report the domain as not offering Minecraft instead.
+func (a *Address) SRVDeclined() bool {
+	return a.srvDeclined
+}
+
+// SRVError returns the error of the last SRV lookup done by ResolveSRV, or nil if it succeeded.
+// A failed lookup is not fatal, the address falls back to its host and port.
+func (a *Address) SRVError() error {
+	return a.srvErr
+}
+
+// LookupSRVFunc looks up SRV records like net.Resolver.LookupSRV,
+// e.g. using DNS-over-HTTPS or a service discovery API.
+type LookupSRVFunc func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+
+// SetLookupSRV sets the function used to look up SRV records instead of the resolver.
+// A nil function uses the resolver (see SetResolver).
+func (a *Address) SetLookupSRV(lookup LookupSRVFunc) {
+	a.lookup = lookup
+}
+
+// WithLookupSRV sets the function used to look up SRV records (see Address.SetLookupSRV).
+func WithLookupSRV(lookup LookupSRVFunc) Option {
+	return func(a *Address) error {
+		a.SetLookupSRV(lookup)
+		return nil
+	}
+}
+
+// SetResolver sets the resolver used to look up SRV records, e.g. to query a specific DNS server.
+// A nil resolver uses net.DefaultResolver.
+func (a *Address) SetResolver(resolver *net.Resolver) {
+	a.resolver = resolver
+}
+
+// SetRand sets the random source used to order SRV records of the same priority by weight,
+// e.g. to make the order reproducible. A nil source uses the global source of math/rand.
+// The source is not safe for concurrent use, so it must not be shared with other goroutines while resolving.
+func (a *Address) SetRand(rng *rand.Rand) {
+	a.rng = rng
+}
+
+// WithRand sets the random source used to order SRV records (see Address.SetRand).
+func WithRand(rng *rand.Rand) Option {
+	return func(a *Address) error {
+		a.SetRand(rng)
+		return nil
+	}
+}
+
 // ResolveSRV resolves the SRV record for the Address's domain and updates its SRV fields.
 // ResolveSRV does not resolve the SRV record if a port has already been set.
 func (a *Address) ResolveSRV() error {
+	return a.ResolveSRVContext(context.Background())
+}
+
+// ResolveSRVContext resolves the SRV record like ResolveSRV, the lookup is aborted if the context is cancelled.
+func (a *Address) ResolveSRVContext(ctx context.Context) error {
 	if a.IsIP() {
 		return nil
 	}
@@ -65,21 +243,135 @@ func (a *Address) ResolveSRV() error {
 		return nil
 	}
 
-	_, records, err := net.LookupSRV("minecraft", "tcp", a.host)
+	a.srvAttempted = true
+	lookup := a.lookup
+	if lookup == nil {
+		lookup = a.lookupResolver().LookupSRV
+	}
+
+	host, err := ToASCII(a.host)
+	if err != nil {
+		a.srvErr = fmt.Errorf("failed to resolve SRV record: %w", err)
+		return a.srvErr
+	}
+
+	_, records, err := lookup(ctx, "minecraft", "tcp", host)
 	if err != nil {
-		return fmt.Errorf("failed to resolve SRV record: %w", err)
+		a.srvErr = fmt.Errorf("failed to resolve SRV record: %w", err)
+		return a.srvErr
 	}
+	a.srvErr = nil
 
-	if len(records) > 0 {
-		srvRecord := records[0]
+	// a single record with the target "." means that the service is decidedly not available
+	a.srvDeclined = len(records) == 1 && records[0].Target == "."
+	if a.srvDeclined {
+		// the records and the target of an earlier lookup must not be used anymore
+		a.records = nil
+		if a.srv {
+			a.srv, a.srvHost, a.srvPort = false, "", 0
+			a.resetIPs()
+		}
+		return nil
+	}
+
+	a.records = orderSRV(usableSRV(records), a.rng)
+	if len(a.records) > 0 {
+		srvRecord := a.records[0]
 		a.srvPort = srvRecord.Port
 		a.srvHost, _ = strings.CutSuffix(srvRecord.Target, ".")
 		a.srv = true
+		a.resetIPs()
 	}
 
 	return nil
 }
 
+// SRVRecords returns all SRV records found by ResolveSRV in the order they should be tried,
+// by priority and weighted random selection as described in RFC 2782.
+// The address uses the first record.
+func (a *Address) SRVRecords() []*net.SRV {
+	return a.records
+}
+
+// ResolveIPs resolves the IP addresses of the host, which is the SRV target if an SRV record was found.
+// The result is cached, later calls return the cached IPs until the host changes.
+// A host that does not exist resolves to no IPs, other lookup errors are returned and not cached.
+// net.IP cannot hold the zone of an IPv6 address, use ResolveIPAddrs to keep it.
+func (a *Address) ResolveIPs(ctx context.Context) ([]net.IP, error) {
+	addrs, err := a.ResolveIPAddrs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ipsOf(addrs), nil
+}
+
+// ResolveIPAddrs resolves the IP addresses of the host like ResolveIPs, including the zones of IPv6 addresses
+// (e.g., "fe80::1%eth0"). It shares the cache with ResolveIPs.
+func (a *Address) ResolveIPAddrs(ctx context.Context) ([]net.IPAddr, error) {
+	if a.resolved {
+		return a.ips, nil
+	}
+
+	if ip, err := netip.ParseAddr(a.Host()); err == nil {
+		a.ips, a.resolved = []net.IPAddr{{IP: ip.AsSlice(), Zone: ip.Zone()}}, true
+		return a.ips, nil
+	}
+
+	host, err := a.ASCIIHost()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve IP addresses: %w", err)
+	}
+
+	addrs, err := a.lookupResolver().LookupIPAddr(ctx, host)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		addrs, err = []net.IPAddr{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve IP addresses: %w", err)
+	}
+
+	a.ips, a.resolved = addrs, true
+	return a.ips, nil
+}
+
+// IPs returns the IP addresses cached by ResolveIPs.
+// It returns false if the IPs have not been resolved yet, an empty list means the host resolved to no IPs.
+func (a *Address) IPs() ([]net.IP, bool) {
+	if !a.resolved {
+		return nil, false
+	}
+	return ipsOf(a.ips), true
+}
+
+// IPAddrs returns the IP addresses cached by ResolveIPAddrs like IPs, including the zones of IPv6 addresses.
+func (a *Address) IPAddrs() ([]net.IPAddr, bool) {
+	return a.ips, a.resolved
+}
+
+// ipsOf returns the IPs of addrs without their zones.
+func ipsOf(addrs []net.IPAddr) []net.IP {
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips
+}
+
+// resetIPs drops the cached IPs after the host changed.
+func (a *Address) resetIPs() {
+	a.ips, a.resolved = nil, false
+}
+
+// lookupResolver returns the resolver used for lookups.
+func (a *Address) lookupResolver() *net.Resolver {
+	if a.resolver == nil {
+		return net.DefaultResolver
+	}
+	return a.resolver
+}
+
 // String returns the address string based on whether SRV record resolution is enabled.
 // If SRV resolution is enabled, it returns the SRV address; otherwise, the original address.
 func (a *Address) String() string {
@@ -97,6 +389,12 @@ func (a *Address) Host() string {
 	return a.host
 }
 
+// ASCIIHost returns the host like Host, but with internationalized domain names encoded with punycode,
+// as needed for DNS lookups and dialing (see ToASCII). Host keeps the original form for display.
+func (a *Address) ASCIIHost() (string, error) {
+	return ToASCII(a.Host())
+}
+
 // Port returns the Port of the Address.
 func (a *Address) Port() uint16 {
 	if a.srv {
@@ -107,15 +405,38 @@ func (a *Address) Port() uint16 {
 
 // SRVAddr returns the address string in the format "hostname:port" based on SRV record values.
 func (a *Address) SRVAddr() string {
-	return fmt.Sprintf("%s:%d", a.srvHost, a.srvPort)
+	return joinHostPort(a.srvHost, a.srvPort)
+}
+
+// DialAddr returns the address string in the format "hostname:port" like String,
+// but with the host in its ASCII form (see ASCIIHost), so it can be dialed.
+func (a *Address) DialAddr() (string, error) {
+	host, err := a.ASCIIHost()
+	if err != nil {
+		return "", err
+	}
+
+	return joinHostPort(host, a.Port()), nil
 }
 
 // OGAddr returns the address string in the format "hostname:port".
+// IPv6 addresses are enclosed in brackets (e.g., "[::1]:25565").
 func (a *Address) OGAddr() string {
-	return fmt.Sprintf("%s:%d", a.host, a.port)
+	return joinHostPort(a.host, a.port)
 }
 
-// IsIP checks if the host in the Address is an IP address.
+// joinHostPort joins a host and a port into an address string that can be dialed.
+func joinHostPort(host string, port uint16) string {
+	return net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// IsIP checks if the host in the Address is an IP address, including IPv6 addresses with a zone.
 func (a *Address) IsIP() bool {
-	return net.ParseIP(a.host) != nil
+	return isIP(a.host)
+}
+
+// isIP checks whether a host is an IP address. IPv6 addresses may contain a zone (e.g., "fe80::1%eth0").
+func isIP(host string) bool {
+	_, err := netip.ParseAddr(host)
+	return err == nil
 }
@@ -0,0 +1,706 @@
+package address
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var errLookup = errors.New("lookup failed")
+
+// failingLookup is an SRV lookup that always fails.
+func failingLookup(context.Context, string, string, string) (string, []*net.SRV, error) {
+	return "", nil, errLookup
+}
+
+func TestNewIPv6(t *testing.T) {
+	tests := []struct {
+		addr     string
+		host     string
+		port     uint16
+		explicit bool
+		str      string
+	}{
+		{"[2001:db8::1]:25565", "2001:db8::1", 25565, true, "[2001:db8::1]:25565"},
+		{"[2001:db8::1]:25566", "2001:db8::1", 25566, true, "[2001:db8::1]:25566"},
+		{"[::1]", "::1", DefaultPort, false, "[::1]:25565"},
+		{"::1", "::1", DefaultPort, false, "[::1]:25565"},
+		{"2001:db8::1", "2001:db8::1", DefaultPort, false, "[2001:db8::1]:25565"},
+		{"::ffff:127.0.0.1", "::ffff:127.0.0.1", DefaultPort, false, "[::ffff:127.0.0.1]:25565"},
+		{"fe80::1%eth0", "fe80::1%eth0", DefaultPort, false, "[fe80::1%eth0]:25565"},
+		{"[fe80::1%eth0]:25566", "fe80::1%eth0", 25566, true, "[fe80::1%eth0]:25566"},
+		{"127.0.0.1:25566", "127.0.0.1", 25566, true, "127.0.0.1:25566"},
+		{"example.com", "example.com", DefaultPort, false, "example.com:25565"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			a, err := New(tt.addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if a.Host() != tt.host || a.Port() != tt.port || a.portSet != tt.explicit {
+				t.Errorf("host = %q, port = %d, explicit = %t, want %q, %d and %t",
+					a.Host(), a.Port(), a.portSet, tt.host, tt.port, tt.explicit)
+			}
+			if a.String() != tt.str || a.OGAddr() != tt.str {
+				t.Errorf("String() = %q, OGAddr() = %q, want %q", a.String(), a.OGAddr(), tt.str)
+			}
+
+			// the address string can be parsed again
+			again, err := New(a.String())
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", a.String(), err)
+			}
+			if again.Host() != a.Host() || again.Port() != a.Port() {
+				t.Errorf("reparsed %q as %q and %d", a.String(), again.Host(), again.Port())
+			}
+		})
+	}
+}
+
+func TestIsIP(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"[::1]:25565", true},
+		{"fe80::1%eth0", true},
+		{"[fe80::1%eth0]:25565", true},
+		{"example.com", false},
+		{"256.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		a, err := New(tt.addr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.addr, err)
+		}
+		if got := a.IsIP(); got != tt.want {
+			t.Errorf("IsIP(%q) = %t, want %t", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestNewFromPartsIPv6(t *testing.T) {
+	for _, host := range []string{"::1", "[::1]"} {
+		a := NewFromParts(host, 25566)
+		if a.Host() != "::1" || a.String() != "[::1]:25566" {
+			t.Errorf("NewFromParts(%q) = %q (%q), want ::1 ([::1]:25566)", host, a.Host(), a.String())
+		}
+	}
+}
+
+func TestResolveSRVSkipsIPv6(t *testing.T) {
+	a, err := New("::1", WithLookupSRV(failingLookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := a.ResolveSRV(); err != nil || a.SRVAttempted() {
+		t.Errorf("error = %v, attempted = %t, want no lookup for an IP address", err, a.SRVAttempted())
+	}
+}
+
+// lookupTarget returns an SRV lookup that always finds a single record pointing to the target.
+func lookupTarget(target string, port uint16) LookupSRVFunc {
+	return func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{{Target: target, Port: port}}, nil
+	}
+}
+
+// fakeResolver returns a resolver answering every DNS query with the SRV records, without any network access.
+func fakeResolver(t *testing.T, records []*net.SRV) *net.Resolver {
+	t.Helper()
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go serveDNS(server, records)
+			t.Cleanup(func() { client.Close() })
+			return client, nil
+		},
+	}
+}
+
+// serveDNS answers DNS queries sent over a stream connection with the SRV records.
+func serveDNS(conn net.Conn, records []*net.SRV) {
+	defer conn.Close()
+
+	for {
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return
+		}
+		query := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, query); err != nil || len(query) < 12 {
+			return
+		}
+
+		// the question starts after the header and ends after the name, the type and the class
+		end := 12
+		for end < len(query) && query[end] != 0 {
+			end += int(query[end]) + 1
+		}
+		end += 5
+		if end > len(query) {
+			return
+		}
+
+		res := append([]byte{}, query[:2]...)
+		res = append(res, 0x81, 0x80, 0, 1)
+		res = binary.BigEndian.AppendUint16(res, uint16(len(records)))
+		res = append(res, 0, 0, 0, 0)
+		res = append(res, query[12:end]...)
+
+		for _, record := range records {
+			var target []byte
+			for _, label := range strings.Split(strings.TrimSuffix(record.Target, "."), ".") {
+				target = append(target, byte(len(label)))
+				target = append(target, label...)
+			}
+			target = append(target, 0)
+
+			// a pointer to the name of the question, the type SRV, the class IN and a TTL of a minute
+			res = append(res, 0xc0, 0x0c, 0, 33, 0, 1, 0, 0, 0, 60)
+			res = binary.BigEndian.AppendUint16(res, uint16(6+len(target)))
+			res = binary.BigEndian.AppendUint16(res, record.Priority)
+			res = binary.BigEndian.AppendUint16(res, record.Weight)
+			res = binary.BigEndian.AppendUint16(res, record.Port)
+			res = append(res, target...)
+		}
+
+		if _, err := conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(res)))); err != nil {
+			return
+		}
+		if _, err := conn.Write(res); err != nil {
+			return
+		}
+	}
+}
+
+func TestResolveSRVWithResolver(t *testing.T) {
+	a, err := New("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.SetResolver(fakeResolver(t, []*net.SRV{{Target: "mc.example.net.", Port: 25566, Priority: 1, Weight: 1}}))
+
+	if err := a.ResolveSRV(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if a.Host() != "mc.example.net" || a.Port() != 25566 || a.String() != "mc.example.net:25566" {
+		t.Errorf("address = %s, want mc.example.net:25566", a)
+	}
+	if a.OGAddr() != "example.com:25565" {
+		t.Errorf("OGAddr() = %s, want example.com:25565", a.OGAddr())
+	}
+}
+
+func TestResolveSRVWithLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []*net.SRV
+		want    string
+		// declined is whether the lookup is reported as declined by SRVDeclined
+		declined bool
+	}{
+		{
+			name:    "record",
+			records: []*net.SRV{{Target: "mc.example.net.", Port: 25566}},
+			want:    "mc.example.net:25566",
+		},
+		{
+			// RFC 2782: the service is decidedly not available, the address falls back to its host
+			name:     "declined",
+			records:  []*net.SRV{{Target: ".", Port: 25566}},
+			want:     "example.com:25565",
+			declined: true,
+		},
+		{
+			name:    "port 0",
+			records: []*net.SRV{{Target: "mc.example.net.", Port: 0}},
+			want:    "example.com:25565",
+		},
+		{
+			name: "unusable records skipped",
+			records: []*net.SRV{
+				{Target: "mc.example.net.", Port: 0, Priority: 0},
+				{Target: "", Port: 25566, Priority: 0},
+				{Target: "alt.example.net.", Port: 25567, Priority: 10},
+			},
+			want: "alt.example.net:25567",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queried string
+			lookup := func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+				queried = "_" + service + "._" + proto + "." + name
+				return "", tt.records, nil
+			}
+
+			// the lookup function takes precedence over the resolver
+			a, err := New("example.com", WithLookupSRV(lookup))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			a.SetResolver(fakeResolver(t, nil))
+
+			if err := a.ResolveSRV(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if queried != "_minecraft._tcp.example.com" {
+				t.Errorf("queried %q, want _minecraft._tcp.example.com", queried)
+			}
+			if a.String() != tt.want {
+				t.Errorf("address = %s, want %s", a, tt.want)
+			}
+			if a.SRVDeclined() != tt.declined {
+				t.Errorf("SRVDeclined() = %t, want %t", a.SRVDeclined(), tt.declined)
+			}
+			if targets := a.Targets(); targets[0].String() != tt.want {
+				t.Errorf("Targets() = %v, want %s first", targets, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveSRVDeclinedAfterRecords(t *testing.T) {
+	records := []*net.SRV{{Target: "mc.example.net.", Port: 25566}}
+	a, err := New("example.com", WithLookupSRV(func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", records, nil
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := a.ResolveSRV(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.String() != "mc.example.net:25566" {
+		t.Fatalf("address = %s, want mc.example.net:25566", a)
+	}
+
+	// a later lookup declining the service drops the records of the earlier one
+	records = []*net.SRV{{Target: ".", Port: 0}}
+	if err := a.ResolveSRV(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !a.SRVDeclined() || a.SRVRecords() != nil {
+		t.Errorf("SRVDeclined() = %t, SRVRecords() = %v, want true and no records", a.SRVDeclined(), a.SRVRecords())
+	}
+	if want := []Target{{Host: "example.com", Port: 25565}}; !slices.Equal(a.Targets(), want) {
+		t.Errorf("Targets() = %v, want %v", a.Targets(), want)
+	}
+	if a.String() != "example.com:25565" {
+		t.Errorf("address = %s, want example.com:25565", a)
+	}
+}
+
+func TestResolveSRVNoRecords(t *testing.T) {
+	a, err := New("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.SetResolver(fakeResolver(t, nil))
+
+	// an answer without records is reported as a missing host, the address falls back to its host
+	var dnsErr *net.DNSError
+	if err := a.ResolveSRV(); !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("error = %v, want a not found *net.DNSError", err)
+	}
+	if a.String() != "example.com:25565" {
+		t.Errorf("address = %s, want example.com:25565", a)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		addr string
+		err  string
+	}{
+		{"", "address is empty"},
+		{":-1", "host is empty"},
+		{":25565", "host is empty"},
+		{"example.com:", "port is empty"},
+		{"example.com:-1", "invalid port"},
+		{"example.com:0", "invalid port"},
+		{"example.com:65536", "invalid port"},
+		{"example.com:70000", "invalid port"},
+		{"example.com:port", "invalid port"},
+		{"[::1]:", "port is empty"},
+		{"[::1]:70000", "invalid port"},
+		{"a:b:c", "invalid address"},
+	}
+
+	for _, tt := range tests {
+		_, err := New(tt.addr)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("New(%q) error = %v, want %q", tt.addr, err, tt.err)
+		}
+	}
+}
+
+func TestNewPortRange(t *testing.T) {
+	for _, tt := range []struct {
+		addr string
+		port uint16
+	}{
+		{"example.com:1", 1},
+		{"example.com:65535", 65535},
+	} {
+		a, err := New(tt.addr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.addr, err)
+		}
+		if a.Port() != tt.port {
+			t.Errorf("%s: port = %d, want %d", tt.addr, a.Port(), tt.port)
+		}
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	tests := []struct {
+		addr string
+		ok   bool
+	}{
+		{"example.com", true},
+		{"example.com.:25565", true},
+		{"mc-1.example.com:25565", true},
+		{"münchen-mc.de", true},
+		{"127.0.0.1:25565", true},
+		{"[::1]:25565", true},
+		{"ho st:25565", false},
+		{"under_score.example.com", false},
+		{"-example.com", false},
+		{"example-.com", false},
+		{"example..com", false},
+		{strings.Repeat("a", 63) + ".com", true},
+		{strings.Repeat("a", 64) + ".com", false},
+		{strings.Repeat("a.", 126) + "a", true},
+		{strings.Repeat("a.", 127) + "a", false},
+	}
+
+	for _, tt := range tests {
+		_, err := New(tt.addr, WithStrict())
+		if tt.ok && err != nil {
+			t.Errorf("New(%q) unexpected error: %v", tt.addr, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("New(%q) did not fail", tt.addr)
+		}
+	}
+
+	// without the strict option the syntax of the hostname is not checked
+	if _, err := New("ho st:25565"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSRVError(t *testing.T) {
+	a, err := New("example.com", WithLookupSRV(failingLookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if a.SRVAttempted() || a.SRVError() != nil {
+		t.Fatalf("attempted = %t, error = %v before the lookup", a.SRVAttempted(), a.SRVError())
+	}
+
+	err = a.ResolveSRV()
+	if !errors.Is(err, errLookup) {
+		t.Fatalf("error = %v, want errLookup", err)
+	}
+
+	// the failed lookup is recorded and the address falls back to its host
+	if !a.SRVAttempted() || !errors.Is(a.SRVError(), errLookup) {
+		t.Errorf("attempted = %t, error = %v, want the failed lookup", a.SRVAttempted(), a.SRVError())
+	}
+	if a.String() != "example.com:25565" {
+		t.Errorf("address = %s, want example.com:25565", a)
+	}
+
+	// a successful lookup clears the error
+	a.SetLookupSRV(lookupTarget("mc.example.net.", 25566))
+	if err := a.ResolveSRV(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.SRVError() != nil || a.String() != "mc.example.net:25566" {
+		t.Errorf("error = %v, address = %s, want no error and mc.example.net:25566", a.SRVError(), a)
+	}
+}
+
+func TestSRVErrorResolver(t *testing.T) {
+	a, err := New("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.SetResolver(&net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errLookup
+		},
+	})
+
+	if err := a.ResolveSRV(); err == nil {
+		t.Fatal("ResolveSRV() did not fail")
+	}
+
+	var dnsErr *net.DNSError
+	if !a.SRVAttempted() || !errors.As(a.SRVError(), &dnsErr) {
+		t.Errorf("attempted = %t, error = %v, want a *net.DNSError", a.SRVAttempted(), a.SRVError())
+	}
+}
+
+func TestSRVNotAttempted(t *testing.T) {
+	for _, addr := range []string{"example.com:25565", "127.0.0.1", "[::1]"} {
+		a, err := New(addr, WithLookupSRV(failingLookup))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := a.ResolveSRV(); err != nil || a.SRVAttempted() || a.SRVError() != nil {
+			t.Errorf("%s: error = %v, attempted = %t, want no lookup", addr, err, a.SRVAttempted())
+		}
+	}
+}
+
+// fakeIPResolver returns a resolver answering A queries with the IPv4 addresses of the hosts, without any network access.
+// Hosts that are not in the map do not exist. The number of queries is counted.
+func fakeIPResolver(t *testing.T, hosts map[string]net.IP) (*net.Resolver, *atomic.Int32) {
+	t.Helper()
+
+	var queries atomic.Int32
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go serveIPs(server, hosts, &queries)
+			t.Cleanup(func() { client.Close() })
+			return client, nil
+		},
+	}
+
+	return resolver, &queries
+}
+
+// serveIPs answers DNS queries sent over a stream connection with the IPv4 addresses of the hosts.
+func serveIPs(conn net.Conn, hosts map[string]net.IP, queries *atomic.Int32) {
+	defer conn.Close()
+
+	for {
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return
+		}
+		query := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, query); err != nil || len(query) < 12 {
+			return
+		}
+		queries.Add(1)
+
+		var labels []string
+		end := 12
+		for end < len(query) && query[end] != 0 {
+			next := end + int(query[end]) + 1
+			if next > len(query) {
+				return
+			}
+			labels = append(labels, string(query[end+1:next]))
+			end = next
+		}
+		end += 5
+		if end > len(query) {
+			return
+		}
+		qtype := binary.BigEndian.Uint16(query[end-4:])
+
+		ip, ok := hosts[strings.Join(labels, ".")]
+		res := append([]byte{}, query[:2]...)
+		switch {
+		case !ok:
+			// NXDOMAIN
+			res = append(res, 0x81, 0x83, 0, 1, 0, 0)
+		case qtype == 1:
+			res = append(res, 0x81, 0x80, 0, 1, 0, 1)
+		default:
+			res = append(res, 0x81, 0x80, 0, 1, 0, 0)
+		}
+		res = append(res, 0, 0, 0, 0)
+		res = append(res, query[12:end]...)
+		if ok && qtype == 1 {
+			// a pointer to the name of the question, the type A, the class IN and a TTL of a minute
+			res = append(res, 0xc0, 0x0c, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4)
+			res = append(res, ip.To4()...)
+		}
+
+		if _, err := conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(res)))); err != nil {
+			return
+		}
+		if _, err := conn.Write(res); err != nil {
+			return
+		}
+	}
+}
+
+func TestResolveIPsCached(t *testing.T) {
+	resolver, queries := fakeIPResolver(t, map[string]net.IP{"mc.example.com": net.IPv4(10, 0, 0, 1)})
+	a, err := New("mc.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.SetResolver(resolver)
+
+	if ips, ok := a.IPs(); ok || ips != nil {
+		t.Errorf("IPs() = %v, %t before resolving, want nil, false", ips, ok)
+	}
+
+	ips, err := a.ResolveIPs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ips) != 1 || !ips[0].Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("ResolveIPs() = %v, want [10.0.0.1]", ips)
+	}
+
+	n := queries.Load()
+	if n == 0 {
+		t.Fatal("the resolver was not used")
+	}
+	if _, err := a.ResolveIPs(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queries.Load() != n {
+		t.Errorf("the IPs were resolved again: %d queries, want %d", queries.Load(), n)
+	}
+
+	if cached, ok := a.IPs(); !ok || len(cached) != 1 || !cached[0].Equal(ips[0]) {
+		t.Errorf("IPs() = %v, %t, want %v, true", cached, ok, ips)
+	}
+}
+
+func TestResolveIPsNotFound(t *testing.T) {
+	resolver, _ := fakeIPResolver(t, nil)
+	a, err := New("missing.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.SetResolver(resolver)
+
+	// a host that does not exist is resolved to nothing, which is not the same as not resolved yet
+	ips, err := a.ResolveIPs(context.Background())
+	if err != nil || len(ips) != 0 {
+		t.Errorf("ResolveIPs() = %v, %v, want no IPs and no error", ips, err)
+	}
+	if cached, ok := a.IPs(); !ok || len(cached) != 0 {
+		t.Errorf("IPs() = %v, %t, want no IPs, true", cached, ok)
+	}
+}
+
+func TestResolveIPsError(t *testing.T) {
+	a, err := New("mc.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.SetResolver(&net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("resolver unavailable")
+		},
+	})
+
+	// a failed lookup is not cached
+	if _, err := a.ResolveIPs(context.Background()); err == nil {
+		t.Error("expected an error")
+	}
+	if ips, ok := a.IPs(); ok || ips != nil {
+		t.Errorf("IPs() = %v, %t after a failed lookup, want nil, false", ips, ok)
+	}
+}
+
+func TestResolveIPsSRV(t *testing.T) {
+	resolver, _ := fakeIPResolver(t, map[string]net.IP{
+		"example.com":     net.IPv4(10, 0, 0, 1),
+		"mc.example.com":  net.IPv4(10, 0, 0, 2),
+		"alt.example.com": net.IPv4(10, 0, 0, 3),
+	})
+	a, err := New("example.com", WithLookupSRV(func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{
+			{Target: "mc.example.com.", Port: 25565, Priority: 0},
+			{Target: "alt.example.com.", Port: 25565, Priority: 10},
+		}, nil
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.SetResolver(resolver)
+
+	resolve := func(want net.IP) {
+		t.Helper()
+
+		ips, err := a.ResolveIPs(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ips) != 1 || !ips[0].Equal(want) {
+			t.Errorf("ResolveIPs() = %v, want [%s]", ips, want)
+		}
+	}
+
+	resolve(net.IPv4(10, 0, 0, 1))
+
+	// the cache is reset when the SRV target becomes the host
+	if err := a.ResolveSRV(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ips, ok := a.IPs(); ok {
+		t.Errorf("IPs() = %v, %t after resolving the SRV record, want the cache to be reset", ips, ok)
+	}
+	resolve(net.IPv4(10, 0, 0, 2))
+
+	a.UseTarget(Target{Host: "alt.example.com", Port: 25565})
+	if ips, ok := a.IPs(); ok {
+		t.Errorf("IPs() = %v, %t after changing the target, want the cache to be reset", ips, ok)
+	}
+	resolve(net.IPv4(10, 0, 0, 3))
+}
+
+func TestResolveIPsZone(t *testing.T) {
+	a, err := New("[fe80::1%eth0]:25565")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	addrs, err := a.ResolveIPAddrs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(addrs) != 1 || addrs[0].String() != "fe80::1%eth0" {
+		t.Errorf("ResolveIPAddrs() = %v, want [fe80::1%%eth0]", addrs)
+	}
+
+	// net.IP cannot hold the zone
+	ips, err := a.ResolveIPs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ips) != 1 || !ips[0].Equal(net.ParseIP("fe80::1")) {
+		t.Errorf("ResolveIPs() = %v, want [fe80::1]", ips)
+	}
+	if cached, ok := a.IPAddrs(); !ok || len(cached) != 1 || cached[0].Zone != "eth0" {
+		t.Errorf("IPAddrs() = %v, %t, want [fe80::1%%eth0], true", cached, ok)
+	}
+}
@@ -0,0 +1,98 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+const (
+	maxHostnameLength = 253
+	maxLabelLength    = 63
+)
+
+// Normalize converts the host and the SRV target of the Address into their canonical form:
+// domains are lowercased, stripped of a trailing dot and internationalized domain names are encoded
+// with punycode, IP addresses are formatted in their shortest form.
+func (a *Address) Normalize() {
+	host := normalizeHost(a.host)
+	if host != a.host {
+		a.host = host
+		if !a.srv {
+			a.resetIPs()
+		}
+	}
+
+	if a.srv {
+		a.srvHost = normalizeHost(a.srvHost)
+	}
+}
+
+// Equal checks whether two addresses point to the same host and port, ignoring differences
+// removed by Normalize. SRV records are not taken into account, see Key.
+func (a *Address) Equal(other *Address) bool {
+	if a == nil || other == nil {
+		return a == other
+	}
+
+	return normalizeHost(a.host) == normalizeHost(other.host) && a.port == other.port
+}
+
+// Key returns a stable string identifying the normalized address, suitable as a map key.
+// If an SRV record was resolved, the key also contains the SRV target (e.g., "example.com:25565>mc.example.com:25566").
+func (a *Address) Key() string {
+	key := joinHostPort(normalizeHost(a.host), a.port)
+	if a.srv {
+		key += ">" + joinHostPort(normalizeHost(a.srvHost), a.srvPort)
+	}
+
+	return key
+}
+
+// normalizeHost converts a host into its canonical form.
+func normalizeHost(host string) string {
+	// the zone is kept as is
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return ip.String()
+	}
+
+	host = strings.TrimSuffix(host, ".")
+	return strings.ToLower(toASCII(host))
+}
+
+// ValidateHostname checks whether a hostname is syntactically valid: it consists of labels of 1 to 63 letters,
+// digits and hyphens not starting or ending with a hyphen and is at most 253 characters long.
+// Internationalized domain names are validated in their punycode form. A trailing dot is allowed.
+func ValidateHostname(host string) error {
+	ascii := toASCII(strings.TrimSuffix(host, "."))
+	if ascii == "" {
+		return errors.New("hostname is empty")
+	}
+
+	if len(ascii) > maxHostnameLength {
+		return fmt.Errorf("hostname exceeds the max length of %d characters: %s", maxHostnameLength, host)
+	}
+
+	for _, label := range strings.Split(ascii, ".") {
+		if label == "" || len(label) > maxLabelLength {
+			return fmt.Errorf("hostname contains a label that is empty or longer than %d characters: %s", maxLabelLength, host)
+		}
+
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("hostname contains a label starting or ending with a hyphen: %s", host)
+		}
+
+		for _, c := range label {
+			if !isHostnameChar(c) {
+				return fmt.Errorf("hostname contains invalid character %q: %s", c, host)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isHostnameChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
@@ -0,0 +1,142 @@
+package address
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"Example.COM", "example.com:25565"},
+		{"example.com.", "example.com:25565"},
+		{"MC.Example.com.:25566", "mc.example.com:25566"},
+		{"münchen-mc.de", "xn--mnchen-mc-q9a.de:25565"},
+		{"MÜNCHEN-mc.de", "xn--mnchen-mc-q9a.de:25565"},
+		{"xn--mnchen-mc-q9a.de", "xn--mnchen-mc-q9a.de:25565"},
+		{"127.0.0.1", "127.0.0.1:25565"},
+		{"[2001:DB8:0::1]", "[2001:db8::1]:25565"},
+		{"fe80::1%eth0", "[fe80::1%eth0]:25565"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			a, err := New(tt.addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			a.Normalize()
+			if got := a.String(); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+
+			// normalizing is idempotent
+			a.Normalize()
+			if got := a.String(); got != tt.want {
+				t.Errorf("second Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithNormalize(t *testing.T) {
+	a, err := New("MÜNCHEN-mc.de.:25566", WithNormalize())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := a.String(), "xn--mnchen-mc-q9a.de:25566"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// without the option the host is kept as is
+	a, err = New("MÜNCHEN-mc.de.:25566")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := a.Host(), "MÜNCHEN-mc.de."; got != want {
+		t.Errorf("Host() = %q, want %q", got, want)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"example.com", "EXAMPLE.com", true},
+		{"example.com", "example.com.", true},
+		{"example.com", "example.com:25565", true},
+		{"münchen-mc.de", "xn--mnchen-mc-q9a.de", true},
+		{"München-MC.de.", "xn--mnchen-mc-q9a.de:25565", true},
+		{"[2001:db8::1]", "2001:DB8:0::1", true},
+		{"example.com", "example.com:25566", false},
+		{"example.com", "example.net", false},
+		{"münchen-mc.de", "munchen-mc.de", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"="+tt.b, func(t *testing.T) {
+			a, err := New(tt.a)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, err := New(tt.b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := a.Equal(b); got != tt.want {
+				t.Errorf("Equal() = %t, want %t", got, tt.want)
+			}
+			if got := b.Equal(a); got != tt.want {
+				t.Errorf("Equal() reversed = %t, want %t", got, tt.want)
+			}
+			if got := a.Key() == b.Key(); got != tt.want {
+				t.Errorf("keys %q and %q equal = %t, want %t", a.Key(), b.Key(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualNil(t *testing.T) {
+	a, err := New("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var none *Address
+	if a.Equal(nil) || none.Equal(a) || !none.Equal(nil) {
+		t.Error("a nil address is only equal to nil")
+	}
+}
+
+func TestKeySRV(t *testing.T) {
+	lookup := func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{{Target: "MC.Example.net.", Port: 25566}}, nil
+	}
+
+	a, err := New("Example.com.", WithLookupSRV(lookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := a.Key(), "example.com:25565"; got != want {
+		t.Errorf("Key() = %q before resolving the SRV record, want %q", got, want)
+	}
+
+	if err := a.ResolveSRV(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := a.Key(), "example.com:25565>mc.example.net:25566"; got != want {
+		t.Errorf("Key() = %q, want %q", got, want)
+	}
+
+	// the SRV target is normalized as well
+	a.Normalize()
+	if got, want := a.String(), "mc.example.net:25566"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
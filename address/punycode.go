@@ -0,0 +1,192 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Punycode parameters.
+// https://datatracker.ietf.org/doc/html/rfc3492#section-5
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+// acePrefix prefixes labels of internationalized domain names encoded with punycode.
+const acePrefix = "xn--"
+
+// toASCII converts an internationalized domain name into its ASCII form by encoding
+// all labels containing non-ASCII characters with punycode (e.g., "bücher.example" to "xn--bcher-kva.example").
+// Unlike full IDNA processing, the labels are only lowercased and not further mapped or validated.
+func toASCII(host string) string {
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if !isASCII(label) {
+			labels[i] = acePrefix + punycodeEncode(strings.ToLower(label))
+		}
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// ErrInvalidIDN is returned if a hostname cannot be converted into its ASCII form.
+var ErrInvalidIDN = errors.New("invalid internationalized domain name")
+
+// ToASCII converts a hostname into the ASCII form used for DNS lookups and dialing by encoding
+// all labels containing non-ASCII characters with punycode (e.g., "münchen-mc.de" to "xn--mnchen-mc-q9a.de").
+// Unlike toASCII, it rejects hostnames that cannot be looked up, e.g. because they contain empty labels,
+// control characters or spaces, or exceed the length limits after encoding, with an error wrapping ErrInvalidIDN.
+// IP addresses and ASCII hostnames are returned unchanged apart from these checks, a trailing dot is kept.
+func ToASCII(host string) (string, error) {
+	if isIP(host) {
+		return host, nil
+	}
+
+	trimmed := strings.TrimSuffix(host, ".")
+	if trimmed == "" {
+		return "", fmt.Errorf("%w: hostname is empty", ErrInvalidIDN)
+	}
+
+	labels := strings.Split(trimmed, ".")
+	for i, label := range labels {
+		if label == "" {
+			return "", fmt.Errorf("%w: hostname contains an empty label: %q", ErrInvalidIDN, host)
+		}
+
+		for _, r := range label {
+			if r == utf8.RuneError || !unicode.IsPrint(r) || unicode.IsSpace(r) {
+				return "", fmt.Errorf("%w: hostname contains invalid character %q: %q", ErrInvalidIDN, r, host)
+			}
+		}
+
+		if !isASCII(label) {
+			// longer labels cannot fit into 63 characters anyway and are not encoded to bound the work
+			if utf8.RuneCountInString(label) > maxLabelLength {
+				return "", fmt.Errorf("%w: label exceeds the max length of %d characters: %q", ErrInvalidIDN, maxLabelLength, host)
+			}
+
+			label = acePrefix + punycodeEncode(strings.ToLower(label))
+			labels[i] = label
+		}
+
+		if len(label) > maxLabelLength {
+			return "", fmt.Errorf("%w: label exceeds the max length of %d characters: %q", ErrInvalidIDN, maxLabelLength, host)
+		}
+	}
+
+	ascii := strings.Join(labels, ".")
+	if len(ascii) > maxHostnameLength {
+		return "", fmt.Errorf("%w: hostname exceeds the max length of %d characters: %q", ErrInvalidIDN, maxHostnameLength, host)
+	}
+
+	if len(trimmed) < len(host) {
+		ascii += "."
+	}
+
+	return ascii, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// punycodeEncode encodes a label with punycode as described in RFC 3492 without the ACE prefix.
+func punycodeEncode(label string) string {
+	input := []rune(label)
+
+	var output []byte
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			output = append(output, byte(r))
+		}
+	}
+
+	basic := len(output)
+	handled := basic
+	if basic > 0 {
+		output = append(output, '-')
+	}
+
+	n, delta, bias := rune(punyInitialN), 0, punyInitialBias
+	for handled < len(input) {
+		// the smallest code point that has not been handled yet
+		m := rune(utf8.MaxRune)
+		for _, r := range input {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+
+		delta += int(m-n) * (handled + 1)
+		n = m
+
+		for _, r := range input {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := min(max(k-bias, punyTMin), punyTMax)
+				if q < t {
+					break
+				}
+
+				output = append(output, punycodeDigit(t+(q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			output = append(output, punycodeDigit(q))
+
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+
+		delta++
+		n++
+	}
+
+	return string(output)
+}
+
+// punycodeAdapt is the bias adaptation function of RFC 3492.
+func punycodeAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
@@ -0,0 +1,107 @@
+package address
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// punycodeVectors are sample strings of RFC 3492 section 7.1 with their punycode encoding.
+// The Japanese and Spanish samples contain uppercase letters, which are lowercased before encoding.
+var punycodeVectors = []struct {
+	name    string
+	unicode string
+	encoded string
+}{
+	{"arabic", "ليهمابتكلموشعربي؟", "egbpdaj6bu4bxfgehfvwxn"},
+	{"chinese simplified", "他们为什么不说中文", "ihqwcrb4cv8a8dqg056pqjye"},
+	{"chinese traditional", "他們爲什麽不說中文", "ihqwctvzc91f659drss3x8bo0yb"},
+	{"japanese", "3年b組金八先生", "3b-ww4c5e180e575a65lsy2b"},
+	{"spanish", "porquénopuedensimplementehablarenespañol", "porqunopuedensimplementehablarenespaol-fmd56a"},
+}
+
+func TestPunycodeEncode(t *testing.T) {
+	for _, tt := range punycodeVectors {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := punycodeEncode(tt.unicode); got != tt.encoded {
+				t.Errorf("punycodeEncode(%q) = %q, want %q", tt.unicode, got, tt.encoded)
+			}
+		})
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"münchen-mc.de", "xn--mnchen-mc-q9a.de"},
+		{"MÜNCHEN-mc.de", "xn--mnchen-mc-q9a.de"},
+		{"bücher.example.", "xn--bcher-kva.example."},
+		{"3年B組金八先生.jp", "xn--3b-ww4c5e180e575a65lsy2b.jp"},
+		{"example.com", "example.com"},
+		{"Example.COM.", "Example.COM."},
+		{"xn--mnchen-mc-q9a.de", "xn--mnchen-mc-q9a.de"},
+		{"127.0.0.1", "127.0.0.1"},
+		{"fe80::1%eth0", "fe80::1%eth0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := ToASCII(tt.host)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToASCII(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToASCIIInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+	}{
+		{"empty", ""},
+		{"dot", "."},
+		{"empty label", "a..b"},
+		{"leading dot", ".example.com"},
+		{"newline", "evil\nhost.de"},
+		{"nul", "bad\x00.de"},
+		{"escape", "\x1b[31mred.de"},
+		{"space", "a b.de"},
+		{"zero width space", "\u200b.de"},
+		{"invalid utf-8", "\xff.de"},
+		{"label too long", strings.Repeat("a", 64) + ".de"},
+		{"encoded label too long", strings.Repeat("a", 60) + "ü.de"},
+		{"hostname too long", strings.Repeat(strings.Repeat("a", 63)+".", 4) + "de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ToASCII(tt.host); !errors.Is(err, ErrInvalidIDN) {
+				t.Errorf("ToASCII(%q) = %q, %v, want ErrInvalidIDN", tt.host, got, err)
+			}
+		})
+	}
+}
+
+func TestASCIIHost(t *testing.T) {
+	a, err := New("münchen-mc.de:25566")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the original form is kept for display
+	if got, want := a.Host(), "münchen-mc.de"; got != want {
+		t.Errorf("Host() = %q, want %q", got, want)
+	}
+	if got, err := a.ASCIIHost(); err != nil || got != "xn--mnchen-mc-q9a.de" {
+		t.Errorf("ASCIIHost() = %q, %v, want %q", got, err, "xn--mnchen-mc-q9a.de")
+	}
+	if got, err := a.DialAddr(); err != nil || got != "xn--mnchen-mc-q9a.de:25566" {
+		t.Errorf("DialAddr() = %q, %v, want %q", got, err, "xn--mnchen-mc-q9a.de:25566")
+	}
+}
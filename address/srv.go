@@ -0,0 +1,146 @@
+package address
+
+import (
+	"math/rand"
+	"net"
+	"slices"
+	"strings"
+)
+
+// Target is a host and port a server can be reached at.
+type Target struct {
+	Host string
+	Port uint16
+}
+
+// String returns the target in the format "hostname:port".
+func (t Target) String() string {
+	return joinHostPort(t.Host, t.Port)
+}
+
+// DialAddr returns the target in the format "hostname:port" with the host in its ASCII form (see ToASCII).
+func (t Target) DialAddr() (string, error) {
+	host, err := ToASCII(t.Host)
+	if err != nil {
+		return "", err
+	}
+
+	return joinHostPort(host, t.Port), nil
+}
+
+// Targets returns the targets the server can be reached at in the order they should be tried:
+// all SRV records found by ResolveSRV (see SRVRecords) or the address itself if no SRV record was found.
+func (a *Address) Targets() []Target {
+	if !a.srv {
+		return []Target{{Host: a.host, Port: a.port}}
+	}
+
+	targets := make([]Target, len(a.records))
+	for i, record := range a.records {
+		host, _ := strings.CutSuffix(record.Target, ".")
+		targets[i] = Target{Host: host, Port: record.Port}
+	}
+
+	return targets
+}
+
+// UseTarget makes the address use another of its SRV targets, e.g. if the first target is not reachable.
+// It has no effect if no SRV record was found.
+func (a *Address) UseTarget(target Target) {
+	if !a.srv {
+		return
+	}
+
+	if a.srvHost != target.Host {
+		a.resetIPs()
+	}
+
+	a.srvHost = target.Host
+	a.srvPort = target.Port
+}
+
+// usableSRV returns the records with a target and a port other than 0.
+func usableSRV(records []*net.SRV) []*net.SRV {
+	var usable []*net.SRV
+	for _, record := range records {
+		if record.Target != "." && record.Target != "" && record.Port != 0 {
+			usable = append(usable, record)
+		}
+	}
+
+	return usable
+}
+
+// orderSRV orders SRV records as described in RFC 2782: records with a lower priority come first
+// and records of the same priority are ordered by a weighted random selection.
+// If rng is nil, the global random source is used.
+func orderSRV(records []*net.SRV, rng *rand.Rand) []*net.SRV {
+	sorted := slices.Clone(records)
+	slices.SortStableFunc(sorted, func(a, b *net.SRV) int {
+		return int(a.Priority) - int(b.Priority)
+	})
+
+	ordered := make([]*net.SRV, 0, len(sorted))
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].Priority == sorted[start].Priority {
+			end++
+		}
+
+		ordered = append(ordered, orderByWeight(sorted[start:end], rng)...)
+		start = end
+	}
+
+	return ordered
+}
+
+// orderByWeight orders records of the same priority by repeatedly selecting a record
+// with a probability proportional to its weight.
+// Records with a weight of zero are placed at the beginning of the list before every selection,
+// so they have a small chance to be selected as required by RFC 2782.
+func orderByWeight(records []*net.SRV, rng *rand.Rand) []*net.SRV {
+	remaining := make([]*net.SRV, 0, len(records))
+	for _, record := range records {
+		if record.Weight == 0 {
+			remaining = append(remaining, record)
+		}
+	}
+	for _, record := range records {
+		if record.Weight != 0 {
+			remaining = append(remaining, record)
+		}
+	}
+
+	ordered := make([]*net.SRV, 0, len(records))
+	for len(remaining) > 0 {
+		var sum int
+		for _, record := range remaining {
+			sum += int(record.Weight)
+		}
+
+		// a random number between 0 and the sum of the weights, inclusive
+		n := randIntn(rng, sum+1)
+
+		selected := len(remaining) - 1
+		var running int
+		for i, record := range remaining {
+			running += int(record.Weight)
+			if running >= n {
+				selected = i
+				break
+			}
+		}
+
+		ordered = append(ordered, remaining[selected])
+		remaining = slices.Delete(remaining, selected, selected+1)
+	}
+
+	return ordered
+}
+
+func randIntn(rng *rand.Rand, n int) int {
+	if rng == nil {
+		return rand.Intn(n)
+	}
+	return rng.Intn(n)
+}
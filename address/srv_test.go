@@ -0,0 +1,197 @@
+package address
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"slices"
+	"testing"
+)
+
+func targets(records []*net.SRV) []string {
+	names := make([]string, len(records))
+	for i, record := range records {
+		names[i] = record.Target
+	}
+	return names
+}
+
+func TestOrderSRVPriority(t *testing.T) {
+	records := []*net.SRV{
+		{Target: "c.", Port: 1, Priority: 20, Weight: 5},
+		{Target: "a.", Port: 1, Priority: 0, Weight: 5},
+		{Target: "b.", Port: 1, Priority: 10, Weight: 0},
+	}
+
+	for seed := int64(0); seed < 10; seed++ {
+		got := targets(orderSRV(records, rand.New(rand.NewSource(seed))))
+		if want := []string{"a.", "b.", "c."}; !slices.Equal(got, want) {
+			t.Errorf("seed %d: order = %q, want %q", seed, got, want)
+		}
+	}
+}
+
+func TestOrderSRVKeepsRecords(t *testing.T) {
+	records := []*net.SRV{
+		{Target: "a.", Port: 1, Weight: 0},
+		{Target: "b.", Port: 1, Weight: 0},
+		{Target: "c.", Port: 1, Weight: 10},
+		{Target: "d.", Port: 1, Weight: 60},
+	}
+
+	for seed := int64(0); seed < 100; seed++ {
+		got := targets(orderSRV(records, rand.New(rand.NewSource(seed))))
+		slices.Sort(got)
+		if want := targets(records); !slices.Equal(got, want) {
+			t.Fatalf("seed %d: records = %q, want %q", seed, got, want)
+		}
+	}
+}
+
+func TestOrderSRVReproducible(t *testing.T) {
+	records := []*net.SRV{
+		{Target: "a.", Port: 1, Weight: 1},
+		{Target: "b.", Port: 1, Weight: 1},
+		{Target: "c.", Port: 1, Weight: 1},
+		{Target: "d.", Port: 1, Weight: 1},
+	}
+
+	first := targets(orderSRV(records, rand.New(rand.NewSource(42))))
+	second := targets(orderSRV(records, rand.New(rand.NewSource(42))))
+	if !slices.Equal(first, second) {
+		t.Errorf("orders differ for the same seed: %q and %q", first, second)
+	}
+}
+
+func TestOrderSRVWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []*net.SRV
+		// first is how often each target is expected to be ordered first, as a fraction of the runs
+		first map[string]float64
+	}{
+		{
+			// n is drawn from [0, 4], so the first record is selected for 0 to 3
+			name: "weighted",
+			records: []*net.SRV{
+				{Target: "heavy.", Port: 1, Weight: 3},
+				{Target: "light.", Port: 1, Weight: 1},
+			},
+			first: map[string]float64{"heavy.": 0.8, "light.": 0.2},
+		},
+		{
+			// the zero weight record is placed first and selected only for n = 0
+			name: "zero weight",
+			records: []*net.SRV{
+				{Target: "weighted.", Port: 1, Weight: 9},
+				{Target: "zero.", Port: 1, Weight: 0},
+			},
+			first: map[string]float64{"weighted.": 0.9, "zero.": 0.1},
+		},
+		{
+			// with only zero weights every selection takes the first remaining record
+			name: "all zero",
+			records: []*net.SRV{
+				{Target: "a.", Port: 1, Weight: 0},
+				{Target: "b.", Port: 1, Weight: 0},
+			},
+			first: map[string]float64{"a.": 1},
+		},
+	}
+
+	const runs = 10000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			counts := make(map[string]int)
+			for i := 0; i < runs; i++ {
+				counts[orderSRV(tt.records, rng)[0].Target]++
+			}
+
+			for target, want := range tt.first {
+				got := float64(counts[target]) / runs
+				if got < want-0.03 || got > want+0.03 {
+					t.Errorf("%s first in %.3f of the runs, want %.3f", target, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveSRVWithRand(t *testing.T) {
+	lookup := func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{
+			{Target: "a.example.com.", Port: 25565, Weight: 1},
+			{Target: "b.example.com.", Port: 25566, Weight: 1},
+			{Target: "c.example.com.", Port: 25567, Weight: 1},
+		}, nil
+	}
+
+	resolve := func() []string {
+		a, err := New("example.com", WithLookupSRV(lookup), WithRand(rand.New(rand.NewSource(7))))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := a.ResolveSRV(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return targets(a.SRVRecords())
+	}
+
+	first, second := resolve(), resolve()
+	if len(first) != 3 || !slices.Equal(first, second) {
+		t.Errorf("orders differ for the same seed: %q and %q", first, second)
+	}
+}
+
+func TestTargets(t *testing.T) {
+	lookup := func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{
+			{Target: "backup.example.com.", Port: 25566, Priority: 10},
+			{Target: "main.example.com.", Port: 25565, Priority: 0},
+		}, nil
+	}
+
+	a, err := New("example.com", WithLookupSRV(lookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// without SRV records the address itself is the only target
+	if got, want := a.Targets(), []Target{{Host: "example.com", Port: 25565}}; !slices.Equal(got, want) {
+		t.Errorf("Targets() = %v, want %v", got, want)
+	}
+
+	if err := a.ResolveSRV(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Target{{Host: "main.example.com", Port: 25565}, {Host: "backup.example.com", Port: 25566}}
+	if got := a.Targets(); !slices.Equal(got, want) {
+		t.Errorf("Targets() = %v, want %v", got, want)
+	}
+
+	a.UseTarget(want[1])
+	if got := a.SRVAddr(); got != "backup.example.com:25566" {
+		t.Errorf("SRVAddr() = %q, want %q", got, "backup.example.com:25566")
+	}
+	if got := a.String(); got != "backup.example.com:25566" {
+		t.Errorf("String() = %q, want %q", got, "backup.example.com:25566")
+	}
+	// the order of the targets does not change with the target in use
+	if got := a.Targets(); !slices.Equal(got, want) {
+		t.Errorf("Targets() = %v, want %v", got, want)
+	}
+}
+
+func TestUseTargetWithoutSRV(t *testing.T) {
+	a, err := New("example.com:25570")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a.UseTarget(Target{Host: "other.example.com", Port: 25565})
+	if got := a.String(); got != "example.com:25570" {
+		t.Errorf("String() = %q, want %q", got, "example.com:25570")
+	}
+}
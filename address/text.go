@@ -0,0 +1,60 @@
+package address
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MarshalText encodes the address as "host:port", omitting the port if it was not explicitly set,
+// so the address round-trips through UnmarshalText with the same SRV behaviour.
+// It implements encoding.TextMarshaler, which is also used for JSON.
+func (a Address) MarshalText() ([]byte, error) {
+	if a.portSet {
+		return []byte(joinHostPort(a.host, a.port)), nil
+	}
+
+	return []byte(a.host), nil
+}
+
+// UnmarshalText parses an address like New.
+// It implements encoding.TextUnmarshaler, which is also used for JSON.
+func (a *Address) UnmarshalText(text []byte) error {
+	parsed, err := New(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = *parsed
+	return nil
+}
+
+// LogString returns the address like String, but with non-printable characters, backslashes and invalid UTF-8
+// escaped (e.g., "evil\nhost:25565" becomes `evil\nhost:25565`), so user-supplied addresses are safe to log.
+func (a *Address) LogString() string {
+	return escapeNonPrintable(a.String())
+}
+
+// escapeNonPrintable escapes all characters of s that are not printable using Go escape sequences.
+func escapeNonPrintable(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\\':
+			b.WriteString(`\\`)
+		case unicode.IsPrint(r):
+			b.WriteRune(r)
+		default:
+			quoted := strconv.QuoteRuneToASCII(r)
+			b.WriteString(quoted[1 : len(quoted)-1])
+		}
+		i += size
+	}
+
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package address
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalText(t *testing.T) {
+	tests := []struct {
+		addr     string
+		text     string
+		explicit bool
+	}{
+		{"example.com", "example.com", false},
+		{"example.com:25565", "example.com:25565", true},
+		{"example.com:25566", "example.com:25566", true},
+		{"127.0.0.1", "127.0.0.1", false},
+		{"127.0.0.1:25566", "127.0.0.1:25566", true},
+		{"::1", "::1", false},
+		{"[::1]", "::1", false},
+		{"[2001:db8::1]:25565", "[2001:db8::1]:25565", true},
+		{"fe80::1%eth0", "fe80::1%eth0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			a, err := New(tt.addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			text, err := a.MarshalText()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(text) != tt.text {
+				t.Errorf("MarshalText() = %q, want %q", text, tt.text)
+			}
+
+			var parsed Address
+			if err := parsed.UnmarshalText(text); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parsed.Host() != a.Host() || parsed.Port() != a.Port() || parsed.portSet != tt.explicit {
+				t.Errorf("unmarshalled %s (explicit port: %t), want %s (%t)", parsed.String(), parsed.portSet, a, tt.explicit)
+			}
+		})
+	}
+}
+
+func TestMarshalTextIgnoresSRV(t *testing.T) {
+	a, err := New("example.com", WithLookupSRV(lookupTarget("mc.example.net.", 25566)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := a.ResolveSRV(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the SRV target is not stored, so it is looked up again after unmarshalling
+	text, _ := a.MarshalText()
+	if string(text) != "example.com" {
+		t.Errorf("MarshalText() = %q, want %q", text, "example.com")
+	}
+}
+
+func TestAddressJSON(t *testing.T) {
+	type config struct {
+		Server  Address   `json:"server"`
+		Servers []Address `json:"servers"`
+		Backup  *Address  `json:"backup"`
+	}
+
+	const raw = `{"server":"example.com","servers":["[::1]:25566","127.0.0.1"],"backup":"example.net:25565"}`
+
+	var c config
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.Server.String() != "example.com:25565" || c.Servers[0].String() != "[::1]:25566" ||
+		c.Servers[1].String() != "127.0.0.1:25565" || c.Backup.String() != "example.net:25565" {
+		t.Errorf("unmarshalled %+v", c)
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != raw {
+		t.Errorf("json.Marshal() = %s, want %s", b, raw)
+	}
+}
+
+func TestUnmarshalTextInvalid(t *testing.T) {
+	for _, text := range []string{"", "example.com:70000", "a:b:c"} {
+		var a Address
+		if err := a.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) did not fail", text)
+		}
+	}
+
+	var c struct {
+		Server Address `json:"server"`
+	}
+	if err := json.Unmarshal([]byte(`{"server":"example.com:0"}`), &c); err == nil {
+		t.Error("json.Unmarshal() did not fail")
+	}
+}
+
+func TestLogString(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"example.com", "example.com:25565"},
+		{"münchen-mc.de", "münchen-mc.de:25565"},
+		{"evil\nhost", `evil\nhost:25565`},
+		{"tab\there", `tab\there:25565`},
+		{"bell\a", `bell\a:25565`},
+		{"\x1b[31mred", `\x1b[31mred:25565`},
+		{"back\\slash", `back\\slash:25565`},
+		{"bad\xffutf8", `bad\xffutf8:25565`},
+		{"\u202eevil", `\u202eevil:25565`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			a, err := New(tt.addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := a.LogString(); got != tt.want {
+				t.Errorf("LogString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
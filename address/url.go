@@ -0,0 +1,62 @@
+package address
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Scheme is the URL scheme of Minecraft server addresses (e.g., "minecraft://play.example.com:25565").
+const Scheme = "minecraft"
+
+// ParseURL creates a new Address from a URL like "minecraft://play.example.com:25565".
+// Besides the minecraft scheme, the tcp scheme and addresses without a scheme are accepted.
+// The host may be percent-encoded (e.g., "minecraft://pl%61y.example.com") and the default port is used
+// if none is specified.
+// URLs containing user information, a path, a query or a fragment are rejected.
+func ParseURL(s string, opts ...Option) (*Address, error) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok {
+		return New(s, opts...)
+	}
+
+	// url.Parse only accepts percent-encoded hosts that decode to non-ASCII characters,
+	// so the authority is split off and decoded here
+	scheme = strings.ToLower(scheme)
+	if scheme != Scheme && scheme != "tcp" {
+		return nil, fmt.Errorf("unsupported address url scheme: %s", scheme)
+	}
+
+	authority, path := rest, ""
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+
+	if strings.Contains(authority, "@") {
+		return nil, fmt.Errorf("address url must not contain user information")
+	}
+
+	var query string
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path, query = path[:i], path[i:]
+	}
+
+	if path != "" && path != "/" {
+		return nil, fmt.Errorf("address url must not contain a path: %s", path)
+	}
+
+	if query != "" {
+		return nil, fmt.Errorf("address url must not contain a query or fragment: %s", s)
+	}
+
+	host, err := url.PathUnescape(authority)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address url: %w", err)
+	}
+
+	if strings.ContainsAny(host, "/?#@") {
+		return nil, fmt.Errorf("invalid address url host: %q", host)
+	}
+
+	return New(host, opts...)
+}
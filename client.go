@@ -1,8 +1,10 @@
 package mclib
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"time"
 
@@ -30,12 +32,19 @@ const (
 
 // Client represents a client for interacting with Minecraft servers through the Minecraft protocol.
 type Client struct {
-	addr     *address.Address
-	timeout  time.Duration
-	srv      bool
-	protocol int32
-	state    ConnState
-	conn     net.Conn
+	addr        *address.Address
+	timeout     time.Duration
+	idleTimeout time.Duration
+	srv         bool
+	srvFallback bool
+	resolver    *net.Resolver
+	lookupSRV   address.LookupSRVFunc
+	protocol    int32
+	parseOpts   slp.ParseOptions
+	trace       io.Writer
+	state       ConnState
+	conn        *packet.Conn
+	scratch     *packet.OutboundPacket
 }
 
 // ClientOption represents a functional option for configuring a Client instance.
@@ -48,6 +57,14 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithIdleTimeout sets the maximum time the server may stay silent while sending a packet.
+// Unlike the timeout, it is refreshed whenever data is received. See packet.Conn.SetIdleTimeout.
+func WithIdleTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.idleTimeout = timeout
+	}
+}
+
 // WithProtocolVersion sets a custom Minecraft protocol version.
 func WithProtocolVersion(protocol int32) ClientOption {
 	return func(c *Client) {
@@ -62,10 +79,49 @@ func WithoutSRV() ClientOption {
 	}
 }
 
+// WithSRVFallback makes the client try all SRV targets in order until a connection succeeds,
+// instead of only the first one like the Notchian client.
+func WithSRVFallback() ClientOption {
+	return func(c *Client) {
+		c.srvFallback = true
+	}
+}
+
+// WithResolver sets a custom resolver used for SRV record lookups and for resolving the host when connecting.
+func WithResolver(resolver *net.Resolver) ClientOption {
+	return func(c *Client) {
+		c.resolver = resolver
+	}
+}
+
+// WithLookupSRV sets a custom function for SRV record lookups, e.g. using DNS-over-HTTPS.
+// It takes precedence over the resolver set by WithResolver for SRV lookups.
+func WithLookupSRV(lookup address.LookupSRVFunc) ClientOption {
+	return func(c *Client) {
+		c.lookupSRV = lookup
+	}
+}
+
+// WithParseOptions sets the options used to parse status responses,
+// e.g. WithoutRaw to not keep a copy of the raw response.
+func WithParseOptions(opts slp.ParseOptions) ClientOption {
+	return func(c *Client) {
+		c.parseOpts = opts
+	}
+}
+
+// WithTrace writes a dump of every packet sent and received by the client to w,
+// e.g. to log what a misbehaving server sent. See packet.Conn.SetTrace.
+func WithTrace(w io.Writer) ClientOption {
+	return func(c *Client) {
+		c.trace = w
+	}
+}
+
 // WithConnection set a custom already connected connection.
 func WithConnection(conn net.Conn) ClientOption {
 	return func(c *Client) {
-		c.conn = conn
+		c.conn = packet.NewConn(conn, c.timeout)
 		c.state = Connected
 	}
 }
@@ -78,8 +134,10 @@ func WithAddress(addr *address.Address) ClientOption {
 }
 
 // NewClient creates a new Client for pinging a Minecraft server at the specified address.
+// The address may also be given as a URL like "minecraft://play.example.com:25565".
 func NewClient(addr string, opts ...ClientOption) (*Client, error) {
-	a, err := address.New(addr)
+	// plain addresses are passed on to address.New
+	a, err := address.ParseURL(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +153,13 @@ func NewClient(addr string, opts ...ClientOption) (*Client, error) {
 		opt(client)
 	}
 
+	// the timeouts and the trace may have been set after the connection
+	if client.conn != nil {
+		client.conn.SetTimeout(client.timeout)
+		client.conn.SetIdleTimeout(client.idleTimeout)
+		client.conn.SetTrace(client.trace)
+	}
+
 	return client, nil
 }
 
@@ -120,30 +185,42 @@ func (c *Client) StatusPing() (*slp.Response, error) {
 
 // Status performs a status query to the Minecraft server and retrieves server information.
 func (c *Client) Status() (*slp.Response, error) {
-	if err := c.connectAndHandshake(StatusState); err != nil {
+	return c.StatusContext(context.Background())
+}
+
+// StatusContext performs a status query like Status. If the context is cancelled,
+// connecting is aborted or the connection is closed and the context's error is returned.
+func (c *Client) StatusContext(ctx context.Context) (*slp.Response, error) {
+	if err := c.connectAndHandshake(ctx, StatusState); err != nil {
 		return nil, err
 	}
 
+	stop := c.interruptOn(ctx)
+	res, err := c.status()
+	if ctxErr := stop(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	return res, err
+}
+
+// status sends the status request and receives the response.
+func (c *Client) status() (*slp.Response, error) {
 	if err := c.sendStatusRequest(); err != nil {
 		return nil, err
 	}
 
-	rawRes, err := c.recvStatusResponse()
+	res, err := c.recvStatusResponse()
 	if err != nil {
 		return nil, fmt.Errorf("failed to receive status response: %w", err)
 	}
 
-	res, err := slp.NewResponse(rawRes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse json response: %w", err)
-	}
-
 	return res, nil
 }
 
 // Ping performs a ping operation to the Minecraft server and returns the latency in milliseconds.
 func (c *Client) Ping() (int, error) {
-	if err := c.connectAndHandshake(StatusState); err != nil {
+	if err := c.connectAndHandshake(context.Background(), StatusState); err != nil {
 		return 0, err
 	}
 
@@ -173,25 +250,118 @@ func (c *Client) Ping() (int, error) {
 
 // LoginError tries to trigger an exception in the servers packet parser.
 // The error response can be used to fingerprint the server software.
+// It is empty if the server responded with another packet than a disconnect, whose id is returned.
 func (c *Client) LoginError() (string, int32, error) {
-	if err := c.connectAndHandshake(LoginState); err != nil {
+	return c.LoginErrorContext(context.Background())
+}
+
+// LoginErrorContext tries to trigger an exception like LoginError. If the context is cancelled,
+// connecting is aborted or the connection is closed and the context's error is returned.
+func (c *Client) LoginErrorContext(ctx context.Context) (string, int32, error) {
+	if err := c.connectAndHandshake(ctx, LoginState); err != nil {
 		return "", 0, err
 	}
 
-	if err := c.sendLoginStartCrash("mclib", make([]byte, 16)); err != nil {
+	stop := c.interruptOn(ctx)
+	reason, id, err := c.loginError()
+	if ctxErr := stop(); ctxErr != nil {
+		return "", 0, ctxErr
+	}
+
+	return reason, id, err
+}
+
+// loginError sends the malformed login start packet and receives the response.
+func (c *Client) loginError() (string, int32, error) {
+	if err := c.sendLoginStart("mclib", [16]byte{}, true); err != nil {
 		return "", 0, err
 	}
 
-	res, err := packet.NewInboundPacket(c.conn, c.timeout)
+	res, err := c.conn.ReadPacket()
 	if err != nil {
 		return "", 0, err
 	}
+	defer res.Release()
+
+	// only disconnect packets carry a reason, e.g. the set compression packet starts with a VarInt
+	var reason string
+	if res.ID() == c.ids().LoginDisconnect {
+		reason, err = res.ReadString()
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	if err := c.Close(); err != nil {
+		return "", 0, err
+	}
+
+	return reason, res.ID(), nil
+}
+
+// LoginAcknowledgeError logs in with a valid login start packet and, if the server accepts the login
+// without encryption like offline mode servers do, tries to trigger an exception in the servers
+// packet parser with a malformed login acknowledged packet. The error response can be used to fingerprint servers
+// not rejecting the malformed login start packet of LoginError. If the server enables compression,
+// the following packets are compressed. If the login is not accepted,
+// the id of the received packet is returned with an empty response.
+// It requires a protocol version with a configuration state (see packet.ConfigurationProtocol).
+func (c *Client) LoginAcknowledgeError() (string, int32, error) {
+	return c.LoginAcknowledgeErrorContext(context.Background())
+}
+
+// LoginAcknowledgeErrorContext tries to trigger an exception like LoginAcknowledgeError. If the context is cancelled,
+// connecting is aborted or the connection is closed and the context's error is returned.
+func (c *Client) LoginAcknowledgeErrorContext(ctx context.Context) (string, int32, error) {
+	if c.ids().LoginAcknowledged < 0 {
+		return "", 0, fmt.Errorf("protocol version %d has no configuration state", c.protocol)
+	}
+
+	if err := c.connectAndHandshake(ctx, LoginState); err != nil {
+		return "", 0, err
+	}
+
+	stop := c.interruptOn(ctx)
+	reason, id, err := c.loginAcknowledgeError()
+	if ctxErr := stop(); ctxErr != nil {
+		return "", 0, ctxErr
+	}
+
+	return reason, id, err
+}
+
+// loginAcknowledgeError logs in and sends the malformed login acknowledged packet once the login succeeded.
+func (c *Client) loginAcknowledgeError() (string, int32, error) {
+	if err := c.sendLoginStart("mclib", [16]byte{}, false); err != nil {
+		return "", 0, err
+	}
 
-	reason, err := res.ReadString()
+	res, err := c.recvLoginResponse()
 	if err != nil {
 		return "", 0, err
 	}
 
+	if res.ID() == c.ids().LoginSuccess {
+		res.Release()
+		if err := c.sendLoginAcknowledgedCrash(); err != nil {
+			return "", 0, err
+		}
+
+		res, err = c.conn.ReadPacket()
+		if err != nil {
+			return "", 0, err
+		}
+	}
+	defer res.Release()
+
+	var reason string
+	if res.ID() == c.ids().LoginDisconnect {
+		reason, err = res.ReadString()
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
 	if err := c.Close(); err != nil {
 		return "", 0, err
 	}
@@ -199,6 +369,55 @@ func (c *Client) LoginError() (string, int32, error) {
 	return reason, res.ID(), nil
 }
 
+// recvLoginResponse receives the response to the login start packet.
+// A set compression packet enables compression and the packet following it is returned instead.
+func (c *Client) recvLoginResponse() (*packet.InboundPacket, error) {
+	for {
+		res, err := c.conn.ReadPacket()
+		if err != nil {
+			return nil, err
+		}
+
+		if res.ID() != c.ids().LoginCompression {
+			return res, nil
+		}
+
+		threshold, err := res.ReadVarInt()
+		res.Release()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read compression threshold: %w", err)
+		}
+
+		c.conn.EnableCompression(int(threshold))
+	}
+}
+
+// interruptOn closes the connection once the context is done, which aborts any pending read or write.
+// The returned function stops watching the context and returns the context's error
+// if the connection was closed because of it.
+func (c *Client) interruptOn(ctx context.Context) func() error {
+	conn := c.conn
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+
+	return func() error {
+		if stop() {
+			return nil
+		}
+
+		// the connection is already closed
+		c.conn = nil
+		c.state = Idle
+		return ctx.Err()
+	}
+}
+
+// Address returns the address of the server, including the results of the SRV lookup done when connecting.
+func (c *Client) Address() *address.Address {
+	return c.addr
+}
+
 // Close safely closes the TCP connection to the Minecraft server.
 func (c *Client) Close() error {
 	if c.conn == nil {
@@ -225,14 +444,14 @@ func (c *Client) sendHandshake(state int32) error {
 	//
 	// https://wiki.vg/Server_List_Ping#Handshake
 
-	handshake := packet.NewOutboundPacket(packet.HandshakeID)
+	handshake := c.outbound(c.ids().Handshake)
 	handshake.WriteVarInt(c.protocol)
 	if err := handshake.WriteString(c.addr.Host()); err != nil {
 		return fmt.Errorf("failed to write host: %w", err)
 	}
 	handshake.WriteShort(int16(c.addr.Port()))
 	handshake.WriteVarInt(state)
-	if err := handshake.Write(c.conn); err != nil {
+	if err := c.conn.WritePacket(handshake); err != nil {
 		return fmt.Errorf("failed to send handshake: %w", err)
 	}
 
@@ -247,47 +466,73 @@ func (c *Client) sendStatusRequest() error {
 	//
 	// https://wiki.vg/Protocol#Status_Request
 
-	statusRequest := packet.NewOutboundPacket(packet.StatusID)
-	if err := statusRequest.Write(c.conn); err != nil {
+	statusRequest := c.outbound(c.ids().StatusRequest)
+	if err := c.conn.WritePacket(statusRequest); err != nil {
 		return fmt.Errorf("failed to send status request: %w", err)
 	}
 
 	return nil
 }
 
-// recvResponse receives the status response from the Minecraft server.
-func (c *Client) recvStatusResponse() (string, error) {
+// isStatusDisconnect checks whether a packet received in the status state is a disconnect packet.
+// Servers send the play disconnect packet of their own version, which may differ from the protocol version
+// of the client, so the ids used before and since 1.20.2 are accepted as well.
+func isStatusDisconnect(id int32, ids packet.IDTable) bool {
+	return id == ids.Disconnect || id == packet.DisconnectID || id == packet.LegacyDisconnectID
+}
+
+// recvStatusResponse receives the status response from the Minecraft server
+// and decodes it directly from the packet body.
+func (c *Client) recvStatusResponse() (*slp.Response, error) {
 	// status response:
 	//		packet id     (VarInt) (0)
 	//		json response (string)
 	//
 	// https://wiki.vg/Server_List_Ping#Status_Response
 
-	res, err := packet.NewInboundPacket(c.conn, c.timeout)
+	res, err := c.conn.ReadPacketStream()
 	if err != nil {
-		return "", fmt.Errorf("failed to read status response: %w", err)
+		return nil, fmt.Errorf("failed to read status response: %w", err)
 	}
+	defer res.Close()
 
+	ids := c.ids()
 	id := res.ID()
-	if id == packet.DisconnectID || id == packet.LegacyDisconnectID {
-		msg, err := res.ReadString()
+	if isStatusDisconnect(id, ids) {
+		disconnect, err := res.Buffer()
 		if err != nil {
-			return "", fmt.Errorf("failed to read disconnect reason: %w", err)
+			return nil, fmt.Errorf("failed to read disconnect packet: %w", err)
 		}
+		defer disconnect.Release()
+
+		msg, err := disconnect.ReadString()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read disconnect reason: %w", err)
+		}
+
+		return nil, fmt.Errorf("disconnect packet from server: %s", msg)
+	}
 
-		return "", fmt.Errorf("disconnect packet from server: %s", msg)
+	if id != ids.StatusResponse {
+		return nil, fmt.Errorf("response packet contains bad packet id: %d", res.ID())
 	}
 
-	if id != packet.StatusID {
-		return "", fmt.Errorf("response packet contains bad packet id: %d", res.ID())
+	length, err := res.ReadVarInt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status response length: %w", err)
 	}
 
-	resBody, err := res.ReadString()
+	if int(length) > packet.MaxStringByteLength {
+		return nil, fmt.Errorf("status response exceeds the max string length: %d", length)
+	}
+
+	// the response is read directly from the connection instead of buffering the whole packet first
+	status, err := slp.DecodeWithOptions(io.LimitReader(res.Body(), int64(length)), c.parseOpts)
 	if err != nil {
-		return "", fmt.Errorf("failed to read status response body: %w", err)
+		return nil, fmt.Errorf("failed to parse json response: %w", err)
 	}
 
-	return resBody, nil
+	return status, nil
 }
 
 // sendPing sends a ping packet to the Minecraft server to measure latency.
@@ -298,9 +543,9 @@ func (c *Client) sendPing(timestamp int64) error {
 	//
 	// https://wiki.vg/Server_List_Ping#Ping_Request
 
-	ping := packet.NewOutboundPacket(packet.PingID)
+	ping := c.outbound(c.ids().Ping)
 	ping.WriteLong(timestamp)
-	if err := ping.Write(c.conn); err != nil {
+	if err := c.conn.WritePacket(ping); err != nil {
 		return fmt.Errorf("failed to send ping: %w", err)
 	}
 
@@ -315,12 +560,13 @@ func (c *Client) recvPong() (int64, error) {
 	//
 	// https://wiki.vg/Server_List_Ping#Pong_Response
 
-	pong, err := packet.NewInboundPacket(c.conn, c.timeout)
+	pong, err := c.conn.ReadPacket()
 	if err != nil {
 		return 0, fmt.Errorf("failed to read pong: %w", err)
 	}
+	defer pong.Release()
 
-	if pong.ID() != packet.PongID {
+	if pong.ID() != c.ids().Pong {
 		return 0, fmt.Errorf("response packet contains bad packet id: %d", pong.ID())
 	}
 
@@ -332,44 +578,100 @@ func (c *Client) recvPong() (int64, error) {
 	return id, nil
 }
 
-// sendLoginStartCrash sends a bad login start packet to the server to trigger an error.
-func (c *Client) sendLoginStartCrash(name string, uuid []byte) error {
-	// login start crash packet:
+// sendLoginStart sends a login start packet in the layout of the protocol version of the client to the server.
+// With padding, an unexpected byte is appended to trigger an error in the servers packet parser.
+func (c *Client) sendLoginStart(name string, uuid [16]byte, padding bool) error {
+	// login start packet:
 	//		packet id (VarInt) (0)
 	//		name      (string)
-	//		uuid      (uuid)
+	//
+	//	1.19 - 1.19.2 (759 - 760):
+	//		has signature data (bool) (false)
+	//
+	//	1.19.1 - 1.19.2 (760):
+	//		has uuid (bool) (false)
+	//
+	//	1.19.3 - 1.20.1 (761 - 763):
+	//		has uuid (bool) (true)
+	//		uuid     (uuid)
+	//
+	//	1.20.2+ (764+):
+	//		uuid (uuid)
 	//
 	// unexpected:
 	//		padding (byte)
 	//
 	// https://wiki.vg/Protocol#Login_Start
 
-	if len(name) > 16 {
-		return fmt.Errorf("player name cannot be longer than 16 characters: length: %d", len(name))
+	login := c.outbound(c.ids().LoginStart)
+	if err := login.WriteStringN(name, 16); err != nil {
+		return fmt.Errorf("invalid player name: %w", err)
 	}
 
-	if len(uuid) != 16 {
-		return fmt.Errorf("player uuid has to be 16 bytes long: length: %d", len(uuid))
+	switch {
+	case c.protocol >= packet.ConfigurationProtocol:
+		login.WriteUUID(uuid)
+
+	case c.protocol >= 761:
+		login.WriteBool(true)
+		login.WriteUUID(uuid)
+
+	case c.protocol >= 760:
+		login.WriteBool(false)
+		login.WriteBool(false)
+
+	case c.protocol >= 759:
+		login.WriteBool(false)
 	}
 
-	login := packet.NewOutboundPacket(packet.LoginStartID)
-	if err := login.WriteString(name); err != nil {
-		return err
+	if padding {
+		login.WriteByte(0)
 	}
-	login.WriteBytes(uuid)
-	login.WriteByte(0)
 
-	if err := login.Write(c.conn); err != nil {
+	if err := c.conn.WritePacket(login); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// sendLoginAcknowledgedCrash sends a bad login acknowledged packet to the server to trigger an error.
+func (c *Client) sendLoginAcknowledgedCrash() error {
+	// login acknowledged crash packet:
+	//		packet id (VarInt) (3)
+	//
+	// unexpected:
+	//		padding (byte)
+	//
+	// https://wiki.vg/Protocol#Login_Acknowledged
+
+	ack := c.outbound(c.ids().LoginAcknowledged)
+	ack.WriteByte(0)
+
+	return c.conn.WritePacket(ack)
+}
+
+// ids returns the packet ids of the protocol version used by the client.
+func (c *Client) ids() packet.IDTable {
+	return packet.IDs(c.protocol)
+}
+
+// outbound returns the scratch packet of the client emptied and set to the given id.
+// Reusing it avoids allocating a new packet for every request.
+func (c *Client) outbound(id int32) *packet.OutboundPacket {
+	if c.scratch == nil {
+		c.scratch = packet.NewOutboundPacket(id)
+	}
+
+	c.scratch.Reset(id)
+	return c.scratch
+}
+
 // connectAndHandshake handles the connection setup and handshake with the Minecraft server.
-func (c *Client) connectAndHandshake(state int32) error {
+// Connecting is aborted if the context is cancelled.
+func (c *Client) connectAndHandshake(ctx context.Context, state int32) error {
 	if c.state < Connected {
-		if err := c.connect(); err != nil {
+		if err := c.connect(ctx); err != nil {
 			return err
 		}
 	}
@@ -380,24 +682,74 @@ func (c *Client) connectAndHandshake(state int32) error {
 		}
 	}
 
+	if state == StatusState {
+		c.conn.SetMaxPacketLength(packet.MaxStatusPacketLength)
+	} else {
+		c.conn.SetMaxPacketLength(packet.MaxPacketLength)
+	}
+
 	return nil
 }
 
+// dial connects to the address of the client. With SRV fallback enabled, all SRV targets are tried in order
+// and the address is updated to the target that was connected to.
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: c.timeout, Resolver: c.resolver}
+	if !c.srvFallback {
+		addr, err := c.addr.DialAddr()
+		if err != nil {
+			return nil, err
+		}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+
+	var errs []error
+	for _, target := range c.addr.Targets() {
+		addr, err := target.DialAddr()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		c.addr.UseTarget(target)
+		return conn, nil
+	}
+
+	return nil, errors.Join(errs...)
+}
+
 // connect establishes a connection to the Minecraft server.
-func (c *Client) connect() error {
+func (c *Client) connect(ctx context.Context) error {
 	if c.state > Idle {
 		return errors.New("client is already connected")
 	}
 
 	if c.srv {
-		_ = c.addr.ResolveSRV()
+		c.addr.SetResolver(c.resolver)
+		if c.lookupSRV != nil {
+			c.addr.SetLookupSRV(c.lookupSRV)
+		}
+		// like the Notchian client, a failed lookup falls back to the host, the error is kept in the address
+		_ = c.addr.ResolveSRVContext(ctx)
 	}
 
-	conn, err := net.DialTimeout("tcp", c.addr.String(), c.timeout)
+	conn, err := c.dial(ctx)
 	if err != nil {
+		// the failed lookup may be the reason the wrong host was dialed
+		if srvErr := c.addr.SRVError(); srvErr != nil {
+			err = errors.Join(err, srvErr)
+		}
 		return fmt.Errorf("failed to connect: %w", err)
 	}
-	c.conn = conn
+	c.conn = packet.NewConn(conn, c.timeout)
+	c.conn.SetIdleTimeout(c.idleTimeout)
+	c.conn.SetTrace(c.trace)
 	c.state = Connected
 
 	return nil
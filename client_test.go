@@ -0,0 +1,400 @@
+package mclib
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sch8ill/mclib/packet"
+)
+
+// fakeServer serves a single connection of a test over net.Pipe.
+// The handler receives the server end of the connection, which is closed afterwards.
+func fakeServer(t testing.TB, handler func(conn *packet.Conn)) net.Conn {
+	t.Helper()
+
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		handler(packet.NewConn(server, time.Second))
+	}()
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// readPackets reads and discards n packets sent by the client.
+func readPackets(conn *packet.Conn, n int) error {
+	for i := 0; i < n; i++ {
+		p, err := conn.ReadPacket()
+		if err != nil {
+			return err
+		}
+		p.Release()
+	}
+
+	return nil
+}
+
+func TestStatusDisconnect(t *testing.T) {
+	for _, id := range []int32{packet.DisconnectID, packet.LegacyDisconnectID} {
+		conn := fakeServer(t, func(conn *packet.Conn) {
+			// handshake and status request
+			if err := readPackets(conn, 2); err != nil {
+				return
+			}
+
+			disconnect := packet.NewOutboundPacket(id)
+			disconnect.WriteString(`{"text":"maintenance"}`)
+			_ = conn.WritePacket(disconnect)
+		})
+
+		client, err := NewClient("localhost", WithConnection(conn))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = client.Status()
+		if err == nil || !strings.Contains(err.Error(), "maintenance") {
+			t.Errorf("id 0x%02x: error = %v, want the disconnect reason", id, err)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	conn := fakeServer(t, func(conn *packet.Conn) {
+		if err := readPackets(conn, 2); err != nil {
+			return
+		}
+
+		res := packet.NewOutboundPacket(packet.StatusID)
+		res.WriteString(`{"version":{"name":"1.20.4","protocol":765},"description":"hello"}`)
+		_ = conn.WritePacket(res)
+	})
+
+	client, err := NewClient("localhost", WithConnection(conn))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := client.Status()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.Version.Protocol != 765 || res.Description.String() != "hello" {
+		t.Errorf("unexpected response: %+v", res)
+	}
+}
+
+func TestWithTrace(t *testing.T) {
+	conn := fakeServer(t, func(conn *packet.Conn) {
+		if err := readPackets(conn, 2); err != nil {
+			return
+		}
+
+		res := packet.NewOutboundPacket(packet.StatusID)
+		res.WriteString(`{"version":{"name":"1.20.4","protocol":765},"description":"hello"}`)
+		_ = conn.WritePacket(res)
+	})
+
+	var trace strings.Builder
+	client, err := NewClient("localhost", WithConnection(conn), WithTrace(&trace))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.Status(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the handshake and the status request are dumped, the streamed status response only with its header
+	got := trace.String()
+	if n := strings.Count(got, "sent pipe:\npacket id: 0x00"); n != 2 {
+		t.Errorf("trace contains %d sent packets, want 2:\n%s", n, got)
+	}
+	if !strings.Contains(got, "received pipe:\npacket id: 0x00, length: 68 (streamed)\n") {
+		t.Errorf("trace does not contain the status response:\n%s", got)
+	}
+}
+
+func TestWithResolver(t *testing.T) {
+	// A and AAAA records are looked up concurrently
+	var dials atomic.Int32
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dials.Add(1)
+			return nil, errors.New("resolver unavailable")
+		},
+	}
+
+	client, err := NewClient("example.com", WithResolver(resolver))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the SRV lookup and resolving the host both use the resolver
+	_, err = client.Status()
+	if err == nil || strings.Count(err.Error(), "resolver unavailable") < 2 {
+		t.Errorf("error = %v, want the errors of the SRV lookup and of resolving the host", err)
+	}
+	if dials.Load() == 0 {
+		t.Error("the resolver was not used")
+	}
+	if !client.Address().SRVAttempted() || client.Address().SRVError() == nil {
+		t.Error("the failed SRV lookup was not recorded")
+	}
+}
+
+func TestConnectSRVError(t *testing.T) {
+	errLookup := errors.New("lookup failed")
+	lookup := func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", nil, errLookup
+	}
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("resolver unavailable")
+		},
+	}
+
+	client, err := NewClient("example.com", WithLookupSRV(lookup), WithResolver(resolver))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the failed lookup is reported with the connection error, since it may be the reason the host is not reachable
+	if _, err := client.Status(); !errors.Is(err, errLookup) {
+		t.Errorf("error = %v, want the error of the SRV lookup", err)
+	}
+	if !errors.Is(client.Address().SRVError(), errLookup) {
+		t.Errorf("SRVError() = %v, want the error of the SRV lookup", client.Address().SRVError())
+	}
+}
+
+// loginStartFixtures are the login start packets sent by LoginError per protocol version,
+// including the packet id, the name "mclib" and the trailing padding byte.
+var loginStartFixtures = []struct {
+	name     string
+	protocol int32
+	body     string
+}{
+	{"1.8.9", 47, "00 05 6d 63 6c 69 62" + "00"},
+	{"1.18.2", 758, "00 05 6d 63 6c 69 62" + "00"},
+	{"1.19", 759, "00 05 6d 63 6c 69 62" + "00" + "00"},
+	{"1.19.2", 760, "00 05 6d 63 6c 69 62" + "00 00" + "00"},
+	{"1.19.3", 761, "00 05 6d 63 6c 69 62" + "01" + strings.Repeat("00", 16) + "00"},
+	{"1.20.1", 763, "00 05 6d 63 6c 69 62" + "01" + strings.Repeat("00", 16) + "00"},
+	{"1.20.2", 764, "00 05 6d 63 6c 69 62" + strings.Repeat("00", 16) + "00"},
+	{"1.20.4", 765, "00 05 6d 63 6c 69 62" + strings.Repeat("00", 16) + "00"},
+}
+
+func TestLoginErrorLoginStart(t *testing.T) {
+	for _, tt := range loginStartFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies := make(chan []byte, 1)
+			conn := fakeServer(t, func(conn *packet.Conn) {
+				// handshake
+				if err := readPackets(conn, 1); err != nil {
+					return
+				}
+
+				login, err := conn.ReadPacket()
+				if err != nil {
+					return
+				}
+				bodies <- bytes.Clone(login.Body())
+				login.Release()
+
+				disconnect := packet.NewOutboundPacket(packet.LoginDisconnectID)
+				disconnect.WriteString(`{"text":"bad packet"}`)
+				_ = conn.WritePacket(disconnect)
+			})
+
+			client, err := NewClient("localhost", WithConnection(conn), WithProtocolVersion(tt.protocol))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, _, err := client.LoginError(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want, _ := hex.DecodeString(strings.ReplaceAll(tt.body, " ", ""))
+			if got := <-bodies; !bytes.Equal(got, want) {
+				t.Errorf("login start = % x, want % x", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkStatusPing(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		conn := fakeServer(b, func(conn *packet.Conn) {
+			if err := readPackets(conn, 2); err != nil {
+				return
+			}
+
+			res := packet.NewOutboundPacket(packet.StatusID)
+			res.WriteString(`{"version":{"name":"1.20.4","protocol":765},"description":"hello"}`)
+			if err := conn.WritePacket(res); err != nil {
+				return
+			}
+
+			ping, err := conn.ReadPacket()
+			if err != nil {
+				return
+			}
+			defer ping.Release()
+
+			pong := packet.NewOutboundPacket(packet.PongID)
+			pong.WriteBytes(ping.ReadRemaining())
+			_ = conn.WritePacket(pong)
+		})
+
+		client, err := NewClient("localhost", WithConnection(conn))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := client.StatusPing(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNewClientURL(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"minecraft://play.example.com:25566", "play.example.com:25566"},
+		{"tcp://pl%61y.example.com", "play.example.com:25565"},
+		{"play.example.com", "play.example.com:25565"},
+	}
+
+	for _, tt := range tests {
+		c, err := NewClient(tt.addr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.addr, err)
+		}
+		if got := c.Address().String(); got != tt.want {
+			t.Errorf("%s: address = %s, want %s", tt.addr, got, tt.want)
+		}
+	}
+
+	if _, err := NewClient("minecraft://play.example.com/lobby"); err == nil {
+		t.Error("accepted an url with a path")
+	}
+}
+
+// closedPort returns the address of a local port nothing is listening on.
+func closedPort(t *testing.T) *net.TCPAddr {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	return addr
+}
+
+func TestDialSRVFallback(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	closed := closedPort(t)
+	open := ln.Addr().(*net.TCPAddr)
+
+	tests := []struct {
+		name    string
+		records []*net.SRV
+		// want is the target the client connects to, empty if no target is reachable
+		want string
+	}{
+		{
+			name:    "first",
+			records: []*net.SRV{{Target: "127.0.0.1.", Port: uint16(open.Port), Priority: 0}},
+			want:    open.String(),
+		},
+		{
+			name: "fallback",
+			records: []*net.SRV{
+				{Target: "127.0.0.1.", Port: uint16(open.Port), Priority: 10},
+				{Target: "127.0.0.1.", Port: uint16(closed.Port), Priority: 0},
+			},
+			want: open.String(),
+		},
+		{
+			name: "unreachable",
+			records: []*net.SRV{
+				{Target: "127.0.0.1.", Port: uint16(closed.Port), Priority: 0},
+				{Target: "127.0.0.2.", Port: uint16(closed.Port), Priority: 10},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup := func(context.Context, string, string, string) (string, []*net.SRV, error) {
+				return "", tt.records, nil
+			}
+
+			client, err := NewClient("mc.example.com", WithSRVFallback(), WithLookupSRV(lookup), WithTimeout(time.Second))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err = client.connect(context.Background())
+			if tt.want == "" {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				// the errors of all targets are reported
+				for _, record := range tt.records {
+					addr := net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port)))
+					if !strings.Contains(err.Error(), addr) {
+						t.Errorf("error = %v, want the error of target %s", err, addr)
+					}
+				}
+				var opErr *net.OpError
+				if !errors.As(err, &opErr) {
+					t.Errorf("error = %v, want a *net.OpError", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer client.Close()
+
+			if got := client.Address().SRVAddr(); got != tt.want {
+				t.Errorf("SRVAddr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
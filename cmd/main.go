@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/sch8ill/mclib"
 	"github.com/sch8ill/mclib/fingerprint"
+	"github.com/sch8ill/mclib/slp"
 )
 
 func main() {
@@ -14,12 +17,16 @@ func main() {
 	srv := flag.Bool("srv", true, "whether a srv lookup should be made")
 	protocol := flag.Int("protocol", 760, "the protocol version number the client should use")
 	doFingerprint := flag.Bool("fingerprint", true, "whether a software fingerprint should be performed on the server")
+	trace := flag.Bool("trace", false, "whether every packet sent and received should be dumped to stderr")
 	flag.Parse()
 
 	opts := []mclib.ClientOption{mclib.WithTimeout(*timeout), mclib.WithProtocolVersion(int32(*protocol))}
 	if !*srv {
 		opts = append(opts, mclib.WithoutSRV())
 	}
+	if *trace {
+		opts = append(opts, mclib.WithTrace(os.Stderr))
+	}
 
 	mcs, err := mclib.NewClient(*addr, opts...)
 	if err != nil {
@@ -33,6 +40,8 @@ func main() {
 
 	fmt.Printf("version: %s\n", res.Version.Name)
 	fmt.Printf("protocol: %d\n", res.Version.Protocol)
+	compatible, reason := res.Compatible(*protocol)
+	fmt.Printf("compatible: %t (%s)\n", compatible, reason)
 	fmt.Printf("description: %s\n", res.Description.String())
 	fmt.Printf("online players: %d\n", res.Players.Online)
 	fmt.Printf("max players: %d\n", res.Players.Max)
@@ -41,11 +50,24 @@ func main() {
 	fmt.Printf("favicon: %t\n", res.Favicon != "")
 
 	if *doFingerprint {
-		software, err := fingerprint.FingerprintWithProtocol(*addr, res.Version.Protocol, opts...)
+		// the status is reused, so the fingerprint only takes a single connection
+		result, err := detect(*addr, res, opts)
 		if err != nil {
 			fmt.Printf("failed to perform fingerprint: %s\n", err)
 		} else {
-			fmt.Printf("software fingerprint: %s\n", software)
+			fmt.Printf("software fingerprint: %s\n", result.Software)
+			if result.OnlineMode != nil {
+				fmt.Printf("online mode: %t\n", *result.OnlineMode)
+			}
 		}
 	}
 }
+
+func detect(addr string, status *slp.Response, opts []mclib.ClientOption) (fingerprint.Result, error) {
+	f, err := fingerprint.NewFingerprinter(addr, opts...)
+	if err != nil {
+		return fingerprint.Result{}, err
+	}
+
+	return f.DetectWithStatus(context.Background(), status)
+}
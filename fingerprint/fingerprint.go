@@ -3,69 +3,310 @@
 package fingerprint
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sch8ill/mclib"
 	"github.com/sch8ill/mclib/packet"
+	"github.com/sch8ill/mclib/slp"
 )
 
 const (
 	Vanilla     string = "vanilla"
 	CraftBukkit        = "craftbukkit"
 	Paper              = "paper"
+	Purpur             = "purpur"
+	Pufferfish         = "pufferfish"
+	Folia              = "folia"
 	Fabric             = "fabric"
 	Forge              = "forge"
 	Velocity           = "velocity"
+	Geyser             = "geyser"
+	Hybrid             = "hybrid"
+	Mohist             = "mohist"
+	Arclight           = "arclight"
+	Magma              = "magma"
+	Sponge             = "sponge"
 	Empty              = "empty"
-	Encryption         = "encryption"
-	Success            = "success"
-	Compression        = "compression"
 	Plugin             = "plugin"
 	Unknown            = "unknown"
 )
 
-var ConnectionThrottled = errors.New("connection throttled by server")
+// Login states formerly reported as the software. The login probe now reports Unknown instead
+// and records whether the server runs in online mode in Result.OnlineMode.
+//
+// Deprecated: Use Result.OnlineMode.
+const (
+	Encryption  = "encryption"
+	Success     = "success"
+	Compression = "compression"
+)
+
+// classPaths are the packages of server software appearing in the class names of decoder exceptions.
+var classPaths = []struct {
+	pattern  *regexp.Regexp
+	software string
+}{
+	{regexp.MustCompile(`\bcom\.mohistmc\.`), Mohist},
+	{regexp.MustCompile(`\bio\.izzel\.arclight\.`), Arclight},
+	{regexp.MustCompile(`\borg\.magmafoundation\.magma\.`), Magma},
+	{regexp.MustCompile(`\borg\.spongepowered\.`), Sponge},
+}
+
+var (
+	ConnectionThrottled = errors.New("connection throttled by server")
+	VersionMismatch     = errors.New("version mismatch")
+)
+
+// Option configures the Fingerprint functions.
+type Option func(*options)
 
-func Fingerprint(addr string, opts ...mclib.ClientOption) (string, error) {
-	statusClient, err := mclib.NewClient(addr, opts...)
+// options are the settings of the Fingerprint functions.
+type options struct {
+	client []mclib.ClientOption
+	retry  throttleRetry
+}
+
+// WithClientOptions applies the client options to every client used for fingerprinting.
+func WithClientOptions(opts ...mclib.ClientOption) Option {
+	return func(o *options) {
+		o.client = append(o.client, opts...)
+	}
+}
+
+// WithThrottleRetry makes the login probe retry up to retries times if the server throttled the connection,
+// waiting between the attempts (see Fingerprinter.SetThrottleRetry).
+func WithThrottleRetry(retries int, wait time.Duration) Option {
+	return func(o *options) {
+		o.retry = throttleRetry{retries: retries, wait: wait}
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+func Fingerprint(addr string, opts ...Option) (string, error) {
+	return FingerprintContext(context.Background(), addr, opts...)
+}
+
+// FingerprintContext fingerprints the server like Fingerprint, aborting once the context is cancelled.
+func FingerprintContext(ctx context.Context, addr string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	f, err := NewFingerprinter(addr, o.client...)
 	if err != nil {
 		return Unknown, err
 	}
+	f.retry = o.retry
+
+	result, err := f.Detect(ctx)
+	return result.Software, err
+}
+
+func FingerprintWithProtocol(addr string, protocol int, opts ...Option) (string, error) {
+	return FingerprintWithProtocolContext(context.Background(), addr, protocol, opts...)
+}
+
+// FingerprintWithProtocolContext fingerprints the server like FingerprintWithProtocol,
+// aborting once the context is cancelled.
+func FingerprintWithProtocolContext(ctx context.Context, addr string, protocol int, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	clientOpts := append(o.client, mclib.WithProtocolVersion(int32(protocol)))
+	newClient := func() (*mclib.Client, error) {
+		client, err := mclib.NewClient(addr, clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("client creation failed: %w", err)
+		}
+		return client, nil
+	}
+
+	result := Result{Software: Unknown, Protocol: protocol}
+	err := probeRetry(ctx, newClient, &result, o.retry)
+	return result.Software, err
+}
+
+// throttleRetry configures how often a login probe is retried if the server throttled the connection.
+// The zero value does not retry.
+type throttleRetry struct {
+	// retries is the maximum number of retries.
+	retries int
+
+	// wait is the wait before each retry. A wait of zero uses DefaultThrottleWait.
+	wait time.Duration
+}
+
+// DefaultThrottleWait is the wait before retrying a throttled connection,
+// slightly above the default connection throttle of Spigot (4000ms).
+const DefaultThrottleWait = 4500 * time.Millisecond
+
+// probeRetry runs the login probe with a new client and retries it with another client after a wait
+// if the server throttled the connection, as configured by the throttle retry.
+// The waits are recorded as evidence. Probing and waiting are aborted if the context is cancelled.
+func probeRetry(ctx context.Context, newClient func() (*mclib.Client, error), r *Result, retry throttleRetry) error {
+	wait := retry.wait
+	if wait <= 0 {
+		wait = DefaultThrottleWait
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	status, err := statusClient.Status()
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		err = probe(ctx, client, r)
+		if !errors.Is(err, ConnectionThrottled) || attempt >= retry.retries {
+			if err != nil || r.OnlineMode == nil || *r.OnlineMode {
+				return err
+			}
+
+			// offline mode servers accepting the login probe may still reveal their software
+			return probeAcknowledge(ctx, newClient, r)
+		}
+
+		r.Evidence = append(r.Evidence, fmt.Sprintf("connection throttled, retrying after %s (%d/%d)", wait, attempt+1, retry.retries))
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for the duration or until the context is cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// probe sends a malformed login start packet using the protocol version of the result and fingerprints the server
+// based on its response. Rules matching the disconnect message are applied and recorded as evidence.
+func probe(ctx context.Context, client *mclib.Client, r *Result) error {
+	defer client.Close()
+
+	res, id, err := client.LoginErrorContext(ctx)
+	if errors.Is(err, io.EOF) {
+		r.Software = Empty
+		return nil
+	}
 	if err != nil {
-		return Unknown, err
+		r.Software = Unknown
+		return err
 	}
 
-	return FingerprintWithProtocol(addr, status.Version.Protocol, opts...)
+	ids := packet.IDs(int32(r.Protocol))
+	if id != ids.LoginDisconnect {
+		r.OnlineMode = onlineMode(id, ids)
+		if r.OnlineMode == nil {
+			r.Software, err = determineServerState(id, ids)
+			return err
+		}
+
+		// the server accepted the login start packet, which only reveals whether it runs in online mode
+		r.Software = Unknown
+		r.Evidence = append(r.Evidence, fmt.Sprintf("login probe received packet id %d, online mode: %t", id, *r.OnlineMode))
+		return nil
+	}
+
+	return r.interpretDisconnect(res)
 }
 
-func FingerprintWithProtocol(addr string, protocol int, opts ...mclib.ClientOption) (string, error) {
-	opts = append(opts, mclib.WithProtocolVersion(int32(protocol)))
-	client, err := mclib.NewClient(addr, opts...)
+// probeAcknowledge logs in with a new client and sends a malformed login acknowledged packet,
+// which fingerprints offline mode servers accepting the malformed login start packet of the first probe.
+// It is skipped for protocol versions without a configuration state. The result is only updated
+// if the software could be determined.
+func probeAcknowledge(ctx context.Context, newClient func() (*mclib.Client, error), r *Result) error {
+	ids := packet.IDs(int32(r.Protocol))
+	if ids.LoginAcknowledged < 0 {
+		return nil
+	}
+
+	client, err := newClient()
 	if err != nil {
-		return Unknown, fmt.Errorf("client creation failed: %w", err)
+		return err
 	}
+	defer client.Close()
 
-	res, id, err := client.LoginError()
+	res, id, err := client.LoginAcknowledgeErrorContext(ctx)
 	if errors.Is(err, io.EOF) {
-		return Empty, nil
+		return nil
 	}
 	if err != nil {
-		return Unknown, err
+		return err
+	}
+
+	if id != ids.LoginDisconnect {
+		r.Evidence = append(r.Evidence, fmt.Sprintf("login acknowledged probe received packet id %d", id))
+		return nil
 	}
 
-	if id != packet.LoginDisconnectID {
-		return determineServerState(id)
+	probed := Result{Protocol: r.Protocol}
+	if err := probed.interpretDisconnect(res); err != nil || probed.Software == Unknown {
+		r.Evidence = append(r.Evidence, "login acknowledged probe did not reveal the software")
+		return nil
+	}
+
+	r.Software = probed.Software
+	r.Evidence = append(r.Evidence, "software revealed by the login acknowledged probe")
+	r.Evidence = append(r.Evidence, probed.Evidence...)
+	return nil
+}
+
+// interpretDisconnect fingerprints the server based on the disconnect message sent in response to a probe.
+func (r *Result) interpretDisconnect(res string) error {
+	software, err := parseDisconnect(res)
+	refined, evidence := applyRules(DisconnectSignal, software, res)
+	if evidence == "" {
+		r.Software = software
+		return err
 	}
 
-	// response is not json
-	if !strings.HasPrefix(res, "{") {
+	// a matching rule identifies the software even if the message could not be interpreted otherwise
+	r.Software = refined
+	r.Evidence = append(r.Evidence, evidence)
+	return nil
+}
+
+// onlineMode determines whether the server runs in online mode from the packet received in response to the login
+// start packet: an encryption request means online mode, a login success or compression means offline mode.
+func onlineMode(id int32, ids packet.IDTable) *bool {
+	var online bool
+	switch id {
+	case ids.LoginEncryption:
+		online = true
+	case ids.LoginSuccess, ids.LoginCompression:
+		online = false
+	default:
+		return nil
+	}
+
+	return &online
+}
+
+// parseDisconnect fingerprints the server based on the disconnect message sent in response to the login probe.
+func parseDisconnect(res string) (string, error) {
+	// response is not a json object or array
+	if !strings.HasPrefix(res, "{") && !strings.HasPrefix(res, "[") {
 		return parseErrorResponse(res)
 	}
 
@@ -76,24 +317,16 @@ func FingerprintWithProtocol(addr string, protocol int, opts ...mclib.ClientOpti
 
 	mismatch, version := msg.VersionMismatch()
 	if mismatch {
-		return Unknown, fmt.Errorf("version mismatch: %s", version)
+		return Unknown, fmt.Errorf("%w: %s", VersionMismatch, version)
 	}
 
 	return msg.Fingerprint()
 }
 
-func determineServerState(id int32) (string, error) {
-	switch id {
-	case packet.LoginEncryptionID:
-		return Encryption, nil
-
-	case packet.LoginSuccessID:
-		return Success, nil
-
-	case packet.LoginCompressionID:
-		return Compression, nil
-
-	case packet.LoginPluginID:
+// determineServerState fingerprints the server based on a packet received in response to the login probe
+// that neither disconnects nor reveals whether the server runs in online mode.
+func determineServerState(id int32, ids packet.IDTable) (string, error) {
+	if id == ids.LoginPlugin {
 		return Plugin, nil
 	}
 
@@ -111,7 +344,7 @@ func parseErrorResponse(res string) (string, error) {
 
 	versionMismatch := regexp.MustCompile("^\"Outdated client! Please use \\d\\.\\d+\\.\\d+\"$")
 	if versionMismatch.MatchString(res) {
-		return Unknown, fmt.Errorf("version mismatch: %s", res)
+		return Unknown, fmt.Errorf("%w: %s", VersionMismatch, res)
 	}
 
 	// Forge disconnect message:
@@ -126,10 +359,14 @@ func parseErrorResponse(res string) (string, error) {
 	return Unknown, nil
 }
 
+// DisconnectMsg is the reason of a disconnect sent in response to a login probe.
 type DisconnectMsg struct {
 	Translate string   `json:"translate"`
 	With      []string `json:"with"`
 	Text      string   `json:"text"`
+
+	// Plain is the text of the entire message including all nested components.
+	Plain string `json:"-"`
 }
 
 func NewDisconnectMsg(res string) (*DisconnectMsg, error) {
@@ -141,29 +378,79 @@ func NewDisconnectMsg(res string) (*DisconnectMsg, error) {
 	return msg, nil
 }
 
+// UnmarshalJSON unmarshalls a disconnect message sent as a chat component, an array of components or a string.
+// Arguments of the translation may be strings or components, components are converted to their text.
+// If the outer component has neither a text nor a translation, e.g. because the reason is wrapped
+// in its extra components, the first nested component carrying one is used.
+func (m *DisconnectMsg) UnmarshalJSON(b []byte) error {
+	var desc slp.Description
+	if err := json.Unmarshal(b, &desc); err != nil {
+		return err
+	}
+
+	reason := reasonComponent(&desc.Description)
+	with := make([]string, len(reason.With))
+	for i := range reason.With {
+		with[i] = reason.With[i].Clean()
+	}
+
+	*m = DisconnectMsg{
+		Translate: reason.Translate,
+		With:      with,
+		Text:      reason.Text,
+		Plain:     desc.Clean(),
+	}
+
+	return nil
+}
+
+// reasonComponent returns the first component carrying a text or a translation in depth-first order,
+// or the component itself if there is none.
+func reasonComponent(c *slp.ChatComponent) *slp.ChatComponent {
+	if c.Text != "" || c.Translate != "" {
+		return c
+	}
+
+	for i := range c.Extra {
+		reason := reasonComponent(&c.Extra[i].Description)
+		if reason.Text != "" || reason.Translate != "" {
+			return reason
+		}
+	}
+
+	return c
+}
+
 // Fingerprint tries to determine the underlying software of a Minecraft server by
 // analyzing the returned bad login packet disconnect message.
+// Messages without a translation are matched by their plain text.
 // Heavily inspired by matscan:
 // https://github.com/mat-1/matscan/blob/master/src/processing/minecraft_fingerprinting.rs
 func (m *DisconnectMsg) Fingerprint() (string, error) {
-	if m.Text == "This server is only compatible with Minecraft 1.13 and above." {
+	if m.hasText("This server is only compatible with Minecraft 1.13 and above.") {
 		return Velocity, nil
 	}
 
-	if m.Text == "Connection throttled! Please wait before reconnecting." {
+	if m.hasText("Connection throttled! Please wait before reconnecting.") {
 		return Unknown, ConnectionThrottled
 	}
 
-	if m.Translate == "" {
-		return Unknown, errors.New("empty error topic")
-	}
+	var reason string
+	switch m.Translate {
+	case "disconnect.genericReason", "%s":
+		if len(m.With) < 1 {
+			return Unknown, errors.New("incomplete disconnect message")
+		}
+		reason = m.With[0]
 
-	if m.Translate != "disconnect.genericReason" && m.Translate != "%s" {
-		return Unknown, fmt.Errorf("server responded with unfamiliar error topic: %s", m.Translate)
-	}
+	case "":
+		if m.Plain == "" {
+			return Unknown, errors.New("empty error topic")
+		}
+		reason = m.Plain
 
-	if len(m.With) < 1 {
-		return Unknown, errors.New("incomplete disconnect message")
+	default:
+		return Unknown, fmt.Errorf("server responded with unfamiliar error topic: %s", m.Translate)
 	}
 
 	// example disconnect message (Spigot 1.20.4 / 765)
@@ -175,22 +462,32 @@ func (m *DisconnectMsg) Fingerprint() (string, error) {
 	//		whilst reading packet 0"
 	//		]
 	//	}
+	// hybrids and Sponge name their own classes in some decoder exceptions,
+	// e.g. (io.izzel.arclight.common.mixin.core.network.ServerboundHelloPacketMixin)
+	for _, classPath := range classPaths {
+		if classPath.pattern.MatchString(reason) {
+			return classPath.software, nil
+		}
+	}
+
 	msg := strings.TrimPrefix(
-		m.With[0],
+		reason,
 		"Internal Exception: io.netty.handler.codec.DecoderException: java.io.IOException: Packet ")
 
+	// the packet is the login start packet or, for the login acknowledged probe, the login acknowledged packet
 	msg = regexp.MustCompile(
-		" was larger than I expected, found (?:\\d+|(?:login/)?serverbound/minecraft:hello)"+
-			" bytes extra whilst reading packet (?:\\d+|serverbound/minecraft:hello)$").ReplaceAllString(msg, "")
+		" was larger than I expected, found (?:\\d+|(?:login/)?serverbound/minecraft:[a-z_]+)"+
+			" bytes extra whilst reading packet (?:\\d+|serverbound/minecraft:[a-z_]+)$").ReplaceAllString(msg, "")
 
-	msg = regexp.MustCompile("^(login|\\d+)/(serverbound/minecraft:hello|\\d+) ").ReplaceAllString(msg, "")
+	msg = regexp.MustCompile("^(login|\\d+)/(serverbound/minecraft:[a-z_]+|\\d+) ").ReplaceAllString(msg, "")
 
-	if msg == "(PacketLoginInStart)" {
+	// e.g. (PacketLoginInStart)
+	if strings.HasPrefix(msg, "(PacketLoginIn") {
 		return CraftBukkit, nil
 	}
 
 	// paper or forge without mods
-	if msg == "(ServerboundHelloPacket)" {
+	if msg == "(ServerboundHelloPacket)" || msg == "(ServerboundLoginAcknowledgedPacket)" {
 		return Paper, nil
 	}
 
@@ -207,6 +504,11 @@ func (m *DisconnectMsg) Fingerprint() (string, error) {
 	return Unknown, nil
 }
 
+// hasText checks whether the text of the message or its plain text equals text.
+func (m *DisconnectMsg) hasText(text string) bool {
+	return m.Text == text || m.Plain == text
+}
+
 func (m *DisconnectMsg) VersionMismatch() (bool, string) {
 	switch m.Translate {
 	case "multiplayer.disconnect.incompatible":
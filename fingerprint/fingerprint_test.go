@@ -0,0 +1,425 @@
+package fingerprint
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sch8ill/mclib"
+	"github.com/sch8ill/mclib/packet"
+	"github.com/sch8ill/mclib/slp"
+)
+
+const testProtocol = 765
+
+// fakeServers returns a function creating a client for each handler in turn, connected to the handler over net.Pipe.
+// Each handler receives the server end of the connection, which is closed afterwards.
+func fakeServers(t *testing.T, handlers ...func(conn *packet.Conn)) (func() (*mclib.Client, error), *int) {
+	t.Helper()
+
+	var calls int
+	newClient := func() (*mclib.Client, error) {
+		if calls >= len(handlers) {
+			t.Fatalf("unexpected connection %d", calls+1)
+		}
+
+		client, server := net.Pipe()
+		handler := handlers[calls]
+		go func() {
+			defer server.Close()
+			handler(packet.NewConn(server, time.Second))
+		}()
+		t.Cleanup(func() { client.Close() })
+		calls++
+
+		return mclib.NewClient("localhost", mclib.WithConnection(client), mclib.WithProtocolVersion(testProtocol))
+	}
+
+	return newClient, &calls
+}
+
+// readPackets reads and discards n packets sent by the client.
+func readPackets(conn *packet.Conn, n int) error {
+	for i := 0; i < n; i++ {
+		p, err := conn.ReadPacket()
+		if err != nil {
+			return err
+		}
+		p.Release()
+	}
+
+	return nil
+}
+
+// acceptLogin answers the handshake and login start packet like an offline mode server
+// enabling compression with the threshold.
+func acceptLogin(conn *packet.Conn, threshold int32) error {
+	if err := readPackets(conn, 2); err != nil {
+		return err
+	}
+
+	compression := packet.NewOutboundPacket(packet.LoginCompressionID)
+	compression.WriteVarInt(threshold)
+	if err := conn.WritePacket(compression); err != nil {
+		return err
+	}
+	conn.EnableCompression(int(threshold))
+
+	success := packet.NewOutboundPacket(packet.LoginSuccessID)
+	success.WriteUUID([16]byte{})
+	_ = success.WriteString(strings.Repeat("a", 16))
+	success.WriteVarInt(0)
+	return conn.WritePacket(success)
+}
+
+func TestProbeOnlineMode(t *testing.T) {
+	newClient, calls := fakeServers(t, func(conn *packet.Conn) {
+		if err := readPackets(conn, 2); err != nil {
+			return
+		}
+
+		encryption := packet.NewOutboundPacket(packet.LoginEncryptionID)
+		_ = encryption.WriteString("")
+		encryption.WriteByteArray(make([]byte, 162))
+		encryption.WriteByteArray(make([]byte, 4))
+		encryption.WriteBool(true)
+		_ = conn.WritePacket(encryption)
+	})
+
+	r := Result{Protocol: testProtocol}
+	if err := probeRetry(context.Background(), newClient, &r, throttleRetry{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r.Software != Unknown {
+		t.Errorf("software = %q, want %q", r.Software, Unknown)
+	}
+	if r.OnlineMode == nil || !*r.OnlineMode {
+		t.Errorf("online mode = %v, want true", r.OnlineMode)
+	}
+	if *calls != 1 {
+		t.Errorf("connections = %d, want 1", *calls)
+	}
+}
+
+func TestProbeOfflineModeCompression(t *testing.T) {
+	reason := `{"translate":"disconnect.genericReason","with":["Internal Exception: ` +
+		`io.netty.handler.codec.DecoderException: java.io.IOException: Packet login/3 ` +
+		`(ServerboundLoginAcknowledgedPacket) was larger than I expected, found 1 bytes extra whilst reading packet 3"]}`
+
+	newClient, calls := fakeServers(t,
+		func(conn *packet.Conn) {
+			_ = acceptLogin(conn, 64)
+		},
+		func(conn *packet.Conn) {
+			if err := acceptLogin(conn, 64); err != nil {
+				return
+			}
+
+			// the login acknowledged packet is received compressed
+			if err := readPackets(conn, 1); err != nil {
+				return
+			}
+
+			disconnect := packet.NewOutboundPacket(packet.LoginDisconnectID)
+			_ = disconnect.WriteString(reason)
+			_ = conn.WritePacket(disconnect)
+		},
+	)
+
+	r := Result{Protocol: testProtocol}
+	if err := probeRetry(context.Background(), newClient, &r, throttleRetry{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r.Software != Paper {
+		t.Errorf("software = %q, want %q (evidence: %q)", r.Software, Paper, r.Evidence)
+	}
+	if r.OnlineMode == nil || *r.OnlineMode {
+		t.Errorf("online mode = %v, want false", r.OnlineMode)
+	}
+	if *calls != 2 {
+		t.Errorf("connections = %d, want 2", *calls)
+	}
+}
+
+// TestDisconnectMsgFingerprint fingerprints the disconnect messages in testdata/disconnect.
+// The software is the file name up to the first underscore or dot.
+func TestDisconnectMsgFingerprint(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "disconnect", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures found: %v", err)
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		want, _, _ := strings.Cut(strings.TrimSuffix(name, ".json"), "_")
+
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			msg, err := NewDisconnectMsg(strings.TrimSpace(string(raw)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := msg.Fingerprint()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("software = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestNewDisconnectMsgNested(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		translate string
+		with      []string
+		plain     string
+	}{
+		{
+			name:      "string arguments",
+			raw:       `{"translate":"disconnect.genericReason","with":["reason"]}`,
+			translate: "disconnect.genericReason",
+			with:      []string{"reason"},
+			plain:     "reason",
+		},
+		{
+			name:      "component arguments",
+			raw:       `{"translate":"disconnect.genericReason","with":[{"text":"rea","extra":[{"text":"son","bold":true}]},"7"]}`,
+			translate: "disconnect.genericReason",
+			with:      []string{"reason", "7"},
+			plain:     "reason",
+		},
+		{
+			name:      "array",
+			raw:       `[{"translate":"disconnect.genericReason","with":["reason"]},{"text":"!"}]`,
+			translate: "disconnect.genericReason",
+			with:      []string{"reason"},
+			plain:     "reason!",
+		},
+		{
+			name:      "extra",
+			raw:       `{"text":"","extra":[{"text":""},{"translate":"disconnect.genericReason","with":["reason"]}]}`,
+			translate: "disconnect.genericReason",
+			with:      []string{"reason"},
+			plain:     "reason",
+		},
+		{
+			name:  "string",
+			raw:   `"reason"`,
+			with:  []string{},
+			plain: "reason",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := NewDisconnectMsg(tt.raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if msg.Translate != tt.translate || !slices.Equal(msg.With, tt.with) || msg.Plain != tt.plain {
+				t.Errorf("message = %+v, want translate %q, with %q and plain text %q", *msg, tt.translate, tt.with, tt.plain)
+			}
+		})
+	}
+}
+
+// TestStatusFingerprint refines the software detected from the Paper disconnect message in testdata/disconnect
+// with the status responses in testdata/status, as Paper forks respond to the login probe like Paper.
+// The software is the file name up to the first underscore or dot.
+func TestStatusFingerprint(t *testing.T) {
+	disconnect, err := os.ReadFile(filepath.Join("testdata", "disconnect", "paper.json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join("testdata", "status", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures found: %v", err)
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		want, _, _ := strings.Cut(strings.TrimSuffix(name, ".json"), "_")
+
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			status, err := slp.NewResponse(raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var r Result
+			if err := r.interpretDisconnect(strings.TrimSpace(string(disconnect))); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.Software != Paper {
+				t.Fatalf("software = %q before the status rules, want %q", r.Software, Paper)
+			}
+
+			r.applyStatusRules(status)
+			if r.Software != want {
+				t.Errorf("software = %q, want %q (evidence: %q)", r.Software, want, r.Evidence)
+			}
+			if r.Software != Paper && len(r.Evidence) == 0 {
+				t.Error("no evidence recorded")
+			}
+		})
+	}
+}
+
+func TestProbeThrottleRetry(t *testing.T) {
+	throttled := func(conn *packet.Conn) {
+		if err := readPackets(conn, 2); err != nil {
+			return
+		}
+
+		disconnect := packet.NewOutboundPacket(packet.LoginDisconnectID)
+		_ = disconnect.WriteString(`{"text":"Connection throttled! Please wait before reconnecting."}`)
+		_ = conn.WritePacket(disconnect)
+	}
+	vanilla := func(conn *packet.Conn) {
+		if err := readPackets(conn, 2); err != nil {
+			return
+		}
+
+		disconnect := packet.NewOutboundPacket(packet.LoginDisconnectID)
+		_ = disconnect.WriteString(`{"translate":"disconnect.genericReason","with":["Internal Exception: ` +
+			`io.netty.handler.codec.DecoderException: java.io.IOException: Packet login/0 (afu) ` +
+			`was larger than I expected, found 1 bytes extra whilst reading packet 0"]}`)
+		_ = conn.WritePacket(disconnect)
+	}
+
+	t.Run("retried", func(t *testing.T) {
+		newClient, calls := fakeServers(t, throttled, throttled, vanilla)
+
+		r := Result{Protocol: testProtocol}
+		err := probeRetry(context.Background(), newClient, &r, throttleRetry{retries: 2, wait: time.Millisecond})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if r.Software != Vanilla {
+			t.Errorf("software = %q, want %q", r.Software, Vanilla)
+		}
+		if *calls != 3 || len(r.Evidence) != 2 {
+			t.Errorf("connections = %d, evidence = %q, want 3 connections and 2 waits", *calls, r.Evidence)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		newClient, calls := fakeServers(t, throttled, throttled)
+
+		r := Result{Protocol: testProtocol}
+		err := probeRetry(context.Background(), newClient, &r, throttleRetry{retries: 1, wait: time.Millisecond})
+		if !errors.Is(err, ConnectionThrottled) {
+			t.Errorf("error = %v, want ConnectionThrottled", err)
+		}
+		if *calls != 2 {
+			t.Errorf("connections = %d, want 2", *calls)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		newClient, calls := fakeServers(t, throttled)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		r := Result{Protocol: testProtocol}
+		err := probeRetry(ctx, newClient, &r, throttleRetry{retries: 1, wait: time.Minute})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("error = %v, want context.DeadlineExceeded", err)
+		}
+		if *calls != 1 {
+			t.Errorf("connections = %d, want 1", *calls)
+		}
+	})
+}
+
+// listenServers serves each handler in turn on a local TCP listener and returns its address.
+func listenServers(t *testing.T, handlers ...func(conn *packet.Conn)) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for _, handler := range handlers {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			handler(packet.NewConn(conn, time.Second))
+			conn.Close()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestFingerprintWithThrottleRetry(t *testing.T) {
+	throttled := func(conn *packet.Conn) {
+		if err := readPackets(conn, 2); err != nil {
+			return
+		}
+
+		disconnect := packet.NewOutboundPacket(packet.LoginDisconnectID)
+		_ = disconnect.WriteString(`{"text":"Connection throttled! Please wait before reconnecting."}`)
+		_ = conn.WritePacket(disconnect)
+	}
+	velocity := func(conn *packet.Conn) {
+		if err := readPackets(conn, 2); err != nil {
+			return
+		}
+
+		disconnect := packet.NewOutboundPacket(packet.LoginDisconnectID)
+		_ = disconnect.WriteString(`{"text":"This server is only compatible with Minecraft 1.13 and above."}`)
+		_ = conn.WritePacket(disconnect)
+	}
+	clientOpts := WithClientOptions(mclib.WithoutSRV(), mclib.WithTimeout(time.Second))
+
+	t.Run("retried", func(t *testing.T) {
+		addr := listenServers(t, throttled, velocity)
+
+		software, err := FingerprintWithProtocol(addr, testProtocol, clientOpts, WithThrottleRetry(1, time.Millisecond))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if software != Velocity {
+			t.Errorf("software = %q, want %q", software, Velocity)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		addr := listenServers(t, throttled, velocity)
+
+		_, err := FingerprintWithProtocol(addr, testProtocol, clientOpts)
+		if !errors.Is(err, ConnectionThrottled) {
+			t.Errorf("error = %v, want ConnectionThrottled", err)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package fingerprint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sch8ill/mclib"
+	"github.com/sch8ill/mclib/address"
+	"github.com/sch8ill/mclib/slp"
+)
+
+// Result is the outcome of fingerprinting a server.
+type Result struct {
+	// Software is the detected server software, one of the software constants like Paper or Unknown.
+	Software string
+
+	// Protocol is the protocol version used for the login probe.
+	Protocol int
+
+	// Status is the status response the login probe was based on.
+	Status *slp.Response
+
+	// OnlineMode reports whether the server authenticates players with Mojang. It is determined from the response
+	// to the login probe independent of the software, an encryption request means online mode and a login success
+	// or compression offline mode. It is nil if the server responded otherwise.
+	OnlineMode *bool
+
+	// Evidence contains notes on how the result was obtained, e.g. waits after the server throttled the connection.
+	Evidence []string
+}
+
+// Fingerprinter fingerprints a single server. The address and the result of the SRV lookup are shared
+// between the status query and the login probe, which takes at most one connection each,
+// apart from retries after the server throttled the connection (see Fingerprinter.SetThrottleRetry)
+// and a second login probe for offline mode servers accepting the first one.
+type Fingerprinter struct {
+	addr     *address.Address
+	opts     []mclib.ClientOption
+	retry    throttleRetry
+	resolved bool
+}
+
+// NewFingerprinter creates a new Fingerprinter for the server at the specified address.
+// The options are applied to every client used for fingerprinting.
+func NewFingerprinter(addr string, opts ...mclib.ClientOption) (*Fingerprinter, error) {
+	// the client parses the address like it would for a single connection, including WithAddress
+	client, err := mclib.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("client creation failed: %w", err)
+	}
+
+	return &Fingerprinter{
+		addr: client.Address(),
+		opts: opts,
+	}, nil
+}
+
+// SetThrottleRetry makes the login probe retry up to retries times if the server throttled the connection,
+// waiting between the attempts. A wait of zero uses DefaultThrottleWait. By default, the probe is not retried.
+func (f *Fingerprinter) SetThrottleRetry(retries int, wait time.Duration) {
+	f.retry = throttleRetry{retries: retries, wait: wait}
+}
+
+// Address returns the address of the server, including the results of the SRV lookup once a connection was made.
+func (f *Fingerprinter) Address() *address.Address {
+	return f.addr
+}
+
+// Detect queries the status of the server and fingerprints it with a login probe using the protocol version
+// advertised in the status, which takes two connections. Detect aborts once the context is cancelled,
+// also between the status query and the login probe.
+func (f *Fingerprinter) Detect(ctx context.Context) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{Software: Unknown}, err
+	}
+
+	client, err := f.client()
+	if err != nil {
+		return Result{Software: Unknown}, err
+	}
+
+	status, err := client.StatusContext(ctx)
+	// the status query leaves the connection open for a ping, which is not needed
+	_ = client.Close()
+	if err != nil {
+		return Result{Software: Unknown}, err
+	}
+
+	return f.DetectWithStatus(ctx, status)
+}
+
+// DetectWithStatus fingerprints the server with a login probe based on an already known status response,
+// which takes a single connection. If the status is nil, it is queried like Detect does.
+func (f *Fingerprinter) DetectWithStatus(ctx context.Context, status *slp.Response) (Result, error) {
+	if status == nil {
+		return f.Detect(ctx)
+	}
+
+	result := Result{
+		Software: Unknown,
+		Protocol: probeProtocol(status),
+		Status:   status,
+	}
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	newClient := func() (*mclib.Client, error) {
+		return f.client(mclib.WithProtocolVersion(int32(result.Protocol)))
+	}
+
+	err := probeRetry(ctx, newClient, &result, f.retry)
+	if err == nil {
+		result.applyStatusRules(status)
+	}
+	if errors.Is(err, VersionMismatch) {
+		_, reason := status.Compatible(result.Protocol)
+		err = fmt.Errorf("%w (%s)", err, reason)
+	}
+
+	return result, err
+}
+
+// applyStatusRules refines the software using the rules matching the status response.
+// Mods are matched first, so the brand in the version name can tell apart the hybrid they revealed.
+func (r *Result) applyStatusRules(status *slp.Response) {
+	mods := status.Mods()
+	ids := make([]string, len(mods))
+	for i, mod := range mods {
+		ids[i] = mod.ID
+	}
+
+	r.applyRules(ModSignal, ids...)
+	r.applyRules(VersionNameSignal, status.Version.Name)
+}
+
+// applyRules refines the software using the rules matching the values of the signal and records the match.
+func (r *Result) applyRules(signal Signal, values ...string) {
+	var evidence string
+	r.Software, evidence = applyRules(signal, r.Software, values...)
+	if evidence != "" {
+		r.Evidence = append(r.Evidence, evidence)
+	}
+}
+
+// client creates a client for the next connection to the server.
+// Only the first connection looks up the SRV record, later connections reuse its result.
+func (f *Fingerprinter) client(opts ...mclib.ClientOption) (*mclib.Client, error) {
+	clientOpts := append(slices.Clone(f.opts), mclib.WithAddress(f.addr))
+	if f.resolved {
+		clientOpts = append(clientOpts, mclib.WithoutSRV())
+	}
+	f.resolved = true
+
+	client, err := mclib.NewClient(f.addr.OGAddr(), append(clientOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("client creation failed: %w", err)
+	}
+
+	return client, nil
+}
+
+// probeProtocol returns the protocol version the login probe should use for a status response.
+// Proxies sending a placeholder protocol reject logins using it, the newest advertised version is used instead.
+func probeProtocol(status *slp.Response) int {
+	protocol := int(status.Version.Protocol)
+	if slp.IsPlaceholderProtocol(protocol) {
+		if _, max, ok := status.Version.Range(); ok {
+			if p, ok := slp.VersionProtocol(max); ok {
+				protocol = p
+			}
+		}
+	}
+
+	return protocol
+}
@@ -0,0 +1,189 @@
+package fingerprint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Signal is a piece of information about a server a Rule can be matched against.
+type Signal int
+
+const (
+	// VersionNameSignal is the version name of the status response, which often contains the brand of the server.
+	VersionNameSignal Signal = iota
+
+	// DisconnectSignal is the raw disconnect message received in response to the login probe.
+	DisconnectSignal
+
+	// ModSignal is the id of a mod listed in the Forge data of the status response, rules are matched against every mod.
+	ModSignal
+)
+
+func (s Signal) String() string {
+	switch s {
+	case VersionNameSignal:
+		return "version name"
+	case DisconnectSignal:
+		return "disconnect message"
+	case ModSignal:
+		return "mod"
+	}
+
+	return fmt.Sprintf("signal %d", int(s))
+}
+
+// Rule identifies a server software by matching a pattern against a signal.
+// A matching rule replaces the software detected by the login probe if that software is one of Refines,
+// e.g. to tell a fork apart from the software it is based on. Brand strings take precedence over
+// timing or error based detection, as the latter is often identical for forks.
+type Rule struct {
+	Signal  Signal
+	Pattern *regexp.Regexp
+
+	Software   string
+	Confidence float64
+
+	// Refines lists the detected software the rule may replace. If it is empty, the rule replaces any software.
+	Refines []string
+}
+
+// paperBased is the software a Paper based server may be detected as by the login probe.
+// Older Paper versions respond like CraftBukkit.
+var paperBased = []string{Paper, CraftBukkit, Unknown}
+
+// hybridBased is the software a Forge and Bukkit hybrid server may be detected as.
+var hybridBased = []string{Hybrid, Forge, Paper, CraftBukkit, Unknown}
+
+// spongeBased is the software a Sponge server may be detected as, SpongeVanilla responds like Vanilla.
+var spongeBased = append([]string{Vanilla}, hybridBased...)
+
+// Rules are applied to the signals of a server after the login probe, the matching rule with the highest
+// confidence wins. New rules can be added to detect further software without changing the detection logic.
+var Rules = []Rule{
+	// Paper forks advertise their brand in the version name (e.g., "Purpur 1.20.4"),
+	// their login probe responses are identical to Paper.
+	{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bpurpur\b`),
+		Software:   Purpur,
+		Confidence: 0.9,
+		Refines:    paperBased,
+	},
+	{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bpufferfish\b`),
+		Software:   Pufferfish,
+		Confidence: 0.9,
+		Refines:    paperBased,
+	},
+	{
+		// the response timings of region threaded Folia servers are not reliable, only the brand is used
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bfolia\b`),
+		Software:   Folia,
+		Confidence: 0.9,
+		Refines:    paperBased,
+	},
+	// Geyser front-ends name themselves in the version name, the server behind them handles the login probe,
+	// so the brand overrides whatever the probe detected. Disconnect messages of Geyser's Java listener
+	// and of Floodgate mention their name.
+	{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bgeyser\b`),
+		Software:   Geyser,
+		Confidence: 0.9,
+	},
+	{
+		Signal:     DisconnectSignal,
+		Pattern:    regexp.MustCompile(`(?i)\b(geyser|floodgate)\b`),
+		Software:   Geyser,
+		Confidence: 0.7,
+	},
+	// Forge and Bukkit hybrids answer the login probe like Forge or with Bukkit class names,
+	// but list the Forge loader among the mods of the status response. Other mod ids are not used,
+	// as proxies and plugins may forward or fake a mod list. The specific hybrid is told apart by its brand.
+	{
+		Signal:     ModSignal,
+		Pattern:    regexp.MustCompile(`^(forge|fml|neoforge)$`),
+		Software:   Hybrid,
+		Confidence: 0.6,
+		Refines:    []string{Paper, CraftBukkit},
+	},
+	{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bmohist\b`),
+		Software:   Mohist,
+		Confidence: 0.9,
+		Refines:    hybridBased,
+	},
+	{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\barclight\b`),
+		Software:   Arclight,
+		Confidence: 0.9,
+		Refines:    hybridBased,
+	},
+	{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bmagma\b`),
+		Software:   Magma,
+		Confidence: 0.9,
+		Refines:    hybridBased,
+	},
+	{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bsponge`),
+		Software:   Sponge,
+		Confidence: 0.9,
+		Refines:    spongeBased,
+	},
+	{
+		// SpongeForge and SpongeVanilla list the Sponge implementation and API as mods
+		Signal:     ModSignal,
+		Pattern:    regexp.MustCompile(`^sponge(api|forge|vanilla)?$`),
+		Software:   Sponge,
+		Confidence: 0.8,
+		Refines:    spongeBased,
+	},
+	{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bpaper\b`),
+		Software:   Paper,
+		Confidence: 0.8,
+		Refines:    []string{CraftBukkit, Unknown},
+	},
+}
+
+// applyRules returns the software of the matching rule with the highest confidence for the signal
+// that may refine the detected software, together with evidence describing the match.
+// The rules are matched against every value of the signal. If no rule matches,
+// the detected software is returned without evidence.
+func applyRules(signal Signal, software string, values ...string) (string, string) {
+	var best *Rule
+	var matched string
+	for i := range Rules {
+		rule := &Rules[i]
+		if rule.Signal != signal || len(rule.Refines) > 0 && !slices.Contains(rule.Refines, software) {
+			continue
+		}
+
+		if best != nil && rule.Confidence <= best.Confidence {
+			continue
+		}
+
+		for _, value := range values {
+			if rule.Pattern.MatchString(value) {
+				best, matched = rule, value
+				break
+			}
+		}
+	}
+
+	if best == nil {
+		return software, ""
+	}
+
+	evidence := fmt.Sprintf("%s %q matches %s (confidence %.2f)", signal, matched, best.Software, best.Confidence)
+	return best.Software, evidence
+}
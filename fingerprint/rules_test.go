@@ -0,0 +1,93 @@
+package fingerprint
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestApplyRulesMods(t *testing.T) {
+	tests := []struct {
+		detected string
+		mods     []string
+		want     string
+	}{
+		{Paper, []string{"minecraft", "forge", "examplemod"}, Hybrid},
+		{CraftBukkit, []string{"minecraft", "neoforge"}, Hybrid},
+		// a mod list without the Forge loader, e.g. forwarded by a proxy, does not reveal a hybrid
+		{Paper, []string{"minecraft", "examplemod"}, Paper},
+		{Paper, nil, Paper},
+		{Forge, []string{"minecraft", "forge"}, Forge},
+		{Forge, []string{"minecraft", "forge", "spongeforge"}, Sponge},
+	}
+
+	for _, tt := range tests {
+		got, _ := applyRules(ModSignal, tt.detected, tt.mods...)
+		if got != tt.want {
+			t.Errorf("applyRules(%q, %q) = %q, want %q", tt.detected, tt.mods, got, tt.want)
+		}
+	}
+}
+
+func TestApplyRulesVersionName(t *testing.T) {
+	tests := []struct {
+		detected string
+		name     string
+		want     string
+	}{
+		{Paper, "Purpur 1.20.4", Purpur},
+		{CraftBukkit, "Pufferfish 1.20.1", Pufferfish},
+		{Paper, "Folia 1.20.4", Folia},
+		{Vanilla, "Geyser 2.2.0", Geyser},
+		{Hybrid, "Mohist 1.20.1", Mohist},
+		{Forge, "Arclight 1.20.1", Arclight},
+		{CraftBukkit, "Magma 1.18.2", Magma},
+		{Vanilla, "SpongeVanilla 1.16.5", Sponge},
+		{CraftBukkit, "Paper 1.20.4", Paper},
+		// brands only refine the software they are based on
+		{Fabric, "Purpur 1.20.4", Fabric},
+		{Paper, "1.20.4", Paper},
+	}
+
+	for _, tt := range tests {
+		got, evidence := applyRules(VersionNameSignal, tt.detected, tt.name)
+		if got != tt.want {
+			t.Errorf("applyRules(%q, %q) = %q, want %q", tt.detected, tt.name, got, tt.want)
+		}
+		if got != tt.detected && evidence == "" {
+			t.Errorf("applyRules(%q, %q): no evidence recorded", tt.detected, tt.name)
+		}
+	}
+}
+
+func TestRulesExtensible(t *testing.T) {
+	rules := slices.Clone(Rules)
+	t.Cleanup(func() { Rules = rules })
+
+	// a new Paper fork is detected by adding a rule
+	Rules = append(Rules, Rule{
+		Signal:     VersionNameSignal,
+		Pattern:    regexp.MustCompile(`(?i)\bleaf\b`),
+		Software:   "leaf",
+		Confidence: 0.9,
+		Refines:    paperBased,
+	})
+
+	if got, _ := applyRules(VersionNameSignal, Paper, "Leaf 1.20.4"); got != "leaf" {
+		t.Errorf("software = %q, want %q", got, "leaf")
+	}
+	if got, _ := applyRules(VersionNameSignal, Paper, "Purpur 1.20.4"); got != Purpur {
+		t.Errorf("software = %q, want %q", got, Purpur)
+	}
+}
+
+func TestApplyRulesConfidence(t *testing.T) {
+	// the brand in the version name outweighs the Paper rule matching the same value
+	got, evidence := applyRules(VersionNameSignal, CraftBukkit, "Paper Purpur 1.20.4")
+	if got != Purpur {
+		t.Errorf("software = %q, want %q", got, Purpur)
+	}
+	if evidence == "" {
+		t.Error("no evidence recorded")
+	}
+}
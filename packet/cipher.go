@@ -0,0 +1,83 @@
+package packet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"net"
+)
+
+// cipherConn encrypts and decrypts all data sent over the underlying connection.
+type cipherConn struct {
+	net.Conn
+	encrypter cipher.Stream
+	decrypter cipher.Stream
+}
+
+// NewCipherConn wraps a connection into a connection encrypting both directions
+// with AES-128-CFB8, using the shared secret as both key and IV,
+// as Minecraft does after the encryption request during login.
+// https://wiki.vg/Protocol_Encryption
+func NewCipherConn(conn net.Conn, secret []byte) (net.Conn, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &cipherConn{
+		Conn:      conn,
+		encrypter: newCFB8(block, secret, false),
+		decrypter: newCFB8(block, secret, true),
+	}, nil
+}
+
+// Read reads and decrypts data from the connection.
+func (c *cipherConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.decrypter.XORKeyStream(b[:n], b[:n])
+	return n, err
+}
+
+// Write encrypts and writes data to the connection.
+func (c *cipherConn) Write(b []byte) (int, error) {
+	encrypted := make([]byte, len(b))
+	c.encrypter.XORKeyStream(encrypted, b)
+	return c.Conn.Write(encrypted)
+}
+
+// cfb8 implements the cipher feedback mode with 8-bit segments.
+// The standard library only provides CFB with a segment size of the block size.
+type cfb8 struct {
+	block   cipher.Block
+	shift   []byte
+	out     []byte
+	decrypt bool
+}
+
+// newCFB8 creates a CFB8 stream. The IV has to be as long as the block size of the cipher.
+func newCFB8(block cipher.Block, iv []byte, decrypt bool) cipher.Stream {
+	return &cfb8{
+		block:   block,
+		shift:   append([]byte(nil), iv[:block.BlockSize()]...),
+		out:     make([]byte, block.BlockSize()),
+		decrypt: decrypt,
+	}
+}
+
+// XORKeyStream encrypts or decrypts src into dst one byte at a time.
+func (c *cfb8) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		c.block.Encrypt(c.out, c.shift)
+
+		in := src[i]
+		dst[i] = in ^ c.out[0]
+
+		// the ciphertext byte is fed back into the shift register
+		feedback := dst[i]
+		if c.decrypt {
+			feedback = in
+		}
+		copy(c.shift, c.shift[1:])
+		c.shift[len(c.shift)-1] = feedback
+	}
+}
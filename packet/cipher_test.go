@@ -0,0 +1,131 @@
+package packet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// cfb8Vector is the CFB8-AES128 example of NIST SP 800-38A, appendix F.3.7 and F.3.8.
+var cfb8Vector = struct {
+	key, iv, plaintext, ciphertext string
+}{
+	key:        "2b7e151628aed2a6abf7158809cf4f3c",
+	iv:         "000102030405060708090a0b0c0d0e0f",
+	plaintext:  "6bc1bee22e409f96e93d7e117393172aae2d",
+	ciphertext: "3b79424c9c0dd436bace9e0ed4586a4f32b9",
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatalf("invalid hex: %v", err)
+	}
+	return b
+}
+
+func TestCFB8KnownAnswer(t *testing.T) {
+	key := decodeHex(t, cfb8Vector.key)
+	iv := decodeHex(t, cfb8Vector.iv)
+	plaintext := decodeHex(t, cfb8Vector.plaintext)
+	ciphertext := decodeHex(t, cfb8Vector.ciphertext)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make([]byte, len(plaintext))
+	newCFB8(block, iv, false).XORKeyStream(got, plaintext)
+	if !bytes.Equal(got, ciphertext) {
+		t.Errorf("encrypted % x, want % x", got, ciphertext)
+	}
+
+	newCFB8(block, iv, true).XORKeyStream(got, ciphertext)
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("decrypted % x, want % x", got, plaintext)
+	}
+}
+
+func TestCFB8Streaming(t *testing.T) {
+	key := decodeHex(t, cfb8Vector.key)
+	iv := decodeHex(t, cfb8Vector.iv)
+	plaintext := decodeHex(t, cfb8Vector.plaintext)
+	ciphertext := decodeHex(t, cfb8Vector.ciphertext)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// encrypting in chunks of any size continues the stream
+	for _, chunk := range []int{1, 5, 16, 17} {
+		stream := newCFB8(block, iv, false)
+		got := make([]byte, len(plaintext))
+		for i := 0; i < len(plaintext); i += chunk {
+			end := min(i+chunk, len(plaintext))
+			stream.XORKeyStream(got[i:end], plaintext[i:end])
+		}
+
+		if !bytes.Equal(got, ciphertext) {
+			t.Errorf("chunks of %d: encrypted % x, want % x", chunk, got, ciphertext)
+		}
+	}
+}
+
+func TestCFB8InPlace(t *testing.T) {
+	block, err := aes.NewCipher(decodeHex(t, cfb8Vector.key))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buf := decodeHex(t, cfb8Vector.ciphertext)
+	newCFB8(block, decodeHex(t, cfb8Vector.iv), true).XORKeyStream(buf, buf)
+	if want := decodeHex(t, cfb8Vector.plaintext); !bytes.Equal(buf, want) {
+		t.Errorf("decrypted % x, want % x", buf, want)
+	}
+}
+
+func TestConnEncryption(t *testing.T) {
+	secret := decodeHex(t, cfb8Vector.key)
+	client, server := pipeConns(t)
+
+	go func() {
+		// the first packet is sent before encryption is enabled, like the login start packet
+		_ = server.WritePacket(NewOutboundPacket(0x01))
+		if err := server.EnableEncryption(secret); err != nil {
+			return
+		}
+
+		p := NewOutboundPacket(0x02)
+		_ = p.WriteString("encrypted")
+		_ = server.WritePacket(p)
+	}()
+
+	p, err := client.ReadPacket()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.ID() != 0x01 {
+		t.Errorf("plain packet id = 0x%02x, want 0x01", p.ID())
+	}
+	p.Release()
+
+	if err := client.EnableEncryption(secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err = client.ReadPacket()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	if s, err := p.ReadString(); p.ID() != 0x02 || s != "encrypted" || err != nil {
+		t.Errorf("packet 0x%02x: %q, %v", p.ID(), s, err)
+	}
+}
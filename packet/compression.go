@@ -0,0 +1,107 @@
+package packet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// ErrBadCompression is returned when a packet received after compression was enabled is malformed.
+var ErrBadCompression = errors.New("bad compressed packet")
+
+// readCompressedFrame reads a packet in the format used after compression was enabled
+// and returns it in the uncompressed format, including its length prefix.
+func readCompressedFrame(r io.Reader, maxLength, threshold int) ([]byte, error) {
+	// compressed packet:
+	//		packet length (VarInt)
+	//		data length   (VarInt) (0 if the packet is not compressed)
+	//		packet id and data, compressed with zlib if the data length is not 0
+	//
+	// https://wiki.vg/Protocol#With_compression
+
+	byteReader, ok := r.(io.ByteReader)
+	if !ok {
+		byteReader = singleByteReader{r}
+	}
+
+	length, err := readPacketLength(byteReader, maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, length)
+	if n, err := io.ReadFull(r, frame); err != nil {
+		return nil, &ErrTruncatedPacket{Want: length, Got: n, Err: err}
+	}
+
+	src := bytes.NewReader(frame)
+	dataLength, err := ReadVarInt(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read data length: %w", ErrBadCompression, err)
+	}
+
+	if dataLength == 0 {
+		data := frame[len(frame)-src.Len():]
+		return append(AppendVarInt(nil, int32(len(data))), data...), nil
+	}
+
+	if dataLength < 0 {
+		return nil, &ErrBadLength{Length: int(dataLength)}
+	}
+	if int(dataLength) > maxLength {
+		return nil, &ErrBadLength{Length: int(dataLength), Max: maxLength, Err: ErrPacketTooLarge}
+	}
+	if int(dataLength) < threshold {
+		return nil, fmt.Errorf("%w: data length %d is below the threshold of %d", ErrBadCompression, dataLength, threshold)
+	}
+
+	zr, err := zlib.NewReader(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadCompression, err)
+	}
+	defer zr.Close()
+
+	out := AppendVarInt(make([]byte, 0, MaxVarIntLen+int(dataLength)), dataLength)
+	prefix := len(out)
+	out = out[:prefix+int(dataLength)]
+	if _, err := io.ReadFull(zr, out[prefix:]); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadCompression, err)
+	}
+
+	return out, nil
+}
+
+// AppendCompressedTo appends the packet in the format used after compression was enabled to dst.
+// Packets of at least threshold bytes are compressed with zlib,
+// smaller packets are sent uncompressed with a data length of 0.
+func (p *OutboundPacket) AppendCompressedTo(dst []byte, threshold int) ([]byte, error) {
+	length := p.length()
+	if length > MaxPacketLength {
+		return dst, fmt.Errorf("packet exceeds max packet length of %d by %d bytes", MaxPacketLength, length-MaxPacketLength)
+	}
+
+	if length < threshold {
+		// the data length of 0 occupies a single byte
+		dst = slices.Grow(dst, VarIntSize(int32(length+1))+length+1)
+		dst = AppendVarInt(dst, int32(length+1))
+		dst = append(dst, 0)
+		dst = AppendVarInt(dst, p.id)
+		return append(dst, p.body...), nil
+	}
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	// writing to a bytes.Buffer cannot fail
+	_, _ = zw.Write(AppendVarInt(nil, p.id))
+	_, _ = zw.Write(p.body)
+	_ = zw.Close()
+
+	outer := VarIntSize(int32(length)) + compressed.Len()
+	dst = slices.Grow(dst, VarIntSize(int32(outer))+outer)
+	dst = AppendVarInt(dst, int32(outer))
+	dst = AppendVarInt(dst, int32(length))
+	return append(dst, compressed.Bytes()...), nil
+}
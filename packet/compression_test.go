@@ -0,0 +1,129 @@
+package packet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestAppendCompressedToBelowThreshold(t *testing.T) {
+	p := NewOutboundPacket(0x01)
+	p.WriteBytes([]byte{0xAA, 0xBB})
+
+	got, err := p.AppendCompressedTo(nil, 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// packet length, data length of 0, id, body
+	want := []byte{0x04, 0x00, 0x01, 0xAA, 0xBB}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestConnCompressionRoundTrip(t *testing.T) {
+	bodies := [][]byte{
+		nil,
+		[]byte("short"),
+		bytes.Repeat([]byte("compressed "), 100),
+	}
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	sender := NewConn(client, time.Second)
+	receiver := NewConn(server, time.Second)
+	sender.EnableCompression(64)
+	receiver.EnableCompression(64)
+
+	go func() {
+		for i, body := range bodies {
+			p := NewOutboundPacket(int32(i))
+			p.WriteBytes(body)
+			if err := sender.WritePacket(p); err != nil {
+				return
+			}
+		}
+	}()
+
+	for i, body := range bodies {
+		p, err := receiver.ReadPacket()
+		if err != nil {
+			t.Fatalf("packet %d: unexpected error: %v", i, err)
+		}
+
+		if p.ID() != int32(i) {
+			t.Errorf("packet %d: id = %d", i, p.ID())
+		}
+		if got := p.ReadRemaining(); !bytes.Equal(got, body) {
+			t.Errorf("packet %d: body = %q, want %q", i, got, body)
+		}
+		p.Release()
+	}
+}
+
+func TestConnCompressionStream(t *testing.T) {
+	body := bytes.Repeat([]byte{0x42}, 1000)
+	p := NewOutboundPacket(0x05)
+	p.WriteBytes(body)
+	raw, err := p.AppendCompressedTo(nil, 256)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go client.Write(raw)
+
+	conn := NewConn(server, time.Second)
+	conn.EnableCompression(256)
+	s, err := conn.ReadPacketStream()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+
+	if s.ID() != 0x05 || s.Remaining() != len(body) {
+		t.Errorf("id = %d, remaining = %d, want 5 and %d", s.ID(), s.Remaining(), len(body))
+	}
+}
+
+func TestReadCompressedFrameErrors(t *testing.T) {
+	compress := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		zw.Write(b)
+		zw.Close()
+		return buf.Bytes()
+	}
+	frame := func(dataLength int32, data []byte) []byte {
+		inner := append(AppendVarInt(nil, dataLength), data...)
+		return append(AppendVarInt(nil, int32(len(inner))), inner...)
+	}
+
+	tests := []struct {
+		name string
+		raw  []byte
+		want error
+	}{
+		{"below threshold", frame(10, compress(make([]byte, 10))), ErrBadCompression},
+		{"not zlib", frame(100, []byte{0x01, 0x02, 0x03}), ErrBadCompression},
+		{"shorter than data length", frame(200, compress(make([]byte, 100))), ErrBadCompression},
+		{"too large", frame(int32(MaxPacketLength)+1, compress([]byte{0})), ErrPacketTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readCompressedFrame(bytes.NewReader(tt.raw), MaxPacketLength, 64)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
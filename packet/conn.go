@@ -0,0 +1,243 @@
+package packet
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Conn wraps a network connection to read and write packets.
+// It owns a buffered reader for the lifetime of the connection, so packet lengths are read without a system call
+// per byte and bytes buffered beyond a packet are kept for the following packets.
+// It also holds the state of the session, like the timeouts, the max packet length, the encryption and the compression.
+type Conn struct {
+	net.Conn
+	reader      *bufio.Reader
+	maxLength   int
+	timeout     time.Duration
+	idleTimeout time.Duration
+	deadline    time.Time
+	threshold   int
+	trace       io.Writer
+}
+
+// NewConn wraps a network connection. Every packet has to be received and sent within the timeout,
+// a timeout of zero disables the deadlines.
+// The wrapped connection must not be read from directly afterwards.
+func NewConn(conn net.Conn, timeout time.Duration) *Conn {
+	c := &Conn{
+		Conn:      conn,
+		maxLength: MaxPacketLength,
+		timeout:   timeout,
+		threshold: -1,
+	}
+	c.reader = bufio.NewReader(connReader{c})
+
+	return c
+}
+
+// EnableEncryption encrypts all data sent and received from now on with AES-128-CFB8
+// using the shared secret (see NewCipherConn), as done after the encryption response during login.
+// Data that was already received but is still buffered is decrypted as well.
+func (c *Conn) EnableEncryption(secret []byte) error {
+	conn, err := NewCipherConn(c.Conn, secret)
+	if err != nil {
+		return err
+	}
+
+	// modifying the buffered bytes in place is fine, since they are only read through the reader
+	buffered, _ := c.reader.Peek(c.reader.Buffered())
+	conn.(*cipherConn).decrypter.XORKeyStream(buffered, buffered)
+
+	c.Conn = conn
+	return nil
+}
+
+// EnableCompression switches to the compressed packet format from now on, as done after the set compression packet
+// during login. Packets of at least threshold bytes are compressed, a negative threshold disables compression.
+func (c *Conn) EnableCompression(threshold int) {
+	c.threshold = threshold
+}
+
+// SetTrace writes a dump of every packet received and sent to w (see InboundPacket.Dump),
+// each preceded by a line stating the direction. Packets received by ReadPacketStream are traced
+// without their body, which is not buffered. A nil writer disables tracing.
+func (c *Conn) SetTrace(w io.Writer) {
+	c.trace = w
+}
+
+// SetMaxPacketLength sets the max length of received packets, e.g. MaxStatusPacketLength in the status state.
+// It defaults to MaxPacketLength.
+func (c *Conn) SetMaxPacketLength(length int) {
+	c.maxLength = length
+}
+
+// SetTimeout sets the timeout for receiving and sending a packet. A timeout of zero disables the deadlines.
+// The timeout is an absolute deadline covering the length and the body of the packet,
+// no matter how many reads receiving the packet takes.
+func (c *Conn) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// SetIdleTimeout sets the maximum time a single read from the connection may take while receiving a packet.
+// Unlike the timeout, the idle timeout is refreshed by every successful read, so a packet trickling in slowly
+// is only aborted when no data arrives at all. If both are set, the earlier deadline applies.
+// An idle timeout of zero disables it.
+func (c *Conn) SetIdleTimeout(timeout time.Duration) {
+	c.idleTimeout = timeout
+}
+
+// ReadPacket receives the next packet from the connection.
+// The read deadline is cleared after the packet was received.
+func (c *Conn) ReadPacket() (*InboundPacket, error) {
+	if err := c.setReadDeadline(); err != nil {
+		return nil, err
+	}
+	defer func() { c.deadline = time.Time{} }()
+
+	r, err := c.packetReader()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := NewInboundPacketFromReaderLimit(r, c.maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.clearReadDeadline(); err != nil {
+		p.Release()
+		return nil, err
+	}
+
+	if c.trace != nil {
+		c.traceDump("received", p.Dump())
+	}
+
+	return p, nil
+}
+
+// ReadPacketStream receives the header of the next packet from the connection without reading its body.
+// The stream has to be closed before the next packet is read, which discards the unread remainder of the body
+// and clears the read deadline. The timeout covers the packet until the stream is closed.
+func (c *Conn) ReadPacketStream() (*InboundPacketStream, error) {
+	if err := c.setReadDeadline(); err != nil {
+		return nil, err
+	}
+
+	r, err := c.packetReader()
+	if err != nil {
+		c.deadline = time.Time{}
+		return nil, err
+	}
+
+	s, err := NewInboundPacketStream(r, c.maxLength)
+	if err != nil {
+		c.deadline = time.Time{}
+		return nil, err
+	}
+
+	if c.trace != nil {
+		c.traceDump("received", fmt.Sprintf("packet id: 0x%02x, length: %d (streamed)\n", s.ID(), s.Len()))
+	}
+
+	s.done = func() error {
+		c.deadline = time.Time{}
+		return c.clearReadDeadline()
+	}
+
+	return s, nil
+}
+
+// packetReader returns the reader the next packet is read from in the uncompressed format.
+// If compression is enabled, the whole packet is received and decompressed first.
+func (c *Conn) packetReader() (io.Reader, error) {
+	if c.threshold < 0 {
+		return c.reader, nil
+	}
+
+	frame, err := readCompressedFrame(c.reader, c.maxLength, c.threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(frame), nil
+}
+
+// setReadDeadline sets the deadline for receiving a packet if a timeout is set.
+func (c *Conn) setReadDeadline() error {
+	if c.timeout <= 0 {
+		return nil
+	}
+
+	c.deadline = time.Now().Add(c.timeout)
+	if err := c.Conn.SetReadDeadline(c.deadline); err != nil {
+		return fmt.Errorf("failed to set read deadline: %w", err)
+	}
+
+	return nil
+}
+
+// clearReadDeadline clears the deadline set while receiving a packet.
+func (c *Conn) clearReadDeadline() error {
+	if c.timeout <= 0 && c.idleTimeout <= 0 {
+		return nil
+	}
+
+	if err := c.Conn.SetReadDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("failed to clear read deadline: %w", err)
+	}
+
+	return nil
+}
+
+// WritePacket sends a packet over the connection. It has to be accepted within the timeout.
+func (c *Conn) WritePacket(p *OutboundPacket) error {
+	if c.trace != nil {
+		c.traceDump("sent", p.Dump())
+	}
+
+	if c.threshold < 0 {
+		return p.WriteWithDeadline(c.Conn, c.timeout)
+	}
+
+	buf, err := p.AppendCompressedTo(nil, c.threshold)
+	if err != nil {
+		return err
+	}
+
+	return writeWithDeadline(c.Conn, buf, c.timeout)
+}
+
+// traceDump writes a packet dump to the trace writer. Errors of the writer are ignored.
+func (c *Conn) traceDump(direction, dump string) {
+	_, _ = fmt.Fprintf(c.trace, "%s %s:\n%s", direction, c.Conn.RemoteAddr(), dump)
+}
+
+// Read reads raw data from the connection, starting with the bytes buffered by ReadPacket.
+func (c *Conn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// connReader reads from the wrapped connection of a Conn, refreshing the idle deadline before every read.
+type connReader struct {
+	c *Conn
+}
+
+func (r connReader) Read(b []byte) (int, error) {
+	if r.c.idleTimeout > 0 {
+		deadline := time.Now().Add(r.c.idleTimeout)
+		if !r.c.deadline.IsZero() && r.c.deadline.Before(deadline) {
+			deadline = r.c.deadline
+		}
+
+		if err := r.c.Conn.SetReadDeadline(deadline); err != nil {
+			return 0, fmt.Errorf("failed to set read deadline: %w", err)
+		}
+	}
+
+	return r.c.Conn.Read(b)
+}
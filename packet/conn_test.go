@@ -0,0 +1,184 @@
+package packet
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pipeConns returns both ends of a net.Pipe wrapped in a Conn with a timeout of a second.
+func pipeConns(t *testing.T) (*Conn, *Conn) {
+	t.Helper()
+
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	return NewConn(client, time.Second), NewConn(server, time.Second)
+}
+
+func TestConnTrace(t *testing.T) {
+	var trace strings.Builder
+	client, server := pipeConns(t)
+	client.SetTrace(&trace)
+
+	go func() {
+		if p, err := server.ReadPacket(); err == nil {
+			p.Release()
+		}
+
+		p := NewOutboundPacket(0x01)
+		p.WriteLong(42)
+		_ = server.WritePacket(p)
+	}()
+
+	if err := client.WritePacket(NewOutboundPacket(0x00)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := client.ReadPacket()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Release()
+
+	want := "sent pipe:\npacket id: 0x00, length: 1\n00000000  00" + strings.Repeat(" ", 48) + "|.|\n" +
+		"received pipe:\npacket id: 0x01, length: 9\n" +
+		"00000000  01 00 00 00 00 00 00 00  2a" + strings.Repeat(" ", 23) + "|........*|\n"
+	if got := trace.String(); got != want {
+		t.Errorf("trace =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// trickle writes b to conn one byte at a time, waiting for delay before every byte, like a throttled server.
+func trickle(conn net.Conn, b []byte, delay time.Duration) {
+	for i := range b {
+		time.Sleep(delay)
+		if _, err := conn.Write(b[i : i+1]); err != nil {
+			return
+		}
+	}
+}
+
+func TestConnTimeoutIsAbsolute(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	// every byte arrives within the timeout, but the whole packet does not
+	go trickle(server, encodePacket(0x00, make([]byte, 10)), 30*time.Millisecond)
+
+	start := time.Now()
+	_, err := NewConn(client, 150*time.Millisecond).ReadPacket()
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("error = %v, want os.ErrDeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("ReadPacket() returned after %v, want about 150ms", elapsed)
+	}
+}
+
+func TestConnIdleTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	conn := NewConn(client, 0)
+	conn.SetIdleTimeout(150 * time.Millisecond)
+
+	// a packet trickling in slower than the idle timeout in total is received
+	raw := encodePacket(0x00, make([]byte, 10))
+	go trickle(server, raw, 30*time.Millisecond)
+
+	p, err := conn.ReadPacket()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Release()
+
+	// a packet stalling for longer than the idle timeout is not
+	go trickle(server, raw[:5], 30*time.Millisecond)
+
+	if _, err := conn.ReadPacket(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("error = %v, want os.ErrDeadlineExceeded", err)
+	}
+}
+
+func TestConnIdleTimeoutCappedByTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	conn := NewConn(client, 150*time.Millisecond)
+	conn.SetIdleTimeout(time.Second)
+
+	go trickle(server, encodePacket(0x00, make([]byte, 10)), 30*time.Millisecond)
+
+	if _, err := conn.ReadPacket(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("error = %v, want os.ErrDeadlineExceeded", err)
+	}
+}
+
+func TestConnClearsReadDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	go func() {
+		_, _ = server.Write(encodePacket(0x00, nil))
+		time.Sleep(150 * time.Millisecond)
+		_, _ = server.Write([]byte{0x2a})
+	}()
+
+	conn := NewConn(client, 50*time.Millisecond)
+	p, err := conn.ReadPacket()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Release()
+
+	// a stale deadline would abort this read, which waits longer than the timeout
+	b := make([]byte, 1)
+	if _, err := conn.Read(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b[0] != 0x2a {
+		t.Errorf("read %#x, want 0x2a", b[0])
+	}
+}
+
+func TestNewInboundPacketClearsReadDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	go func() {
+		_, _ = server.Write(encodePacket(0x00, nil))
+		time.Sleep(150 * time.Millisecond)
+		_, _ = server.Write([]byte{0x2a})
+	}()
+
+	p, err := NewInboundPacket(client, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Release()
+
+	if _, err := client.Read(make([]byte, 1)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
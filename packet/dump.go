@@ -0,0 +1,28 @@
+package packet
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Dump returns an annotated hex dump of the packet for debugging.
+// The first line contains the packet id and the length of the packet without its length prefix,
+// followed by a dump of the packet id and body in the format of DumpBytes.
+func (p *InboundPacket) Dump() string {
+	return dump(p.id, p.body)
+}
+
+// Dump returns an annotated hex dump of the packet for debugging in the same format as InboundPacket.Dump.
+func (p *OutboundPacket) Dump() string {
+	return dump(p.id, append(AppendVarInt(nil, p.id), p.body...))
+}
+
+// DumpBytes returns a hex dump of b with 16 bytes per line, each line containing the offset,
+// the bytes in hex and their printable ASCII characters, like the output of `hexdump -C`.
+func DumpBytes(b []byte) string {
+	return hex.Dump(b)
+}
+
+func dump(id int32, payload []byte) string {
+	return fmt.Sprintf("packet id: 0x%02x, length: %d\n%s", id, len(payload), DumpBytes(payload))
+}
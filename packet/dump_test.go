@@ -0,0 +1,47 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	out := NewOutboundPacket(0x00)
+	_ = out.WriteString("hello, world!")
+
+	want := "packet id: 0x00, length: 15\n" +
+		"00000000  00 0d 68 65 6c 6c 6f 2c  20 77 6f 72 6c 64 21     |..hello, world!|\n"
+	if got := out.Dump(); got != want {
+		t.Errorf("OutboundPacket.Dump() =\n%s\nwant\n%s", got, want)
+	}
+
+	raw, err := out.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	in, err := NewInboundPacketFromReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer in.Release()
+
+	// reading from the packet does not change its dump
+	_, _ = in.ReadString()
+	if got := in.Dump(); got != want {
+		t.Errorf("InboundPacket.Dump() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDumpBytes(t *testing.T) {
+	b := []byte("0123456789abcdef\x00\x7f\xff")
+
+	want := "00000000  30 31 32 33 34 35 36 37  38 39 61 62 63 64 65 66  |0123456789abcdef|\n" +
+		"00000010  00 7f ff                                          |...|\n"
+	if got := DumpBytes(b); got != want {
+		t.Errorf("DumpBytes() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := DumpBytes(nil); got != "" {
+		t.Errorf("DumpBytes(nil) = %q, want an empty string", got)
+	}
+}
@@ -0,0 +1,89 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTruncatedPacket is returned when the connection ends before the whole packet body was received,
+// e.g. because the server closed the connection mid-packet.
+type ErrTruncatedPacket struct {
+	Want int
+	Got  int
+	Err  error
+}
+
+func (e *ErrTruncatedPacket) Error() string {
+	return fmt.Sprintf("packet is truncated: received %d of %d bytes: %v", e.Got, e.Want, e.Err)
+}
+
+func (e *ErrTruncatedPacket) Unwrap() error {
+	return e.Err
+}
+
+// ErrBadLength is returned when the length prefix of a packet is corrupt, negative or exceeds the max packet length.
+// Err is ErrVarIntTooLong for a corrupt prefix, whose Length is unknown,
+// and ErrPacketTooLarge for a packet exceeding the limit, which is then reported in Max.
+type ErrBadLength struct {
+	Length int
+	Max    int
+	Err    error
+}
+
+func (e *ErrBadLength) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("bad packet length: %d", e.Length)
+	}
+	if errors.Is(e.Err, ErrVarIntTooLong) {
+		return fmt.Sprintf("bad packet length: %v", e.Err)
+	}
+	if errors.Is(e.Err, ErrPacketTooLarge) {
+		return fmt.Sprintf("bad packet length: %d: %v: exceeds the limit of %d bytes", e.Length, e.Err, e.Max)
+	}
+
+	return fmt.Sprintf("bad packet length: %d: %v", e.Length, e.Err)
+}
+
+func (e *ErrBadLength) Unwrap() error {
+	return e.Err
+}
+
+// ErrStringTooLong is returned when a string exceeds its max length.
+// Length and Max are counted in UTF-8 bytes when the length prefix is rejected and in characters otherwise.
+type ErrStringTooLong struct {
+	Length int
+	Max    int
+}
+
+func (e *ErrStringTooLong) Error() string {
+	return fmt.Sprintf("string exceeds the max length of %d: %d", e.Max, e.Length)
+}
+
+// ErrPacketRead is returned when reading a field of a received packet fails and carries the id of the packet.
+type ErrPacketRead struct {
+	ID  int32
+	Err error
+}
+
+func (e *ErrPacketRead) Error() string {
+	return fmt.Sprintf("%v (packet id: 0x%02x)", e.Err, e.ID)
+}
+
+func (e *ErrPacketRead) Unwrap() error {
+	return e.Err
+}
+
+// errorf formats an error like fmt.Errorf and attaches the id of the packet (see wrapErr).
+func (p *InboundPacket) errorf(format string, a ...any) error {
+	return p.wrapErr(fmt.Errorf(format, a...))
+}
+
+// wrapErr attaches the id of the packet to an error, unless it already carries one.
+func (p *InboundPacket) wrapErr(err error) error {
+	var readErr *ErrPacketRead
+	if err == nil || errors.As(err, &readErr) {
+		return err
+	}
+
+	return &ErrPacketRead{ID: p.id, Err: err}
+}
@@ -0,0 +1,162 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestErrTruncatedPacket(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       []byte
+		want, got int
+		err       error
+	}{
+		{"empty body", []byte{0x05}, 5, 0, io.EOF},
+		{"partial body", []byte{0x05, 0x00, 0x01}, 5, 2, io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewInboundPacketFromReader(bytes.NewReader(tt.raw))
+
+			var truncated *ErrTruncatedPacket
+			if !errors.As(err, &truncated) {
+				t.Fatalf("error = %v, want *ErrTruncatedPacket", err)
+			}
+			if truncated.Want != tt.want || truncated.Got != tt.got {
+				t.Errorf("want = %d, got = %d, expected %d and %d", truncated.Want, truncated.Got, tt.want, tt.got)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestErrBadLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    []byte
+		length int
+		err    error
+	}{
+		{"corrupt", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, 0, ErrVarIntTooLong},
+		{"negative", []byte{0xff, 0xff, 0xff, 0xff, 0x0f}, -1, nil},
+		{"too large", AppendVarInt(nil, int32(MaxPacketLength+1)), MaxPacketLength + 1, ErrPacketTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewInboundPacketFromReader(bytes.NewReader(tt.raw))
+
+			var badLength *ErrBadLength
+			if !errors.As(err, &badLength) {
+				t.Fatalf("error = %v, want *ErrBadLength", err)
+			}
+			if badLength.Length != tt.length {
+				t.Errorf("length = %d, want %d", badLength.Length, tt.length)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+
+			// the stream reports the same error
+			_, err = NewInboundPacketStream(bytes.NewReader(tt.raw), MaxPacketLength)
+			if !errors.As(err, &badLength) {
+				t.Errorf("stream error = %v, want *ErrBadLength", err)
+			}
+		})
+	}
+}
+
+func TestErrPacketRead(t *testing.T) {
+	// a packet with id 0x2a and a body of two bytes
+	newPacket := func() *InboundPacket {
+		p, err := NewInboundPacketFromReader(bytes.NewReader([]byte{0x03, 0x2a, 0x05, 0x02}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		t.Cleanup(p.Release)
+
+		return p
+	}
+
+	tests := []struct {
+		name string
+		read func(p *InboundPacket) error
+		err  error
+	}{
+		{"int", func(p *InboundPacket) error { _, err := p.ReadInt(); return err }, io.ErrUnexpectedEOF},
+		{"long", func(p *InboundPacket) error { _, err := p.ReadLong(); return err }, io.ErrUnexpectedEOF},
+		{"uuid", func(p *InboundPacket) error { _, err := p.ReadUUID(); return err }, io.ErrUnexpectedEOF},
+		{"bytes", func(p *InboundPacket) error { _, err := p.ReadBytes(3); return err }, io.ErrUnexpectedEOF},
+		{"discard", func(p *InboundPacket) error { return p.DiscardBytes(3) }, io.ErrUnexpectedEOF},
+		{"string", func(p *InboundPacket) error { _, err := p.ReadString(); return err }, io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.read(newPacket())
+
+			var readErr *ErrPacketRead
+			if !errors.As(err, &readErr) {
+				t.Fatalf("error = %v, want *ErrPacketRead", err)
+			}
+			if readErr.ID != 0x2a {
+				t.Errorf("id = %#x, want 0x2a", readErr.ID)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+			if !strings.Contains(err.Error(), "(packet id: 0x2a)") {
+				t.Errorf("error %q does not contain the packet id", err)
+			}
+
+			// the id is attached only once
+			if strings.Count(err.Error(), "packet id") != 1 {
+				t.Errorf("error %q contains the packet id more than once", err)
+			}
+		})
+	}
+}
+
+func TestErrStringTooLongIsReadError(t *testing.T) {
+	p, err := NewInboundPacketFromReader(bytes.NewReader(encodePacket(0x07, AppendVarInt(nil, int32(MaxStringByteLength+1)))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	err = p.SkipString()
+
+	var tooLong *ErrStringTooLong
+	if !errors.As(err, &tooLong) || tooLong.Length != MaxStringByteLength+1 || tooLong.Max != MaxStringByteLength {
+		t.Errorf("error = %v, want *ErrStringTooLong", err)
+	}
+
+	var readErr *ErrPacketRead
+	if !errors.As(err, &readErr) || readErr.ID != 0x07 {
+		t.Errorf("error = %v, want *ErrPacketRead with id 0x07", err)
+	}
+}
+
+func TestErrTruncatedStream(t *testing.T) {
+	s, err := NewInboundPacketStream(bytes.NewReader([]byte{0x05, 0x00, 0x01}), MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = io.ReadAll(s.Body())
+
+	var truncated *ErrTruncatedPacket
+	if !errors.As(err, &truncated) || truncated.Want != 5 || truncated.Got != 2 {
+		t.Errorf("error = %v, want a packet of 5 bytes truncated after 2", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
@@ -1,16 +1,121 @@
 package packet
 
+// Packet ids shared by most protocol versions. Use IDs to look up the ids of a specific protocol version.
 const (
-	HandshakeID        int32 = 0
-	StatusID           int32 = 0
-	PingID             int32 = 1
-	PongID             int32 = 1
-	DisconnectID       int32 = 27
-	LegacyDisconnectID int32 = 26 // disconnect packet id before 1.20.2
-	LoginStartID       int32 = 0
-	LoginDisconnectID  int32 = 0
-	LoginEncryptionID  int32 = 1
-	LoginSuccessID     int32 = 2
-	LoginCompressionID int32 = 3
-	LoginPluginID      int32 = 4
+	HandshakeID         int32 = 0
+	StatusID            int32 = 0
+	PingID              int32 = 1
+	PongID              int32 = 1
+	DisconnectID        int32 = 27
+	LegacyDisconnectID  int32 = 26 // disconnect packet id before 1.20.2
+	LoginStartID        int32 = 0
+	LoginDisconnectID   int32 = 0
+	LoginEncryptionID   int32 = 1
+	LoginSuccessID      int32 = 2
+	LoginCompressionID  int32 = 3
+	LoginPluginID       int32 = 4
+	LoginAcknowledgedID int32 = 3
 )
+
+// ConfigurationProtocol is the first protocol version (1.20.2) with a configuration state between login and play.
+const ConfigurationProtocol int32 = 764
+
+// IDTable contains the packet ids used by a protocol version.
+// Ids of packets that do not exist in the protocol version are -1.
+type IDTable struct {
+	Handshake int32
+
+	StatusRequest  int32
+	StatusResponse int32
+	Ping           int32
+	Pong           int32
+
+	LoginStart       int32
+	LoginDisconnect  int32
+	LoginEncryption  int32
+	LoginSuccess     int32
+	LoginCompression int32
+	LoginPlugin      int32
+
+	// LoginAcknowledged is the id of the packet acknowledging the login success,
+	// which only exists in versions with a configuration state.
+	LoginAcknowledged int32
+
+	// Disconnect is the id of the disconnect packet in the play state.
+	Disconnect int32
+
+	// Configuration reports whether the protocol version has a configuration state.
+	Configuration bool
+}
+
+// disconnectIDs maps the first protocol version using a play disconnect packet id to the id,
+// ordered from the newest to the oldest version.
+// https://minecraft.wiki/w/Java_Edition_protocol
+var disconnectIDs = []struct {
+	since int32
+	id    int32
+}{
+	{770, 0x1C}, // 1.21.5
+	{766, 0x1D}, // 1.20.5
+	{764, 0x1B}, // 1.20.2
+	{762, 0x1A}, // 1.19.4
+	{761, 0x17}, // 1.19.3
+	{760, 0x19}, // 1.19.1
+	{759, 0x17}, // 1.19
+	{755, 0x1A}, // 1.17
+	{751, 0x19}, // 1.16.2
+	{735, 0x1A}, // 1.16
+	{573, 0x1B}, // 1.15
+	{477, 0x1A}, // 1.14
+	{393, 0x1B}, // 1.13
+	{107, 0x1A}, // 1.9
+	{4, 0x40},   // 1.7.2
+}
+
+// IDs returns the packet ids used by a protocol version.
+// Unknown protocol versions, e.g. -1 or versions older than 1.7, fall back to the ids of the latest version.
+func IDs(protocol int32) IDTable {
+	ids := IDTable{
+		Handshake:         HandshakeID,
+		StatusRequest:     StatusID,
+		StatusResponse:    StatusID,
+		Ping:              PingID,
+		Pong:              PongID,
+		LoginStart:        LoginStartID,
+		LoginDisconnect:   LoginDisconnectID,
+		LoginEncryption:   LoginEncryptionID,
+		LoginSuccess:      LoginSuccessID,
+		LoginCompression:  LoginCompressionID,
+		LoginPlugin:       LoginPluginID,
+		LoginAcknowledged: LoginAcknowledgedID,
+		Disconnect:        disconnectIDs[0].id,
+		Configuration:     true,
+	}
+
+	oldest := disconnectIDs[len(disconnectIDs)-1].since
+	if protocol < oldest {
+		return ids
+	}
+
+	for _, entry := range disconnectIDs {
+		if protocol >= entry.since {
+			ids.Disconnect = entry.id
+			break
+		}
+	}
+
+	// set compression was added in 1.8 and login plugin requests in 1.13
+	if protocol < 47 {
+		ids.LoginCompression = -1
+	}
+	if protocol < 393 {
+		ids.LoginPlugin = -1
+	}
+
+	ids.Configuration = protocol >= ConfigurationProtocol
+	if !ids.Configuration {
+		ids.LoginAcknowledged = -1
+	}
+
+	return ids
+}
@@ -0,0 +1,76 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultNamespace is the namespace of identifiers without an explicit namespace.
+const DefaultNamespace = "minecraft"
+
+// ErrInvalidIdentifier is returned when an identifier does not follow the namespace:path grammar.
+var ErrInvalidIdentifier = errors.New("invalid identifier")
+
+// WriteIdentifier writes an identifier like "minecraft:brand" to the packet.
+// It returns an error wrapping ErrInvalidIdentifier if the identifier is invalid (see ValidateIdentifier).
+func (p *OutboundPacket) WriteIdentifier(id string) error {
+	if err := ValidateIdentifier(id); err != nil {
+		return err
+	}
+
+	return p.WriteString(id)
+}
+
+// ReadIdentifier reads an identifier like "minecraft:brand" from the packet.
+// It returns an error wrapping ErrInvalidIdentifier if the identifier is invalid (see ValidateIdentifier).
+func (p *InboundPacket) ReadIdentifier() (string, error) {
+	id, err := p.ReadString()
+	if err != nil {
+		return "", p.errorf("failed to read identifier: %w", err)
+	}
+
+	if err := ValidateIdentifier(id); err != nil {
+		return "", p.wrapErr(err)
+	}
+
+	return id, nil
+}
+
+// ValidateIdentifier checks whether an identifier follows the namespace:path grammar.
+// The namespace may contain lowercase letters, digits, dots, dashes and underscores
+// and defaults to DefaultNamespace if omitted. The path may additionally contain slashes.
+// https://minecraft.wiki/w/Resource_location
+func ValidateIdentifier(id string) error {
+	if len(id) > MaxStringLength {
+		return fmt.Errorf("%w: exceeds the max length of %d bytes: %d", ErrInvalidIdentifier, MaxStringLength, len(id))
+	}
+
+	namespace, path, found := strings.Cut(id, ":")
+	if !found {
+		namespace, path = DefaultNamespace, id
+	}
+
+	if path == "" {
+		return fmt.Errorf("%w: empty path: %q", ErrInvalidIdentifier, id)
+	}
+
+	for _, r := range namespace {
+		if !isIdentifierRune(r) {
+			return fmt.Errorf("%w: invalid character %q in namespace: %q", ErrInvalidIdentifier, r, id)
+		}
+	}
+
+	for _, r := range path {
+		if !isIdentifierRune(r) && r != '/' {
+			return fmt.Errorf("%w: invalid character %q in path: %q", ErrInvalidIdentifier, r, id)
+		}
+	}
+
+	return nil
+}
+
+// isIdentifierRune checks whether a character is allowed in the namespace of an identifier.
+func isIdentifierRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_'
+}
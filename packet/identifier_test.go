@@ -0,0 +1,125 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestValidateIdentifier(t *testing.T) {
+	tests := []struct {
+		id string
+		ok bool
+	}{
+		{"minecraft:brand", true},
+		{"brand", true},
+		{":brand", true},
+		{"fml:handshake", true},
+		{"bungeecord:main", true},
+		{"my_mod-1.0:textures/block/stone.png", true},
+		{strings.Repeat("a", MaxStringLength), true},
+		{"a:" + strings.Repeat("b", MaxStringLength-2), true},
+		{"a:" + strings.Repeat("b", MaxStringLength-1), false},
+		{"", false},
+		{"minecraft:", false},
+		{"Minecraft:brand", false},
+		{"minecraft:Brand", false},
+		{"minecraft:br and", false},
+		{"minecraft:brand:extra", false},
+		{"mine/craft:brand", false},
+		{"minecraft:bränd", false},
+		{"minecraft:brand\x00", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateIdentifier(tt.id)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("ValidateIdentifier(%.32q) = %v, want ok = %t", tt.id, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("ValidateIdentifier(%.32q) = %v, want ErrInvalidIdentifier", tt.id, err)
+		}
+	}
+}
+
+func TestWriteIdentifier(t *testing.T) {
+	p := NewOutboundPacket(0x00)
+	if err := p.WriteIdentifier("brand"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the identifier is written as given, without adding the implicit namespace
+	want := append(AppendVarInt(nil, 5), "brand"...)
+	if !bytes.Equal(p.body, want) {
+		t.Errorf("body = % x, want % x", p.body, want)
+	}
+
+	if err := p.WriteIdentifier("minecraft:Brand"); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("error = %v, want ErrInvalidIdentifier", err)
+	}
+	if !bytes.Equal(p.body, want) {
+		t.Errorf("an invalid identifier was written: % x", p.body)
+	}
+}
+
+func TestReadIdentifier(t *testing.T) {
+	for _, id := range []string{"minecraft:brand", "brand", "a:" + strings.Repeat("b", MaxStringLength-2)} {
+		got, err := stringPacket(t, len(id), id).ReadIdentifier()
+		if err != nil {
+			t.Fatalf("%.32q: unexpected error: %v", id, err)
+		}
+		if got != id {
+			t.Errorf("ReadIdentifier() = %.32q, want %.32q", got, id)
+		}
+	}
+}
+
+func TestReadIdentifierInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		p    func(t *testing.T) *InboundPacket
+		want func(err error) bool
+	}{
+		{
+			name: "uppercase",
+			p:    func(t *testing.T) *InboundPacket { return stringPacket(t, 15, "MINECRAFT:BRAND") },
+			want: func(err error) bool { return errors.Is(err, ErrInvalidIdentifier) },
+		},
+		{
+			name: "empty path",
+			p:    func(t *testing.T) *InboundPacket { return stringPacket(t, 10, "minecraft:") },
+			want: func(err error) bool { return errors.Is(err, ErrInvalidIdentifier) },
+		},
+		{
+			name: "too long",
+			p: func(t *testing.T) *InboundPacket {
+				return stringPacket(t, MaxStringLength+1, strings.Repeat("a", MaxStringLength+1))
+			},
+			want: func(err error) bool {
+				var tooLong *ErrStringTooLong
+				return errors.As(err, &tooLong)
+			},
+		},
+		{
+			name: "truncated",
+			p:    func(t *testing.T) *InboundPacket { return stringPacket(t, 15, "minecraft") },
+			want: func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.p(t).ReadIdentifier()
+			if !tt.want(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			var readErr *ErrPacketRead
+			if !errors.As(err, &readErr) {
+				t.Errorf("error = %v, want *ErrPacketRead", err)
+			}
+		})
+	}
+}
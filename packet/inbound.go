@@ -5,66 +5,158 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"time"
 )
 
+// ErrPacketTooLarge is returned when a received packet exceeds the max packet length.
+var ErrPacketTooLarge = errors.New("packet is too large")
+
 // InboundPacket represents a packet received from a connection.
 type InboundPacket struct {
 	id     int32
 	body   []byte
+	src    *bytes.Reader
 	reader *bufio.Reader
 }
 
 // NewInboundPacket creates a new InboundPacket from a network connection.
+// The packet, including its length, has to be received within the timeout.
+// The read deadline is cleared after the packet was received, so it does not affect later reads.
 func NewInboundPacket(conn net.Conn, timeout time.Duration) (*InboundPacket, error) {
 	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
 		return nil, fmt.Errorf("failed to set read deadline: %w", err)
 	}
 
-	p := &InboundPacket{}
-	connReader := bufio.NewReader(conn)
-
-	uLength, err := binary.ReadUvarint(connReader)
+	p, err := NewInboundPacketFromReader(conn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read packet length: %w", err)
+		return nil, err
 	}
-	length := int(uLength)
 
-	if length > MaxPacketLength {
-		return nil, fmt.Errorf("received packet is too long: %d", length)
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		p.Release()
+		return nil, fmt.Errorf("failed to clear read deadline: %w", err)
+	}
+
+	return p, nil
+}
+
+// NewInboundPacketFromReader creates a new InboundPacket from a reader without any deadline handling.
+// No bytes following the packet are consumed from the reader.
+func NewInboundPacketFromReader(r io.Reader) (*InboundPacket, error) {
+	return NewInboundPacketFromReaderLimit(r, MaxPacketLength)
+}
+
+// NewInboundPacketFromReaderLimit creates a new InboundPacket of up to maxLength bytes from a reader.
+// Longer packets are rejected with an ErrBadLength wrapping ErrPacketTooLarge before their body is read,
+// which reports the claimed length and the limit.
+func NewInboundPacketFromReaderLimit(r io.Reader, maxLength int) (*InboundPacket, error) {
+	byteReader, ok := r.(io.ByteReader)
+	if !ok {
+		byteReader = singleByteReader{r}
 	}
 
-	p.body, err = readBytes(connReader, length)
+	length, err := readPacketLength(byteReader, maxLength)
 	if err != nil {
-		return nil, fmt.Errorf("failed to receive packet body: %w", err)
+		return nil, err
+	}
+
+	p := &InboundPacket{body: getBuffer(length)}
+	if n, err := io.ReadFull(r, p.body); err != nil {
+		p.Release()
+		return nil, &ErrTruncatedPacket{Want: length, Got: n, Err: err}
 	}
 
-	p.reader = bufio.NewReader(bytes.NewReader(p.body))
+	p.src = bytes.NewReader(p.body)
+	p.reader = getReader(p.src)
 
-	packetID, err := binary.ReadUvarint(p.reader)
+	p.id, err = ReadVarInt(p.reader)
 	if err != nil {
+		p.Release()
 		return nil, fmt.Errorf("failed to read packet id: %w", err)
 	}
-	p.id = int32(packetID)
 
 	return p, nil
 }
 
+// readPacketLength reads the length prefix of a packet of up to maxLength bytes.
+// A corrupt, negative or too large length is rejected with ErrBadLength.
+func readPacketLength(r io.ByteReader, maxLength int) (int, error) {
+	varLength, err := ReadVarInt(r)
+	if errors.Is(err, ErrVarIntTooLong) {
+		return 0, &ErrBadLength{Err: err}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read packet length: %w", err)
+	}
+	length := int(varLength)
+
+	if length < 0 {
+		return 0, &ErrBadLength{Length: length}
+	}
+
+	if length > maxLength {
+		return 0, &ErrBadLength{Length: length, Max: maxLength, Err: ErrPacketTooLarge}
+	}
+
+	return length, nil
+}
+
+// singleByteReader reads single bytes from a reader without buffering any data.
+type singleByteReader struct {
+	io.Reader
+}
+
+func (r singleByteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r.Reader, b[:]); err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
+
 // ID returns the id of the packet.
 func (p *InboundPacket) ID() int32 {
 	return p.id
 }
 
+// Len returns the total size of the packet body in bytes, including the packet id.
+func (p *InboundPacket) Len() int {
+	return len(p.body)
+}
+
+// Remaining returns the number of unread bytes left in the packet body.
+func (p *InboundPacket) Remaining() int {
+	if p.reader == nil {
+		return 0
+	}
+
+	return p.reader.Buffered() + p.src.Len()
+}
+
+// Body returns the raw packet body including the packet id, independent of what has been read already.
+// The returned slice must not be modified and is only valid until the packet is released.
+func (p *InboundPacket) Body() []byte {
+	return p.body
+}
+
+// BodyReader returns a reader for the unread remainder of the packet body.
+func (p *InboundPacket) BodyReader() io.Reader {
+	return p.reader
+}
+
 // ReadInt reads a 32-bit integer from the packet.
 func (p *InboundPacket) ReadInt() (int32, error) {
 	buf := make([]byte, 4)
 
 	_, err := io.ReadFull(p.reader, buf)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read int: %w", err)
+		return 0, p.errorf("failed to read int: %w", err)
 	}
 	n := int32(binary.BigEndian.Uint32(buf))
 
@@ -77,7 +169,7 @@ func (p *InboundPacket) ReadShort() (int16, error) {
 
 	_, err := io.ReadFull(p.reader, buf)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read short: %w", err)
+		return 0, p.errorf("failed to read short: %w", err)
 	}
 	n := int16(binary.BigEndian.Uint16(buf))
 
@@ -90,68 +182,109 @@ func (p *InboundPacket) ReadLong() (int64, error) {
 
 	_, err := io.ReadFull(p.reader, buf)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read long: %w", err)
+		return 0, p.errorf("failed to read long: %w", err)
 	}
 	n := int64(binary.BigEndian.Uint64(buf))
 
 	return n, nil
 }
 
-// ReadVarInt reads a variable-length 32-bit integer from the packet.
-func (p *InboundPacket) ReadVarInt() (int32, error) {
-	n, err := binary.ReadUvarint(p.reader)
+// ReadFloat reads a 32-bit IEEE 754 floating point number from the packet.
+func (p *InboundPacket) ReadFloat() (float32, error) {
+	buf := make([]byte, 4)
+
+	_, err := io.ReadFull(p.reader, buf)
 	if err != nil {
-		return 0, err
+		return 0, p.errorf("failed to read float: %w", err)
 	}
+	f := math.Float32frombits(binary.BigEndian.Uint32(buf))
 
-	return int32(n), nil
+	return f, nil
 }
 
-// ReadVarLong reads a variable-length 64-bit integer from the packet.
-func (p *InboundPacket) ReadVarLong() (int64, error) {
-	n, err := p.ReadVarInt()
+// ReadDouble reads a 64-bit IEEE 754 floating point number from the packet.
+func (p *InboundPacket) ReadDouble() (float64, error) {
+	buf := make([]byte, 8)
+
+	_, err := io.ReadFull(p.reader, buf)
 	if err != nil {
-		return 0, err
+		return 0, p.errorf("failed to read double: %w", err)
 	}
+	f := math.Float64frombits(binary.BigEndian.Uint64(buf))
+
+	return f, nil
+}
 
-	return int64(n), err
+// ReadVarInt reads a variable-length 32-bit integer of up to 5 bytes from the packet.
+func (p *InboundPacket) ReadVarInt() (int32, error) {
+	v, err := ReadVarInt(p.reader)
+	return v, p.wrapErr(err)
+}
+
+// ReadVarLong reads a variable-length 64-bit integer of up to 10 bytes from the packet.
+func (p *InboundPacket) ReadVarLong() (int64, error) {
+	v, err := ReadVarLong(p.reader)
+	return v, p.wrapErr(err)
 }
 
 // ReadBool reads a boolean value from the packet.
 func (p *InboundPacket) ReadBool() (bool, error) {
 	value, err := p.ReadByte()
 	if err != nil {
-		return false, fmt.Errorf("failed to read bool: %w", err)
+		return false, p.errorf("failed to read bool: %w", err)
 	}
 
 	return value != 0, nil
 }
 
-// ReadString reads a string from the packet.
+// ReadString reads a string of up to MaxStringLength characters from the packet.
 func (p *InboundPacket) ReadString() (string, error) {
+	return p.ReadStringN(MaxStringLength)
+}
+
+// ReadStringN reads a string of up to maxUnits characters from the packet.
+// Characters are counted in UTF-16 code units like vanilla does, but the length prefix counts UTF-8 bytes,
+// so strings longer than 3 bytes per character are rejected before they are read.
+// It returns an error wrapping ErrInvalidUTF8 if the string is not valid UTF-8.
+func (p *InboundPacket) ReadStringN(maxUnits int) (string, error) {
 	uLength, err := p.ReadVarInt()
 	if err != nil {
-		return "", fmt.Errorf("failed to read string length: %w", err)
+		return "", p.errorf("failed to read string length: %w", err)
 	}
 	length := int(uLength)
 
-	if length > MaxStringLength {
-		return "", fmt.Errorf("received string exceeds the max string length: %d", length)
+	if length > maxStringBytes(maxUnits) {
+		return "", p.wrapErr(&ErrStringTooLong{Length: length, Max: maxStringBytes(maxUnits)})
 	}
 
 	raw, err := p.ReadBytes(length)
 	if err != nil {
-		return "", fmt.Errorf("failed to read string: %w", err)
+		return "", p.errorf("failed to read string: %w", err)
 	}
 
-	return string(raw), nil
+	str := string(raw)
+	if err := checkString(str, maxUnits); err != nil {
+		return "", p.errorf("received invalid string: %w", err)
+	}
+
+	return str, nil
+}
+
+// ReadUUID reads a UUID sent as 16 raw bytes from the packet.
+func (p *InboundPacket) ReadUUID() ([16]byte, error) {
+	var uuid [16]byte
+	if _, err := io.ReadFull(p.reader, uuid[:]); err != nil {
+		return uuid, p.errorf("failed to read uuid: %w", err)
+	}
+
+	return uuid, nil
 }
 
 // ReadByte reads a single byte from the packet.
 func (p *InboundPacket) ReadByte() (byte, error) {
 	buf, err := p.ReadBytes(1)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read byte: %w", err)
+		return 0, p.errorf("failed to read byte: %w", err)
 	}
 
 	return buf[0], nil
@@ -159,29 +292,90 @@ func (p *InboundPacket) ReadByte() (byte, error) {
 
 // ReadBytes reads a specified number of bytes from the packet.
 func (p *InboundPacket) ReadBytes(length int) ([]byte, error) {
+	// the length is checked first, so a hostile length cannot cause a huge allocation
+	if length > p.Remaining() {
+		truncated := &ErrTruncatedPacket{Want: length, Got: p.Remaining(), Err: io.ErrUnexpectedEOF}
+		return nil, p.errorf("failed to read bytes: %w", truncated)
+	}
+
 	b, err := readBytes(p.reader, length)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read bytes: %w", err)
+		return nil, p.errorf("failed to read bytes: %w", err)
 	}
 
 	return b, nil
 }
 
-// readBytes reads a specified number of bytes from a buffered reader.
-func readBytes(reader *bufio.Reader, length int) ([]byte, error) {
+// ReadByteArray reads a byte slice prefixed by its length as a VarInt from the packet.
+// Lengths exceeding maxLen are rejected before anything is allocated.
+func (p *InboundPacket) ReadByteArray(maxLen int) ([]byte, error) {
+	length, err := p.ReadVarInt()
+	if err != nil {
+		return nil, p.errorf("failed to read byte array length: %w", err)
+	}
+
+	if int(length) > maxLen {
+		return nil, p.errorf("byte array exceeds the max length of %d: %d", maxLen, length)
+	}
+
+	return p.ReadBytes(int(length))
+}
+
+// DiscardBytes skips the next n bytes of the packet without copying them.
+func (p *InboundPacket) DiscardBytes(n int) error {
+	if n < 0 {
+		return p.errorf("discard length cannot be negative: %d", n)
+	}
+
+	if n > p.Remaining() {
+		truncated := &ErrTruncatedPacket{Want: n, Got: p.Remaining(), Err: io.ErrUnexpectedEOF}
+		return p.errorf("failed to discard bytes: %w", truncated)
+	}
+
+	if _, err := p.reader.Discard(n); err != nil {
+		return p.errorf("failed to discard bytes: %w", err)
+	}
+
+	return nil
+}
+
+// SkipVarInt skips a variable-length 32-bit integer.
+func (p *InboundPacket) SkipVarInt() error {
+	_, err := p.ReadVarInt()
+	return err
+}
+
+// SkipString skips a string without decoding or validating it.
+func (p *InboundPacket) SkipString() error {
+	length, err := p.ReadVarInt()
+	if err != nil {
+		return p.errorf("failed to read string length: %w", err)
+	}
+
+	if int(length) > MaxStringByteLength {
+		return p.wrapErr(&ErrStringTooLong{Length: int(length), Max: MaxStringByteLength})
+	}
+
+	return p.DiscardBytes(int(length))
+}
+
+// ReadRemaining reads the unread remainder of the packet body.
+func (p *InboundPacket) ReadRemaining() []byte {
+	// reading from the in-memory body cannot fail
+	remaining, _ := io.ReadAll(p.reader)
+	return remaining
+}
+
+// readBytes reads a specified number of bytes from a reader.
+// If the reader ends early, ErrTruncatedPacket reports how many bytes were obtained.
+func readBytes(reader io.Reader, length int) ([]byte, error) {
 	if length < 0 {
 		return nil, fmt.Errorf("read length cannot be negative: %d", length)
 	}
 
 	data := make([]byte, length)
-	var received int
-	for received < length {
-		segmentLength, err := reader.Read(data[received:])
-		if err != nil {
-			return nil, fmt.Errorf("failed to read packet segment: %w", err)
-		}
-
-		received += segmentLength
+	if n, err := io.ReadFull(reader, data); err != nil {
+		return nil, &ErrTruncatedPacket{Want: length, Got: n, Err: err}
 	}
 
 	return data, nil
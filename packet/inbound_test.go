@@ -0,0 +1,372 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// encodePacket returns the wire form of a packet with the given id and body.
+func encodePacket(id int32, body []byte) []byte {
+	payload := append(AppendVarInt(nil, id), body...)
+	return append(AppendVarInt(nil, int32(len(payload))), payload...)
+}
+
+func TestNewInboundPacketFromReaderLimitAboveMaxPacketLength(t *testing.T) {
+	body := bytes.Repeat([]byte{0xAB}, 3<<20)
+	raw := encodePacket(0x10, body)
+
+	p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(raw), 4<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.ID() != 0x10 {
+		t.Errorf("id = %d, want %d", p.ID(), 0x10)
+	}
+	if got := p.ReadRemaining(); !bytes.Equal(got, body) {
+		t.Errorf("body differs, got %d bytes, want %d", len(got), len(body))
+	}
+
+	// releasing an unpooled buffer must not panic
+	p.Release()
+}
+
+func TestNewInboundPacketFromReaderLimitTooLarge(t *testing.T) {
+	raw := encodePacket(0x00, make([]byte, 100))
+
+	_, err := NewInboundPacketFromReaderLimit(bytes.NewReader(raw), 50)
+	if !errors.Is(err, ErrPacketTooLarge) {
+		t.Fatalf("error = %v, want ErrPacketTooLarge", err)
+	}
+
+	var badLength *ErrBadLength
+	if !errors.As(err, &badLength) {
+		t.Fatalf("error = %v, want *ErrBadLength", err)
+	}
+	if badLength.Length != 101 || badLength.Max != 50 {
+		t.Errorf("length = %d, max = %d, want 101 and 50", badLength.Length, badLength.Max)
+	}
+}
+
+func TestInboundPacketStreamBufferAboveMaxPacketLength(t *testing.T) {
+	body := bytes.Repeat([]byte{0x01}, MaxPacketLength+1)
+	raw := encodePacket(0x01, body)
+
+	s, err := NewInboundPacketStream(bytes.NewReader(raw), 4<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err := s.Buffer()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	if p.Remaining() != len(body) {
+		t.Errorf("remaining = %d, want %d", p.Remaining(), len(body))
+	}
+}
+
+// floatBits are float bit patterns including infinities, signed zeros and NaN payloads.
+var floatBits = []uint32{
+	0x00000000, 0x80000000, 0x3f800000, 0xc0000000, 0x00000001, 0x7f7fffff,
+	0x7f800000, 0xff800000, 0x7fc00000, 0xffc00000, 0x7fa00001,
+}
+
+// doubleBits are double bit patterns including infinities, signed zeros and NaN payloads.
+var doubleBits = []uint64{
+	0x0000000000000000, 0x8000000000000000, 0x3ff0000000000000, 0xc000000000000000, 0x0000000000000001,
+	0x7fefffffffffffff, 0x7ff0000000000000, 0xfff0000000000000, 0x7ff8000000000000, 0xfff8000000000000,
+	0x7ff4000000000001,
+}
+
+func TestFloatRoundTrip(t *testing.T) {
+	out := NewOutboundPacket(0x01)
+	for _, bits := range floatBits {
+		out.WriteFloat(math.Float32frombits(bits))
+	}
+	for _, bits := range doubleBits {
+		out.WriteDouble(math.Float64frombits(bits))
+	}
+
+	raw, err := out.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the values are written big-endian after the length and the id
+	body := raw[len(raw)-4*len(floatBits)-8*len(doubleBits):]
+	if want := []byte{0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x3f, 0x80}; !bytes.HasPrefix(body, want) {
+		t.Errorf("body = % x, want prefix % x", body, want)
+	}
+
+	p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(raw), MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	for _, bits := range floatBits {
+		f, err := p.ReadFloat()
+		if err != nil {
+			t.Fatalf("%#08x: unexpected error: %v", bits, err)
+		}
+		if got := math.Float32bits(f); got != bits {
+			t.Errorf("float bits = %#08x, want %#08x", got, bits)
+		}
+	}
+
+	for _, bits := range doubleBits {
+		f, err := p.ReadDouble()
+		if err != nil {
+			t.Fatalf("%#016x: unexpected error: %v", bits, err)
+		}
+		if got := math.Float64bits(f); got != bits {
+			t.Errorf("double bits = %#016x, want %#016x", got, bits)
+		}
+	}
+}
+
+func TestFloatSpecialValues(t *testing.T) {
+	out := NewOutboundPacket(0x01)
+	out.WriteFloat(float32(math.Inf(1)))
+	out.WriteFloat(float32(math.NaN()))
+	out.WriteDouble(math.Inf(-1))
+	out.WriteDouble(math.NaN())
+
+	raw, err := out.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(raw), MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	if f, _ := p.ReadFloat(); !math.IsInf(float64(f), 1) {
+		t.Errorf("float = %v, want +Inf", f)
+	}
+	if f, _ := p.ReadFloat(); !math.IsNaN(float64(f)) {
+		t.Errorf("float = %v, want NaN", f)
+	}
+	if f, _ := p.ReadDouble(); !math.IsInf(f, -1) {
+		t.Errorf("double = %v, want -Inf", f)
+	}
+	if f, _ := p.ReadDouble(); !math.IsNaN(f) {
+		t.Errorf("double = %v, want NaN", f)
+	}
+}
+
+func TestReadFloatTruncated(t *testing.T) {
+	for _, size := range []int{0, 3} {
+		p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(encodePacket(0x01, make([]byte, size))), MaxPacketLength)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := p.ReadFloat(); err == nil {
+			t.Errorf("ReadFloat() of %d bytes did not fail", size)
+		}
+		p.Release()
+	}
+
+	for _, size := range []int{0, 7} {
+		p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(encodePacket(0x01, make([]byte, size))), MaxPacketLength)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := p.ReadDouble(); err == nil {
+			t.Errorf("ReadDouble() of %d bytes did not fail", size)
+		}
+		p.Release()
+	}
+}
+
+// stutterReader returns (0, nil) before every byte it reads from r, which io.Reader permits.
+type stutterReader struct {
+	r       io.Reader
+	stutter bool
+}
+
+func (s *stutterReader) Read(b []byte) (int, error) {
+	s.stutter = !s.stutter
+	if s.stutter || len(b) == 0 {
+		return 0, nil
+	}
+
+	return s.r.Read(b[:1])
+}
+
+func TestNewInboundPacketFromReaderZeroReads(t *testing.T) {
+	body := []byte{0x01, 0x02, 0x03, 0x04}
+	raw := encodePacket(0x2a, body)
+
+	p, err := NewInboundPacketFromReader(&stutterReader{r: bytes.NewReader(raw)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	if p.ID() != 0x2a {
+		t.Errorf("id = %#x, want 0x2a", p.ID())
+	}
+	if got := p.ReadRemaining(); !bytes.Equal(got, body) {
+		t.Errorf("body = % x, want % x", got, body)
+	}
+}
+
+func TestNewInboundPacketFromReaderZeroReadsTruncated(t *testing.T) {
+	raw := encodePacket(0x2a, []byte{0x01, 0x02, 0x03, 0x04})
+
+	_, err := NewInboundPacketFromReader(&stutterReader{r: bytes.NewReader(raw[:4])})
+
+	var truncated *ErrTruncatedPacket
+	if !errors.As(err, &truncated) || truncated.Want != 5 || truncated.Got != 3 {
+		t.Fatalf("error = %v, want a packet of 5 bytes truncated after 3", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestReadBytesZeroReads(t *testing.T) {
+	b, err := readBytes(&stutterReader{r: bytes.NewReader([]byte("mclib"))}, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != "mclib" {
+		t.Errorf("readBytes() = %q, want %q", b, "mclib")
+	}
+
+	_, err = readBytes(&stutterReader{r: bytes.NewReader([]byte("mc"))}, 5)
+
+	var truncated *ErrTruncatedPacket
+	if !errors.As(err, &truncated) || truncated.Want != 5 || truncated.Got != 2 {
+		t.Errorf("error = %v, want 5 bytes truncated after 2", err)
+	}
+}
+
+func TestInboundPacketStreamZeroReads(t *testing.T) {
+	body := []byte{0x01, 0x02, 0x03, 0x04}
+
+	s, err := NewInboundPacketStream(&stutterReader{r: bytes.NewReader(encodePacket(0x2a, body))}, MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := io.ReadAll(s.Body())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("body = % x, want % x", got, body)
+	}
+}
+
+func TestReadShortsAndStrings(t *testing.T) {
+	tests := []struct {
+		name    string
+		shorts  []int16
+		strings []string
+		// body is the expected body after the packet id
+		body []byte
+	}{
+		{
+			name:    "short before string",
+			shorts:  []int16{25565},
+			strings: []string{"mclib"},
+			body:    []byte{0x63, 0xdd, 0x05, 'm', 'c', 'l', 'i', 'b'},
+		},
+		{
+			name:    "negative shorts",
+			shorts:  []int16{-1, math.MinInt16, math.MaxInt16},
+			strings: []string{""},
+			body:    []byte{0xff, 0xff, 0x80, 0x00, 0x7f, 0xff, 0x00},
+		},
+		{
+			name:    "multi-byte strings",
+			shorts:  []int16{0, 1},
+			strings: []string{"§6", "😱"},
+			body:    []byte{0x00, 0x00, 0x00, 0x01, 0x03, 0xc2, 0xa7, '6', 0x04, 0xf0, 0x9f, 0x98, 0xb1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := NewOutboundPacket(0x01)
+			for _, n := range tt.shorts {
+				out.WriteShort(n)
+			}
+			for _, s := range tt.strings {
+				if err := out.WriteString(s); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			raw, err := out.AppendTo(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := encodePacket(0x01, tt.body); !bytes.Equal(raw, want) {
+				t.Errorf("packet = % x, want % x", raw, want)
+			}
+
+			p, err := NewInboundPacketFromReader(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer p.Release()
+
+			// reading a short must consume exactly two bytes, so the strings following it are intact
+			for _, want := range tt.shorts {
+				got, err := p.ReadShort()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != want {
+					t.Errorf("ReadShort() = %d, want %d", got, want)
+				}
+			}
+			for _, want := range tt.strings {
+				got, err := p.ReadString()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != want {
+					t.Errorf("ReadString() = %q, want %q", got, want)
+				}
+			}
+
+			if p.Remaining() != 0 {
+				t.Errorf("%d bytes left unread", p.Remaining())
+			}
+		})
+	}
+}
+
+func TestNewInboundPacketTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	// the server never sends a packet
+	start := time.Now()
+	if _, err := NewInboundPacket(client, 100*time.Millisecond); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("error = %v, want os.ErrDeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("NewInboundPacket() returned after %v, want about 100ms", elapsed)
+	}
+}
@@ -0,0 +1,162 @@
+package packet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf16"
+)
+
+// Packet ids of the legacy server list ping used before the Netty rewrite (1.7).
+// https://wiki.vg/Server_List_Ping#1.6
+const (
+	LegacyPingID          byte = 0xFE
+	LegacyPluginMessageID byte = 0xFA
+	LegacyKickID          byte = 0xFF
+)
+
+// LegacyPingChannel is the plugin message channel carrying the host of a 1.6 legacy ping.
+const LegacyPingChannel = "MC|PingHost"
+
+// LegacyPing represents a legacy server list ping sent by a pre-Netty client.
+// Clients before 1.6 do not send the plugin message, their Host is empty and their Protocol is 0.
+type LegacyPing struct {
+	Protocol byte
+	Host     string
+	Port     uint16
+}
+
+// EncodeLegacyPing encodes a legacy server list ping as sent by 1.6 clients.
+func EncodeLegacyPing(host string, port uint16, protocol byte) []byte {
+	// legacy ping:
+	//		packet id         (byte)  (0xFE)
+	//		payload           (byte)  (1)
+	//		packet id         (byte)  (0xFA, plugin message)
+	//		channel           (string) (MC|PingHost)
+	//		data length       (short) (7 + 2 * host length)
+	//		protocol version  (byte)
+	//		hostname          (string)
+	//		port              (int)
+	//
+	// strings are prefixed by their length in characters as a short and encoded as UTF-16BE
+	hostUnits := utf16.Encode([]rune(host))
+
+	b := []byte{LegacyPingID, 1, LegacyPluginMessageID}
+	b = appendLegacyString(b, utf16.Encode([]rune(LegacyPingChannel)))
+	b = binary.BigEndian.AppendUint16(b, uint16(7+2*len(hostUnits)))
+	b = append(b, protocol)
+	b = appendLegacyString(b, hostUnits)
+	return binary.BigEndian.AppendUint32(b, uint32(port))
+}
+
+// ParseLegacyPing parses a legacy server list ping sent by a pre-Netty client.
+// Besides the 1.6 format it accepts the 1.4 - 1.5 ping (0xFE 0x01) and the beta ping (0xFE).
+func ParseLegacyPing(b []byte) (*LegacyPing, error) {
+	if len(b) == 0 || b[0] != LegacyPingID {
+		return nil, errors.New("data is not a legacy ping")
+	}
+
+	ping := &LegacyPing{}
+	if len(b) <= 2 {
+		return ping, nil
+	}
+
+	if b[1] != 1 {
+		return nil, fmt.Errorf("legacy ping contains an unexpected payload: %#x", b[1])
+	}
+	if b[2] != LegacyPluginMessageID {
+		return nil, fmt.Errorf("legacy ping contains an unexpected packet: %#x", b[2])
+	}
+
+	channel, rest, err := readLegacyString(b[3:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy ping channel: %w", err)
+	}
+	if channel != LegacyPingChannel {
+		return nil, fmt.Errorf("legacy ping contains unexpected channel: %s", channel)
+	}
+
+	if len(rest) < 3 {
+		return nil, errors.New("legacy ping plugin message is truncated")
+	}
+
+	length := int(binary.BigEndian.Uint16(rest))
+	data := rest[2:]
+	if len(data) != length {
+		return nil, fmt.Errorf("legacy ping plugin message length mismatch: %d instead of %d bytes", len(data), length)
+	}
+
+	ping.Protocol = data[0]
+	ping.Host, data, err = readLegacyString(data[1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy ping host: %w", err)
+	}
+
+	if len(data) != 4 {
+		return nil, errors.New("legacy ping is missing the port")
+	}
+	ping.Port = uint16(binary.BigEndian.Uint32(data))
+
+	return ping, nil
+}
+
+// EncodeLegacyKick encodes a legacy kick packet carrying the reason, e.g. a legacy ping response.
+func EncodeLegacyKick(reason string) ([]byte, error) {
+	units := utf16.Encode([]rune(reason))
+	if len(units) > math.MaxUint16 {
+		return nil, fmt.Errorf("legacy kick reason exceeds the max length of %d characters", math.MaxUint16)
+	}
+
+	return appendLegacyString([]byte{LegacyKickID}, units), nil
+}
+
+// ParseLegacyKick parses a legacy kick packet and returns its reason.
+// The reason of a legacy ping response can be parsed with slp.ParseLegacy.
+func ParseLegacyKick(b []byte) (string, error) {
+	if len(b) == 0 || b[0] != LegacyKickID {
+		return "", errors.New("data is not a legacy kick packet")
+	}
+
+	reason, rest, err := readLegacyString(b[1:])
+	if err != nil {
+		return "", fmt.Errorf("failed to read legacy kick reason: %w", err)
+	}
+
+	if len(rest) > 0 {
+		return "", fmt.Errorf("legacy kick packet contains %d trailing bytes", len(rest))
+	}
+
+	return reason, nil
+}
+
+// appendLegacyString appends a UTF-16BE string prefixed by its length in code units as a short to b.
+func appendLegacyString(b []byte, units []uint16) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(units)))
+	for _, unit := range units {
+		b = binary.BigEndian.AppendUint16(b, unit)
+	}
+
+	return b
+}
+
+// readLegacyString reads a UTF-16BE string prefixed by its length in code units as a short from b
+// and returns the string and the remaining bytes.
+func readLegacyString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("legacy string length is truncated")
+	}
+
+	length := int(binary.BigEndian.Uint16(b))
+	b = b[2:]
+	if len(b) < 2*length {
+		return "", nil, fmt.Errorf("legacy string of %d characters is truncated", length)
+	}
+
+	units := make([]uint16, length)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(b[2*i:])
+	}
+
+	return string(utf16.Decode(units)), b[2*length:], nil
+}
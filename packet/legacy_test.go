@@ -0,0 +1,159 @@
+package packet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// wikiLegacyPing is the 1.6 ping of https://wiki.vg/Server_List_Ping#1.6 for localhost:25565 and protocol 73.
+const wikiLegacyPing = "fe 01 fa 00 0b 00 4d 00 43 00 7c 00 50 00 69 00 6e 00 67 00 48 00 6f 00 73 00 74" +
+	"00 19 49 00 09 00 6c 00 6f 00 63 00 61 00 6c 00 68 00 6f 00 73 00 74 00 00 63 dd"
+
+// wikiLegacyKick is the 1.4 ping response of https://wiki.vg/Server_List_Ping#1.4_to_1.5.
+const wikiLegacyKick = "ff 00 23 00 a7 00 31 00 00 00 34 00 37 00 00 00 31 00 2e 00 34 00 2e 00 32 00 00 00" +
+	"41 00 20 00 4d 00 69 00 6e 00 65 00 63 00 72 00 61 00 66 00 74 00 20 00 53 00 65 00" +
+	"72 00 76 00 65 00 72 00 00 00 30 00 00 00 32 00 30"
+
+const wikiLegacyKickReason = "§1\x0047\x001.4.2\x00A Minecraft Server\x000\x0020"
+
+func TestEncodeLegacyPing(t *testing.T) {
+	want := decodeHex(t, wikiLegacyPing)
+	if got := EncodeLegacyPing("localhost", 25565, 73); !bytes.Equal(got, want) {
+		t.Errorf("EncodeLegacyPing() = % x, want % x", got, want)
+	}
+}
+
+func TestParseLegacyPing(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want LegacyPing
+	}{
+		{"1.6", wikiLegacyPing, LegacyPing{Protocol: 73, Host: "localhost", Port: 25565}},
+		{"1.4", "fe 01", LegacyPing{}},
+		{"beta", "fe", LegacyPing{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ping, err := ParseLegacyPing(decodeHex(t, tt.raw))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *ping != tt.want {
+				t.Errorf("ParseLegacyPing() = %+v, want %+v", *ping, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLegacyPingRoundTrip(t *testing.T) {
+	want := LegacyPing{Protocol: 78, Host: "mc.hypixel.net", Port: 25566}
+
+	ping, err := ParseLegacyPing(EncodeLegacyPing(want.Host, want.Port, want.Protocol))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *ping != want {
+		t.Errorf("ParseLegacyPing() = %+v, want %+v", *ping, want)
+	}
+}
+
+func TestParseLegacyPingInvalid(t *testing.T) {
+	valid := decodeHex(t, wikiLegacyPing)
+
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{"empty", nil},
+		{"not a ping", []byte{0x00, 0x01, 0xfa}},
+		{"payload", append([]byte{0xfe, 0x02}, valid[2:]...)},
+		{"packet", append([]byte{0xfe, 0x01, 0xfb}, valid[3:]...)},
+		{"channel", EncodeLegacyPing("localhost", 25565, 73)[:10]},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing", append(valid[:len(valid):len(valid)], 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseLegacyPing(tt.raw); err == nil {
+				t.Errorf("ParseLegacyPing(% x) did not fail", tt.raw)
+			}
+		})
+	}
+}
+
+func TestEncodeLegacyKick(t *testing.T) {
+	got, err := EncodeLegacyKick(wikiLegacyKickReason)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := decodeHex(t, wikiLegacyKick); !bytes.Equal(got, want) {
+		t.Errorf("EncodeLegacyKick() = % x, want % x", got, want)
+	}
+}
+
+func TestEncodeLegacyKickTooLong(t *testing.T) {
+	if _, err := EncodeLegacyKick(strings.Repeat("a", 1<<16)); err == nil {
+		t.Error("EncodeLegacyKick() did not fail")
+	}
+}
+
+func TestParseLegacyKick(t *testing.T) {
+	reason, err := ParseLegacyKick(decodeHex(t, wikiLegacyKick))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reason != wikiLegacyKickReason {
+		t.Errorf("ParseLegacyKick() = %q, want %q", reason, wikiLegacyKickReason)
+	}
+}
+
+func TestParseLegacyKickRoundTrip(t *testing.T) {
+	// the astral character is encoded as a surrogate pair and counts as two characters
+	for _, reason := range []string{"", "A Minecraft Server", "§6gold §rplain", "emoji 😱"} {
+		b, err := EncodeLegacyKick(reason)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := ParseLegacyKick(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != reason {
+			t.Errorf("ParseLegacyKick() = %q, want %q", got, reason)
+		}
+	}
+
+	b, _ := EncodeLegacyKick("😱")
+	if want := []byte{0xff, 0x00, 0x02, 0xd8, 0x3d, 0xde, 0x31}; !bytes.Equal(b, want) {
+		t.Errorf("EncodeLegacyKick() = % x, want % x", b, want)
+	}
+}
+
+func TestParseLegacyKickInvalid(t *testing.T) {
+	valid := decodeHex(t, wikiLegacyKick)
+
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{"empty", nil},
+		{"not a kick", append([]byte{0xfe}, valid[1:]...)},
+		{"length", []byte{0xff, 0x00}},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing", append(valid[:len(valid):len(valid)], 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseLegacyKick(tt.raw); err == nil {
+				t.Errorf("ParseLegacyKick(% x) did not fail", tt.raw)
+			}
+		})
+	}
+}
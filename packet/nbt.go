@@ -0,0 +1,462 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"unicode/utf16"
+)
+
+// NBT tag types.
+// https://minecraft.wiki/w/NBT_format
+const (
+	TagEnd byte = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+// MaxNBTDepth is the maximum nesting depth of NBT compounds and lists accepted when decoding.
+const MaxNBTDepth = 512
+
+// MaxNBTSize is the maximum number of bytes of a tag accepted when decoding,
+// matching the 2 MiB quota vanilla applies to network NBT.
+const MaxNBTSize = 2 * 1024 * 1024
+
+// maxNBTPrealloc limits the number of list elements allocated before they are read,
+// so a hostile list length cannot cause a huge allocation.
+const maxNBTPrealloc = 1024
+
+// ReadNBT reads a tag in the network NBT format used since 1.20.2, which omits the name of the root tag.
+// Tags are decoded into the following values:
+//
+//	Byte: int8, Short: int16, Int: int32, Long: int64, Float: float32, Double: float64,
+//	String: string, List: []any, Compound: map[string]any,
+//	Byte Array: []int8, Int Array: []int32, Long Array: []int64
+//
+// Tags nested deeper than MaxNBTDepth or larger than MaxNBTSize bytes are rejected.
+// https://wiki.vg/NBT#Network_NBT_(Java_Edition)
+func (p *InboundPacket) ReadNBT() (any, error) {
+	value, err := readNBT(p.reader)
+	return value, p.wrapErr(err)
+}
+
+// DecodeNBT decodes a tag in the network NBT format. See InboundPacket.ReadNBT for the decoded values.
+// It fails if the tag is followed by trailing bytes.
+func DecodeNBT(b []byte) (any, error) {
+	r := bytes.NewReader(b)
+	value, err := readNBT(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Len() > 0 {
+		return nil, fmt.Errorf("nbt contains %d trailing bytes", r.Len())
+	}
+
+	return value, nil
+}
+
+// WriteNBT writes a value as a tag in the network NBT format. See AppendNBT for the supported values.
+func (p *OutboundPacket) WriteNBT(value any) error {
+	body, err := AppendNBT(p.body, value)
+	if err != nil {
+		return err
+	}
+
+	p.body = body
+	return nil
+}
+
+// AppendNBT appends a value encoded as a tag in the network NBT format to buf.
+// Besides the values produced by ReadNBT, booleans are encoded as byte tags.
+// Compound keys are written in sorted order and lists mixing tag types are written as a list of compounds
+// wrapping every element under an empty key, like vanilla does for chat components.
+func AppendNBT(buf []byte, value any) ([]byte, error) {
+	tag, err := nbtTagType(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendNBTPayload(append(buf, tag), value)
+}
+
+// nbtTagType returns the tag type a value is encoded as.
+func nbtTagType(value any) (byte, error) {
+	switch value.(type) {
+	case bool, int8:
+		return TagByte, nil
+	case int16:
+		return TagShort, nil
+	case int32:
+		return TagInt, nil
+	case int64:
+		return TagLong, nil
+	case float32:
+		return TagFloat, nil
+	case float64:
+		return TagDouble, nil
+	case []int8:
+		return TagByteArray, nil
+	case string:
+		return TagString, nil
+	case []any:
+		return TagList, nil
+	case map[string]any:
+		return TagCompound, nil
+	case []int32:
+		return TagIntArray, nil
+	case []int64:
+		return TagLongArray, nil
+	}
+
+	return 0, fmt.Errorf("cannot encode %T as nbt", value)
+}
+
+// appendNBTPayload appends the payload of a value without its tag type to buf.
+func appendNBTPayload(buf []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case int8:
+		return append(buf, byte(v)), nil
+	case int16:
+		return binary.BigEndian.AppendUint16(buf, uint16(v)), nil
+	case int32:
+		return binary.BigEndian.AppendUint32(buf, uint32(v)), nil
+	case int64:
+		return binary.BigEndian.AppendUint64(buf, uint64(v)), nil
+	case float32:
+		return binary.BigEndian.AppendUint32(buf, math.Float32bits(v)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(v)), nil
+	case string:
+		return appendNBTString(buf, v)
+
+	case []int8:
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(v)))
+		for _, n := range v {
+			buf = append(buf, byte(n))
+		}
+		return buf, nil
+	case []int32:
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(v)))
+		for _, n := range v {
+			buf = binary.BigEndian.AppendUint32(buf, uint32(n))
+		}
+		return buf, nil
+	case []int64:
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(v)))
+		for _, n := range v {
+			buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+		}
+		return buf, nil
+
+	case []any:
+		return appendNBTList(buf, v)
+
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			tag, err := nbtTagType(v[key])
+			if err != nil {
+				return nil, err
+			}
+
+			buf = append(buf, tag)
+			if buf, err = appendNBTString(buf, key); err != nil {
+				return nil, err
+			}
+			if buf, err = appendNBTPayload(buf, v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, TagEnd), nil
+	}
+
+	return nil, fmt.Errorf("cannot encode %T as nbt", value)
+}
+
+// appendNBTList appends a list payload to buf.
+func appendNBTList(buf []byte, values []any) ([]byte, error) {
+	elemTag := TagEnd
+	for i, value := range values {
+		tag, err := nbtTagType(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			elemTag = tag
+		} else if tag != elemTag {
+			elemTag = TagCompound
+			break
+		}
+	}
+
+	buf = append(buf, elemTag)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(values)))
+
+	for _, value := range values {
+		if tag, _ := nbtTagType(value); tag != elemTag {
+			value = map[string]any{"": value}
+		}
+
+		var err error
+		if buf, err = appendNBTPayload(buf, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+// appendNBTString appends a string in Java's modified UTF-8 prefixed by its length to buf.
+func appendNBTString(buf []byte, s string) ([]byte, error) {
+	var encoded []byte
+	for _, r := range s {
+		switch {
+		case r != 0 && r < 0x80:
+			encoded = append(encoded, byte(r))
+		case r < 0x800:
+			encoded = append(encoded, 0xC0|byte(r>>6), 0x80|byte(r&0x3F))
+		case r < 0x10000:
+			encoded = append(encoded, 0xE0|byte(r>>12), 0x80|byte(r>>6&0x3F), 0x80|byte(r&0x3F))
+		default:
+			// supplementary characters are encoded as a surrogate pair
+			high, low := utf16.EncodeRune(r)
+			for _, s := range []rune{high, low} {
+				encoded = append(encoded, 0xE0|byte(s>>12), 0x80|byte(s>>6&0x3F), 0x80|byte(s&0x3F))
+			}
+		}
+	}
+
+	if len(encoded) > math.MaxUint16 {
+		return nil, fmt.Errorf("nbt string exceeds the max length of %d bytes", math.MaxUint16)
+	}
+
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(encoded)))
+	return append(buf, encoded...), nil
+}
+
+// nbtReader is the reader NBT is decoded from.
+type nbtReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// nbtLimitReader fails reads exceeding the remaining number of bytes n with an error instead of io.EOF.
+type nbtLimitReader struct {
+	r nbtReader
+	n int
+}
+
+func (l *nbtLimitReader) Read(b []byte) (int, error) {
+	if len(b) > l.n {
+		return 0, fmt.Errorf("nbt exceeds the max size of %d bytes", MaxNBTSize)
+	}
+
+	n, err := l.r.Read(b)
+	l.n -= n
+	return n, err
+}
+
+func (l *nbtLimitReader) ReadByte() (byte, error) {
+	if l.n <= 0 {
+		return 0, fmt.Errorf("nbt exceeds the max size of %d bytes", MaxNBTSize)
+	}
+
+	b, err := l.r.ReadByte()
+	if err == nil {
+		l.n--
+	}
+	return b, err
+}
+
+// readNBT reads a root tag in the network NBT format of at most MaxNBTSize bytes.
+func readNBT(r nbtReader) (any, error) {
+	r = &nbtLimitReader{r: r, n: MaxNBTSize}
+
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nbt root tag type: %w", err)
+	}
+
+	return readNBTPayload(r, tag, 0)
+}
+
+// readNBTPayload reads the payload of a tag of the given type nested at the given depth.
+func readNBTPayload(r nbtReader, tag byte, depth int) (any, error) {
+	if depth > MaxNBTDepth {
+		return nil, fmt.Errorf("nbt exceeds the max depth of %d", MaxNBTDepth)
+	}
+
+	switch tag {
+	case TagByte:
+		return readNBTNumber[int8](r)
+	case TagShort:
+		return readNBTNumber[int16](r)
+	case TagInt:
+		return readNBTNumber[int32](r)
+	case TagLong:
+		return readNBTNumber[int64](r)
+	case TagFloat:
+		return readNBTNumber[float32](r)
+	case TagDouble:
+		return readNBTNumber[float64](r)
+
+	case TagByteArray:
+		return readNBTArray[int8](r)
+	case TagIntArray:
+		return readNBTArray[int32](r)
+	case TagLongArray:
+		return readNBTArray[int64](r)
+
+	case TagString:
+		return readNBTString(r)
+
+	case TagList:
+		elemTag, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		length, err := readNBTLength(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if elemTag == TagEnd {
+			if length != 0 {
+				return nil, errors.New("nbt list of end tags is not empty")
+			}
+			return []any{}, nil
+		}
+
+		values := make([]any, 0, min(length, maxNBTPrealloc))
+		for range length {
+			value, err := readNBTPayload(r, elemTag, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+
+	case TagCompound:
+		compound := make(map[string]any)
+		for {
+			fieldTag, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			if fieldTag == TagEnd {
+				return compound, nil
+			}
+
+			key, err := readNBTString(r)
+			if err != nil {
+				return nil, err
+			}
+
+			if compound[key], err = readNBTPayload(r, fieldTag, depth+1); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unknown nbt tag type: %d", tag)
+}
+
+// readNBTNumber reads a big-endian number.
+func readNBTNumber[T int8 | int16 | int32 | int64 | float32 | float64](r io.Reader) (T, error) {
+	var v T
+	err := binary.Read(r, binary.BigEndian, &v)
+	return v, err
+}
+
+// readNBTLength reads the length of a list or array.
+func readNBTLength(r io.Reader) (int, error) {
+	var length int32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return 0, err
+	}
+
+	if length < 0 {
+		return 0, fmt.Errorf("invalid nbt list length: %d", length)
+	}
+
+	return int(length), nil
+}
+
+// readNBTArray reads a length-prefixed array of numbers.
+func readNBTArray[T int8 | int32 | int64](r io.Reader) ([]T, error) {
+	length, err := readNBTLength(r)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]T, 0, min(length, maxNBTPrealloc))
+	for range length {
+		v, err := readNBTNumber[T](r)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// readNBTString reads a length-prefixed string in Java's modified UTF-8.
+func readNBTString(r io.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+
+	var units []uint16
+	for i := 0; i < len(b); {
+		switch {
+		case b[i] < 0x80:
+			units = append(units, uint16(b[i]))
+			i++
+		case b[i]&0xE0 == 0xC0 && i+1 < len(b):
+			units = append(units, uint16(b[i]&0x1F)<<6|uint16(b[i+1]&0x3F))
+			i += 2
+		case b[i]&0xF0 == 0xE0 && i+2 < len(b):
+			units = append(units, uint16(b[i]&0x0F)<<12|uint16(b[i+1]&0x3F)<<6|uint16(b[i+2]&0x3F))
+			i += 3
+		default:
+			return "", fmt.Errorf("invalid modified utf-8 byte: %#x", b[i])
+		}
+	}
+
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,202 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeNBT(t *testing.T) {
+	tests := []struct {
+		name string
+		nbt  string
+		want any
+	}{
+		{"byte", "01 ff", int8(-1)},
+		{"short", "02 7fff", int16(32767)},
+		{"int", "03 ffffffd6", int32(-42)},
+		{"long", "04 0000000100000000", int64(1 << 32)},
+		{"float", "05 3fc00000", float32(1.5)},
+		{"double", "06 c004000000000000", float64(-2.5)},
+		{"byte array", "07 00000002 01 ff", []int8{1, -1}},
+		{"string", "08 0005 68656c6c6f", "hello"},
+		{"modified utf-8", "08 0008 c080 eda0bd edb880", "\x00😀"},
+		{"empty list", "09 00 00000000", []any{}},
+		{"list", "09 03 00000002 00000001 00000002", []any{int32(1), int32(2)}},
+		{"int array", "0b 00000001 0000002a", []int32{42}},
+		{"long array", "0c 00000001 ffffffffffffffff", []int64{-1}},
+		{
+			name: "compound",
+			nbt:  "0a 08 0004 74657874 0002 6869 01 0004 626f6c64 01 0a 0001 63 00 00",
+			want: map[string]any{"text": "hi", "bold": int8(1), "c": map[string]any{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeNBT(decodeHex(t, tt.nbt))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeNBT() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeNBTInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		nbt  string
+		want string
+	}{
+		{"empty", "", "root tag type"},
+		{"unknown tag", "0d", "unknown nbt tag type"},
+		{"end tag root", "00", "unknown nbt tag type"},
+		{"truncated int", "03 0000", "EOF"},
+		{"truncated string", "08 0005 6865", "EOF"},
+		{"negative list length", "09 03 ffffffff", "invalid nbt list length"},
+		{"non-empty list of end tags", "09 00 00000001", "not empty"},
+		{"unterminated compound", "0a 08 0001 61 0000", "EOF"},
+		{"invalid modified utf-8", "08 0001 ff", "invalid modified utf-8"},
+		{"trailing bytes", "08 0000 00", "trailing bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeNBT(decodeHex(t, tt.nbt))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// nestedNBTLists returns a list tag nesting depth lists.
+func nestedNBTLists(depth int) []byte {
+	b := []byte{TagList}
+	for i := 1; i < depth; i++ {
+		b = append(b, TagList, 0, 0, 0, 1)
+	}
+	return append(b, TagEnd, 0, 0, 0, 0)
+}
+
+func TestDecodeNBTDepth(t *testing.T) {
+	if _, err := DecodeNBT(nestedNBTLists(MaxNBTDepth + 1)); err != nil {
+		t.Errorf("depth %d: unexpected error: %v", MaxNBTDepth, err)
+	}
+
+	_, err := DecodeNBT(nestedNBTLists(MaxNBTDepth + 2))
+	if err == nil || !strings.Contains(err.Error(), "max depth") {
+		t.Errorf("error = %v, want a max depth error", err)
+	}
+
+	// compounds count towards the depth as well
+	compounds := append([]byte{TagCompound}, bytes.Repeat([]byte{TagCompound, 0, 0}, MaxNBTDepth+1)...)
+	compounds = append(compounds, bytes.Repeat([]byte{TagEnd}, MaxNBTDepth+2)...)
+	if _, err := DecodeNBT(compounds); err == nil || !strings.Contains(err.Error(), "max depth") {
+		t.Errorf("error = %v, want a max depth error", err)
+	}
+}
+
+// byteArrayNBT returns a byte array tag of the given length.
+func byteArrayNBT(length int) []byte {
+	b := binary.BigEndian.AppendUint32([]byte{TagByteArray}, uint32(length))
+	return append(b, make([]byte, length)...)
+}
+
+func TestDecodeNBTSize(t *testing.T) {
+	// the tag type and the length take 5 bytes
+	if _, err := DecodeNBT(byteArrayNBT(MaxNBTSize - 5)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err := DecodeNBT(byteArrayNBT(MaxNBTSize - 4))
+	if err == nil || !strings.Contains(err.Error(), "max size") {
+		t.Errorf("error = %v, want a max size error", err)
+	}
+
+	// a hostile list length does not allocate the whole list up front
+	huge := []byte{TagList, TagLong, 0x7f, 0xff, 0xff, 0xff}
+	if _, err := DecodeNBT(huge); err == nil {
+		t.Error("decoded a truncated list")
+	}
+}
+
+func TestNBTRoundTrip(t *testing.T) {
+	values := []any{
+		int8(1), int16(-2), int32(3), int64(-4), float32(0.5), float64(-0.25),
+		"§6Grüße 😀\x00",
+		[]int8{1, 2}, []int32{-1}, []int64{1 << 40},
+		[]any{"a", "b"},
+		map[string]any{
+			"text":  "",
+			"extra": []any{map[string]any{"text": "a"}, map[string]any{"text": "b", "italic": int8(1)}},
+			"with":  []any{[]any{int32(1)}, []any{}},
+		},
+	}
+
+	for _, want := range values {
+		raw, err := AppendNBT(nil, want)
+		if err != nil {
+			t.Fatalf("%#v: unexpected error: %v", want, err)
+		}
+
+		got, err := DecodeNBT(raw)
+		if err != nil {
+			t.Fatalf("%#v: unexpected error: %v", want, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("decoded %#v, want %#v", got, want)
+		}
+	}
+}
+
+func TestAppendNBTMixedList(t *testing.T) {
+	got, err := AppendNBT(nil, []any{"a", int8(1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// mixed lists are written as compounds wrapping each element under an empty key
+	want := decodeHex(t, "09 0a 00000002 08 0000 0001 61 00 01 0000 01 00")
+	if !bytes.Equal(got, want) {
+		t.Errorf("AppendNBT() = % x, want % x", got, want)
+	}
+}
+
+func TestReadNBT(t *testing.T) {
+	out := NewOutboundPacket(0x01)
+	if err := out.WriteNBT(map[string]any{"text": "bye"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out.WriteVarInt(7)
+
+	raw, err := out.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(raw), MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	tag, err := p.ReadNBT()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tag, map[string]any{"text": "bye"}) {
+		t.Errorf("ReadNBT() = %#v", tag)
+	}
+
+	// the tag is read without consuming the following fields
+	if n, err := p.ReadVarInt(); err != nil || n != 7 {
+		t.Errorf("ReadVarInt() = %d, %v, want 7", n, err)
+	}
+}
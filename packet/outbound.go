@@ -2,19 +2,31 @@ package packet
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net"
+	"os"
+	"slices"
+	"time"
 )
 
 const (
 	MaxPacketLength int = 2097151
 	MaxStringLength int = 32767
+
+	// MaxHandshakePacketLength is the max length of packets received in the handshaking state.
+	MaxHandshakePacketLength int = 1024
+	// MaxStatusPacketLength is the max length of packets received in the status state.
+	MaxStatusPacketLength int = 512 * 1024
 )
 
 // OutboundPacket represents a packet to be sent over a network connection.
 type OutboundPacket struct {
-	id   int32
-	body []byte
+	id     int32
+	body   []byte
+	pooled bool
 }
 
 // NewOutboundPacket creates a new OutboundPacket with a given id.
@@ -22,6 +34,12 @@ func NewOutboundPacket(id int32) *OutboundPacket {
 	return &OutboundPacket{id: id}
 }
 
+// Reset empties the packet and sets a new id, so the packet can be reused while keeping its allocated body.
+func (p *OutboundPacket) Reset(id int32) {
+	p.id = id
+	p.body = p.body[:0]
+}
+
 // WriteInt writes a 32-bit integer to the packet.
 func (p *OutboundPacket) WriteInt(n int32) {
 	buf := make([]byte, 4)
@@ -43,18 +61,35 @@ func (p *OutboundPacket) WriteLong(n int64) {
 	p.WriteBytes(buf)
 }
 
+// WriteFloat writes a 32-bit IEEE 754 floating point number to the packet.
+func (p *OutboundPacket) WriteFloat(f float32) {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, math.Float32bits(f))
+	p.WriteBytes(buf)
+}
+
+// WriteDouble writes a 64-bit IEEE 754 floating point number to the packet.
+func (p *OutboundPacket) WriteDouble(f float64) {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, math.Float64bits(f))
+	p.WriteBytes(buf)
+}
+
 // WriteVarInt writes a variable-length 32-bit integer to the packet.
+// Negative values are encoded in their two's complement form and occupy 5 bytes.
 func (p *OutboundPacket) WriteVarInt(n int32) {
-	buf := make([]byte, binary.MaxVarintLen32)
-	size := binary.PutUvarint(buf, uint64(n))
-	p.WriteBytes(buf[:size])
+	p.body = AppendVarInt(p.body, n)
 }
 
 // WriteVarLong writes a variable-length 64-bit integer to the packet.
+// Negative values are encoded in their two's complement form and occupy 10 bytes.
 func (p *OutboundPacket) WriteVarLong(n int64) {
-	buf := make([]byte, binary.MaxVarintLen64)
-	size := binary.PutUvarint(buf, uint64(n))
-	p.WriteBytes(buf[:size])
+	p.body = AppendVarLong(p.body, n)
+}
+
+// WriteUUID writes a UUID as 16 raw bytes to the packet.
+func (p *OutboundPacket) WriteUUID(uuid [16]byte) {
+	p.WriteBytes(uuid[:])
 }
 
 // WriteBool writes a boolean value to the packet.
@@ -66,19 +101,31 @@ func (p *OutboundPacket) WriteBool(value bool) {
 	}
 }
 
-// WriteString writes a string to the packet.
+// WriteString writes a string of up to MaxStringLength characters to the packet.
 func (p *OutboundPacket) WriteString(str string) error {
-	length := len(str)
-	if length > MaxStringLength {
-		return fmt.Errorf("string is longer than %d", MaxStringLength)
+	return p.WriteStringN(str, MaxStringLength)
+}
+
+// WriteStringN writes a string of up to maxUnits characters to the packet, e.g. 16 for player names.
+// Characters are counted in UTF-16 code units like vanilla does, so characters outside the
+// basic multilingual plane count as two. It returns ErrInvalidUTF8 if the string is not valid UTF-8.
+func (p *OutboundPacket) WriteStringN(str string, maxUnits int) error {
+	if err := checkString(str, maxUnits); err != nil {
+		return err
 	}
 
-	p.WriteVarInt(int32(length))
+	p.WriteVarInt(int32(len(str)))
 	p.WriteBytes([]byte(str))
 
 	return nil
 }
 
+// WriteByteArray writes a byte slice prefixed by its length as a VarInt to the packet.
+func (p *OutboundPacket) WriteByteArray(b []byte) {
+	p.WriteVarInt(int32(len(b)))
+	p.WriteBytes(b)
+}
+
 // WriteByte writes a single byte to the packet.
 func (p *OutboundPacket) WriteByte(b byte) {
 	p.body = append(p.body, b)
@@ -89,29 +136,90 @@ func (p *OutboundPacket) WriteBytes(b []byte) {
 	p.body = append(p.body, b...)
 }
 
-// Write sends the packet over the given network connection.
-func (p *OutboundPacket) Write(conn net.Conn) error {
-	payload := append(encodeVarInt(p.id), p.body...)
-	length := len(payload)
+// Write sends the packet to the given writer, e.g. a network connection.
+func (p *OutboundPacket) Write(w io.Writer) error {
+	_, err := p.WriteTo(w)
+	return err
+}
 
-	if length > MaxPacketLength {
-		return fmt.Errorf("packet exceeds max packet length of %d by %d bytes", MaxPacketLength, length-MaxPacketLength)
+// WriteWithDeadline sends the packet to a network connection, which has to accept it within the timeout.
+// The write deadline is cleared afterwards. A timeout of zero or a connection not supporting deadlines
+// writes the packet without a deadline.
+func (p *OutboundPacket) WriteWithDeadline(conn net.Conn, timeout time.Duration) error {
+	buf, err := p.AppendTo(make([]byte, 0, p.Size()))
+	if err != nil {
+		return err
+	}
+
+	return writeWithDeadline(conn, buf, timeout)
+}
+
+// writeWithDeadline writes an encoded packet to a network connection like OutboundPacket.WriteWithDeadline.
+func writeWithDeadline(conn net.Conn, buf []byte, timeout time.Duration) error {
+	write := func() error {
+		if _, err := conn.Write(buf); err != nil {
+			return fmt.Errorf("failed to write packet: %w", err)
+		}
+		return nil
+	}
+
+	if timeout <= 0 {
+		return write()
 	}
 
-	if _, err := conn.Write(encodeVarInt(int32(length))); err != nil {
-		return fmt.Errorf("failed to write packet length: %w", err)
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		if errors.Is(err, os.ErrNoDeadline) {
+			return write()
+		}
+		return fmt.Errorf("failed to set write deadline: %w", err)
 	}
 
-	if _, err := conn.Write(payload); err != nil {
-		return fmt.Errorf("failed to write packet payload: %w", err)
+	if err := write(); err != nil {
+		return err
+	}
+
+	if err := conn.SetWriteDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("failed to clear write deadline: %w", err)
 	}
 
 	return nil
 }
 
-// encodeVarInt encodes an integer into a variable-length byte slice.
-func encodeVarInt(value int32) []byte {
-	buf := make([]byte, binary.MaxVarintLen32)
-	size := binary.PutUvarint(buf, uint64(value))
-	return buf[:size]
+// WriteTo writes the length-prefixed packet to w in a single call and returns the number of bytes written.
+func (p *OutboundPacket) WriteTo(w io.Writer) (int64, error) {
+	buf, err := p.AppendTo(make([]byte, 0, p.Size()))
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(buf)
+	if err != nil {
+		return int64(n), fmt.Errorf("failed to write packet: %w", err)
+	}
+
+	return int64(n), nil
+}
+
+// Size returns the number of bytes the packet occupies on the wire, including its length prefix.
+func (p *OutboundPacket) Size() int {
+	length := p.length()
+	return VarIntSize(int32(length)) + length
+}
+
+// AppendTo appends the length-prefixed packet to dst, e.g. to send multiple packets in a single write.
+func (p *OutboundPacket) AppendTo(dst []byte) ([]byte, error) {
+	length := p.length()
+	if length > MaxPacketLength {
+		return dst, fmt.Errorf("packet exceeds max packet length of %d by %d bytes", MaxPacketLength, length-MaxPacketLength)
+	}
+
+	dst = slices.Grow(dst, VarIntSize(int32(length))+length)
+	dst = AppendVarInt(dst, int32(length))
+	dst = AppendVarInt(dst, p.id)
+	return append(dst, p.body...), nil
+}
+
+// length returns the length of the packet id and body.
+func (p *OutboundPacket) length() int {
+	return VarIntSize(p.id) + len(p.body)
 }
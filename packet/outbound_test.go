@@ -0,0 +1,274 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// goldenPackets are packets with their expected bytes on the wire.
+var goldenPackets = []struct {
+	name   string
+	packet func() *OutboundPacket
+	want   []byte
+}{
+	{
+		name: "handshake",
+		packet: func() *OutboundPacket {
+			p := NewOutboundPacket(HandshakeID)
+			p.WriteVarInt(765)
+			_ = p.WriteString("localhost")
+			p.WriteShort(25565)
+			p.WriteVarInt(1)
+			return p
+		},
+		want: []byte{
+			0x10, 0x00, 0xfd, 0x05, 0x09, 'l', 'o', 'c', 'a', 'l', 'h', 'o', 's', 't', 0x63, 0xdd, 0x01,
+		},
+	},
+	{
+		name:   "status request",
+		packet: func() *OutboundPacket { return NewOutboundPacket(StatusID) },
+		want:   []byte{0x01, 0x00},
+	},
+	{
+		name: "ping",
+		packet: func() *OutboundPacket {
+			p := NewOutboundPacket(PingID)
+			p.WriteLong(0x0102030405060708)
+			return p
+		},
+		want: []byte{0x09, 0x01, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+	},
+	{
+		name: "two byte length",
+		packet: func() *OutboundPacket {
+			p := NewOutboundPacket(0x7f)
+			p.WriteBytes(bytes.Repeat([]byte{0xaa}, 127))
+			return p
+		},
+		want: append([]byte{0x80, 0x01, 0x7f}, bytes.Repeat([]byte{0xaa}, 127)...),
+	},
+	{
+		name: "two byte id",
+		packet: func() *OutboundPacket {
+			p := NewOutboundPacket(0x80)
+			p.WriteBool(true)
+			return p
+		},
+		want: []byte{0x03, 0x80, 0x01, 0x01},
+	},
+}
+
+func TestOutboundPacketWriteTo(t *testing.T) {
+	for _, tt := range goldenPackets {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.packet().WriteTo(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("wrote % x, want % x", buf.Bytes(), tt.want)
+			}
+			if n != int64(len(tt.want)) {
+				t.Errorf("n = %d, want %d", n, len(tt.want))
+			}
+		})
+	}
+}
+
+var _ io.WriterTo = (*OutboundPacket)(nil)
+
+func TestOutboundPacketWrite(t *testing.T) {
+	// Write produces the same bytes as WriteTo
+	for _, tt := range goldenPackets {
+		var buf bytes.Buffer
+		if err := tt.packet().Write(&buf); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), tt.want) {
+			t.Errorf("%s: wrote % x, want % x", tt.name, buf.Bytes(), tt.want)
+		}
+	}
+}
+
+// failingWriter accepts n bytes before failing.
+type failingWriter struct {
+	n int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if len(b) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errWrite
+	}
+
+	w.n -= len(b)
+	return len(b), nil
+}
+
+func TestOutboundPacketWriteToErrors(t *testing.T) {
+	n, err := goldenPackets[0].packet().WriteTo(&failingWriter{n: 3})
+	if !errors.Is(err, errWrite) || n != 3 {
+		t.Errorf("n = %d, error = %v, want 3 and errWrite", n, err)
+	}
+
+	p := NewOutboundPacket(0x00)
+	p.WriteBytes(make([]byte, MaxPacketLength))
+	var buf bytes.Buffer
+	if n, err := p.WriteTo(&buf); err == nil || n != 0 || buf.Len() != 0 {
+		t.Errorf("n = %d, error = %v, wrote %d bytes, want an error without writing", n, err, buf.Len())
+	}
+}
+
+func TestOutboundPacketAppendTo(t *testing.T) {
+	for _, tt := range goldenPackets {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.packet()
+			got, err := p.AppendTo(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("appended % x, want % x", got, tt.want)
+			}
+			if p.Size() != len(tt.want) {
+				t.Errorf("Size() = %d, want %d", p.Size(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestOutboundPacketAppendToBatch(t *testing.T) {
+	// the handshake and the status request are sent in a single write
+	var want []byte
+	var batch []byte
+	for _, tt := range goldenPackets[:2] {
+		var err error
+		batch, err = tt.packet().AppendTo(batch)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want = append(want, tt.want...)
+	}
+
+	if !bytes.Equal(batch, want) {
+		t.Errorf("appended % x, want % x", batch, want)
+	}
+}
+
+func TestOutboundPacketAppendToTooLarge(t *testing.T) {
+	p := NewOutboundPacket(0x00)
+	p.WriteBytes(make([]byte, MaxPacketLength))
+
+	dst := []byte{0x01, 0x02}
+	got, err := p.AppendTo(dst)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !bytes.Equal(got, dst) {
+		t.Errorf("dst was modified: % x", got)
+	}
+}
+
+func TestOutboundPacketAppendToAllocations(t *testing.T) {
+	p := goldenPackets[0].packet()
+	dst := make([]byte, 0, p.Size())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		dst, _ = p.AppendTo(dst[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("AppendTo into a large enough buffer allocated %.0f times", allocs)
+	}
+}
+
+func TestWriteWithDeadlineTarpit(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	// the server never reads, so the write blocks until the deadline
+	start := time.Now()
+	err := goldenPackets[0].packet().WriteWithDeadline(client, 100*time.Millisecond)
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("error = %v, want os.ErrDeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WriteWithDeadline() returned after %v, want about 100ms", elapsed)
+	}
+}
+
+func TestConnWritePacketTarpit(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	conn := NewConn(client, 100*time.Millisecond)
+	if err := conn.WritePacket(goldenPackets[0].packet()); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("error = %v, want os.ErrDeadlineExceeded", err)
+	}
+}
+
+func TestWriteWithDeadlineClearsDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	go func() {
+		_, _ = io.Copy(io.Discard, server)
+	}()
+
+	if err := goldenPackets[0].packet().WriteWithDeadline(client, 50*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a stale deadline would fail this write
+	time.Sleep(100 * time.Millisecond)
+	if _, err := client.Write([]byte{0x00}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// noDeadlineConn is a connection whose deadlines are not supported, writing to a buffer.
+type noDeadlineConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *noDeadlineConn) Write(b []byte) (int, error) {
+	return c.buf.Write(b)
+}
+
+func (c *noDeadlineConn) SetWriteDeadline(time.Time) error {
+	return os.ErrNoDeadline
+}
+
+func TestWriteWithDeadlineNoDeadline(t *testing.T) {
+	for _, tt := range goldenPackets {
+		conn := &noDeadlineConn{}
+		if err := tt.packet().WriteWithDeadline(conn, time.Second); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !bytes.Equal(conn.buf.Bytes(), tt.want) {
+			t.Errorf("%s: wrote % x, want % x", tt.name, conn.buf.Bytes(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package packet
+
+import (
+	"bufio"
+	"io"
+	"math/bits"
+	"sync"
+)
+
+// minPooledBufferSize is the size of the smallest pooled body buffer.
+// Body buffers are pooled in power of two size classes up to MaxPacketLength.
+const minPooledBufferSize = 256
+
+// maxPooledOutboundSize is the max body capacity of an OutboundPacket kept by PutOutbound,
+// so a single large packet does not stay allocated.
+const maxPooledOutboundSize = 64 * 1024
+
+var (
+	bufferPools  = make([]sync.Pool, bufferSizeClass(MaxPacketLength)+1)
+	readerPool   = sync.Pool{New: func() any { return bufio.NewReader(nil) }}
+	outboundPool = sync.Pool{New: func() any { return &OutboundPacket{} }}
+)
+
+// bufferSizeClass returns the index of the smallest size class fitting size bytes.
+func bufferSizeClass(size int) int {
+	if size <= minPooledBufferSize {
+		return 0
+	}
+
+	return bits.Len(uint(size-1)) - bits.Len(uint(minPooledBufferSize-1))
+}
+
+// getBuffer returns a buffer of the given length, reusing a released buffer if possible.
+// Buffers larger than the largest size class, e.g. for a raised max packet length, are not pooled.
+func getBuffer(length int) []byte {
+	class := bufferSizeClass(length)
+	if class >= len(bufferPools) {
+		return make([]byte, length)
+	}
+
+	if buf, ok := bufferPools[class].Get().(*[]byte); ok {
+		return (*buf)[:length]
+	}
+
+	return make([]byte, length, minPooledBufferSize<<class)
+}
+
+// putBuffer returns a buffer obtained from getBuffer to its pool.
+func putBuffer(buf []byte) {
+	buf = buf[:cap(buf)]
+	class := bufferSizeClass(cap(buf))
+	if class >= len(bufferPools) {
+		return
+	}
+
+	bufferPools[class].Put(&buf)
+}
+
+// getReader returns a buffered reader reading from the given source, reusing a released reader if possible.
+func getReader(src io.Reader) *bufio.Reader {
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(src)
+	return reader
+}
+
+// Release returns the body of the packet to an internal pool for reuse by later packets.
+// The packet and all slices returned by Body must not be used after it was released.
+// Calling Release is optional, packets that are never released are garbage collected as usual.
+func (p *InboundPacket) Release() {
+	if p.body == nil {
+		return
+	}
+
+	if p.reader != nil {
+		p.reader.Reset(nil)
+		readerPool.Put(p.reader)
+	}
+	putBuffer(p.body)
+
+	p.body = nil
+	p.src = nil
+	p.reader = nil
+}
+
+// GetOutbound returns an empty OutboundPacket with the given id, reusing a packet returned by PutOutbound if possible.
+func GetOutbound(id int32) *OutboundPacket {
+	p := outboundPool.Get().(*OutboundPacket)
+	p.pooled = false
+	p.Reset(id)
+	return p
+}
+
+// PutOutbound returns a packet to the pool used by GetOutbound. The packet must not be used afterwards.
+// It panics if the packet is put twice without being taken out by GetOutbound in between.
+func PutOutbound(p *OutboundPacket) {
+	if p.pooled {
+		panic("packet: OutboundPacket was put into the pool twice")
+	}
+
+	if cap(p.body) > maxPooledOutboundSize {
+		return
+	}
+
+	p.pooled = true
+	outboundPool.Put(p)
+}
@@ -0,0 +1,149 @@
+package packet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestReleaseNeverCalled(t *testing.T) {
+	// packets that are never released keep their bodies, even if other packets are released meanwhile
+	var kept []*InboundPacket
+	for i := 0; i < 100; i++ {
+		raw := encodePacket(int32(i), bytes.Repeat([]byte{byte(i)}, 300))
+		p, err := NewInboundPacketFromReader(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if i%2 == 0 {
+			p.Release()
+			continue
+		}
+		kept = append(kept, p)
+	}
+
+	for _, p := range kept {
+		want := bytes.Repeat([]byte{byte(p.ID())}, 300)
+		if got := p.ReadRemaining(); !bytes.Equal(got, want) {
+			t.Fatalf("packet %d: body was overwritten", p.ID())
+		}
+	}
+}
+
+func TestReleaseTwice(t *testing.T) {
+	p, err := NewInboundPacketFromReader(bytes.NewReader(encodePacket(0x01, []byte("body"))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p.Release()
+	// the second release must not put the buffer into the pool again
+	p.Release()
+
+	a, b := getBuffer(4), getBuffer(4)
+	if &a[:1][0] == &b[:1][0] {
+		t.Error("a released buffer was handed out twice")
+	}
+}
+
+func TestGetBufferSizeClasses(t *testing.T) {
+	for _, length := range []int{0, 1, minPooledBufferSize, minPooledBufferSize + 1, 1 << 16, MaxPacketLength, MaxPacketLength + 1} {
+		buf := getBuffer(length)
+		if len(buf) != length {
+			t.Errorf("getBuffer(%d) returned %d bytes", length, len(buf))
+		}
+		putBuffer(buf)
+	}
+}
+
+func TestPutOutboundTwice(t *testing.T) {
+	p := GetOutbound(0x00)
+	PutOutbound(p)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("putting a packet twice did not panic")
+		}
+	}()
+	PutOutbound(p)
+}
+
+func TestGetOutboundReset(t *testing.T) {
+	p := GetOutbound(0x01)
+	p.WriteLong(42)
+	PutOutbound(p)
+
+	p = GetOutbound(0x02)
+	defer PutOutbound(p)
+
+	got, err := p.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []byte{0x01, 0x02}; !bytes.Equal(got, want) {
+		t.Errorf("reused packet = % x, want % x", got, want)
+	}
+}
+
+// benchmarkInboundPacket reads a status response sized packet, releasing it if release is set.
+func benchmarkInboundPacket(b *testing.B, release bool) {
+	raw := encodePacket(0x00, bytes.Repeat([]byte{'a'}, 8<<10))
+	r := bytes.NewReader(raw)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(raw)))
+	for i := 0; i < b.N; i++ {
+		r.Reset(raw)
+		p, err := NewInboundPacketFromReader(r)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if release {
+			p.Release()
+		}
+	}
+}
+
+func BenchmarkInboundPacket(b *testing.B) {
+	b.Run("release", func(b *testing.B) { benchmarkInboundPacket(b, true) })
+	b.Run("no release", func(b *testing.B) { benchmarkInboundPacket(b, false) })
+}
+
+func BenchmarkOutboundPacket(b *testing.B) {
+	handshake := func(p *OutboundPacket) {
+		p.WriteVarInt(765)
+		_ = p.WriteString("localhost")
+		p.WriteShort(25565)
+		p.WriteVarInt(1)
+	}
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p := NewOutboundPacket(HandshakeID)
+			handshake(p)
+			_ = p.Write(io.Discard)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p := GetOutbound(HandshakeID)
+			handshake(p)
+			_ = p.Write(io.Discard)
+			PutOutbound(p)
+		}
+	})
+
+	b.Run("reset", func(b *testing.B) {
+		b.ReportAllocs()
+		p := NewOutboundPacket(HandshakeID)
+		for i := 0; i < b.N; i++ {
+			p.Reset(HandshakeID)
+			handshake(p)
+			_ = p.Write(io.Discard)
+		}
+	})
+}
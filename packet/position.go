@@ -0,0 +1,63 @@
+package packet
+
+// packPosition packs block coordinates into a long.
+// Since 1.14 the layout is x (26 bits), z (26 bits), y (12 bits),
+// before 1.14 it was x (26 bits), y (12 bits), z (26 bits).
+// https://wiki.vg/Protocol#Position
+func packPosition(x, y, z int32, legacy bool) int64 {
+	ux := uint64(x) & 0x3FFFFFF
+	uy := uint64(y) & 0xFFF
+	uz := uint64(z) & 0x3FFFFFF
+
+	if legacy {
+		return int64(ux<<38 | uy<<26 | uz)
+	}
+	return int64(ux<<38 | uz<<12 | uy)
+}
+
+// unpackPosition unpacks block coordinates from a long, restoring the sign of negative coordinates.
+func unpackPosition(n int64, legacy bool) (x, y, z int32) {
+	// arithmetic shifts extend the sign of the field moved to the top of the long
+	x = int32(n >> 38)
+	if legacy {
+		y = int32(n << 26 >> 52)
+		z = int32(n << 38 >> 38)
+	} else {
+		y = int32(n << 52 >> 52)
+		z = int32(n << 26 >> 38)
+	}
+
+	return x, y, z
+}
+
+// WritePosition writes block coordinates packed into a long in the layout used since 1.14.
+func (p *OutboundPacket) WritePosition(x, y, z int32) {
+	p.WriteLong(packPosition(x, y, z, false))
+}
+
+// WriteLegacyPosition writes block coordinates packed into a long in the layout used before 1.14.
+func (p *OutboundPacket) WriteLegacyPosition(x, y, z int32) {
+	p.WriteLong(packPosition(x, y, z, true))
+}
+
+// ReadPosition reads block coordinates packed into a long in the layout used since 1.14.
+func (p *InboundPacket) ReadPosition() (x, y, z int32, err error) {
+	n, err := p.ReadLong()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	x, y, z = unpackPosition(n, false)
+	return x, y, z, nil
+}
+
+// ReadLegacyPosition reads block coordinates packed into a long in the layout used before 1.14.
+func (p *InboundPacket) ReadLegacyPosition() (x, y, z int32, err error) {
+	n, err := p.ReadLong()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	x, y, z = unpackPosition(n, true)
+	return x, y, z, nil
+}
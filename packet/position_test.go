@@ -0,0 +1,98 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// positionVectors pin the bit layouts of block positions. The first vector is the example of
+// https://wiki.vg/Protocol#Position: 01000110000001110110001100 10110000010101101101001000 001100111111.
+var positionVectors = []struct {
+	x, y, z int32
+	packed  uint64
+	legacy  uint64
+}{
+	{18357644, 831, -20882616, 0x4607632c15b4833f, 0x4607630cfec15b48},
+	{0, 0, 0, 0x0000000000000000, 0x0000000000000000},
+	{1, 2, 3, 0x0000004000003002, 0x0000004008000003},
+	{-1, -1, -1, 0xffffffffffffffff, 0xffffffffffffffff},
+	{-33554432, -2048, -33554432, 0x8000002000000800, 0x8000002002000000},
+	{33554431, 2047, 33554431, 0x7fffffdffffff7ff, 0x7fffffdffdffffff},
+}
+
+func TestPackPosition(t *testing.T) {
+	for _, tt := range positionVectors {
+		if got := uint64(packPosition(tt.x, tt.y, tt.z, false)); got != tt.packed {
+			t.Errorf("packPosition(%d, %d, %d) = %#016x, want %#016x", tt.x, tt.y, tt.z, got, tt.packed)
+		}
+		if got := uint64(packPosition(tt.x, tt.y, tt.z, true)); got != tt.legacy {
+			t.Errorf("legacy packPosition(%d, %d, %d) = %#016x, want %#016x", tt.x, tt.y, tt.z, got, tt.legacy)
+		}
+	}
+}
+
+func TestUnpackPosition(t *testing.T) {
+	for _, tt := range positionVectors {
+		if x, y, z := unpackPosition(int64(tt.packed), false); x != tt.x || y != tt.y || z != tt.z {
+			t.Errorf("unpackPosition(%#016x) = (%d, %d, %d), want (%d, %d, %d)", tt.packed, x, y, z, tt.x, tt.y, tt.z)
+		}
+		if x, y, z := unpackPosition(int64(tt.legacy), true); x != tt.x || y != tt.y || z != tt.z {
+			t.Errorf("legacy unpackPosition(%#016x) = (%d, %d, %d), want (%d, %d, %d)", tt.legacy, x, y, z, tt.x, tt.y, tt.z)
+		}
+	}
+}
+
+func TestPositionPacketRoundTrip(t *testing.T) {
+	out := NewOutboundPacket(0x01)
+	var want []byte
+	for _, tt := range positionVectors {
+		out.WritePosition(tt.x, tt.y, tt.z)
+		out.WriteLegacyPosition(tt.x, tt.y, tt.z)
+		want = binary.BigEndian.AppendUint64(want, tt.packed)
+		want = binary.BigEndian.AppendUint64(want, tt.legacy)
+	}
+
+	raw, err := out.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasSuffix(raw, want) {
+		t.Errorf("packet = % x, want body % x", raw, want)
+	}
+
+	p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(raw), MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	for _, tt := range positionVectors {
+		x, y, z, err := p.ReadPosition()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if x != tt.x || y != tt.y || z != tt.z {
+			t.Errorf("ReadPosition() = (%d, %d, %d), want (%d, %d, %d)", x, y, z, tt.x, tt.y, tt.z)
+		}
+
+		x, y, z, err = p.ReadLegacyPosition()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if x != tt.x || y != tt.y || z != tt.z {
+			t.Errorf("ReadLegacyPosition() = (%d, %d, %d), want (%d, %d, %d)", x, y, z, tt.x, tt.y, tt.z)
+		}
+	}
+
+	if _, _, _, err := p.ReadPosition(); err == nil {
+		t.Error("ReadPosition() past the end did not fail")
+	}
+}
+
+func TestPackPositionOutOfRange(t *testing.T) {
+	// coordinates outside the field widths wrap around
+	if x, y, z := unpackPosition(packPosition(1<<25, 1<<11, -1<<25-1, false), false); x != -1<<25 || y != -1<<11 || z != 1<<25-1 {
+		t.Errorf("unpacked (%d, %d, %d), want wrapped coordinates", x, y, z)
+	}
+}
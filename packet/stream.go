@@ -0,0 +1,143 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// InboundPacketStream represents a received packet whose body is read from the connection on demand,
+// e.g. to decode a large status response without buffering it.
+type InboundPacketStream struct {
+	id     int32
+	length int
+	body   *bodyReader
+	done   func() error
+	closed bool
+}
+
+// NewInboundPacketStream reads the length and the id of a packet of up to maxLength bytes from a reader.
+// The body is read through Body. Longer packets are rejected with an error wrapping ErrPacketTooLarge.
+func NewInboundPacketStream(r io.Reader, maxLength int) (*InboundPacketStream, error) {
+	byteReader, ok := r.(io.ByteReader)
+	if !ok {
+		byteReader = singleByteReader{r}
+	}
+
+	length, err := readPacketLength(byteReader, maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &InboundPacketStream{
+		length: length,
+		body:   &bodyReader{r: r, length: length, remaining: length},
+	}
+
+	s.id, err = ReadVarInt(s.body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packet id: %w", err)
+	}
+
+	return s, nil
+}
+
+// ID returns the id of the packet.
+func (s *InboundPacketStream) ID() int32 {
+	return s.id
+}
+
+// Len returns the total size of the packet body in bytes, including the packet id.
+func (s *InboundPacketStream) Len() int {
+	return s.length
+}
+
+// Remaining returns the number of bytes of the body that have not been read yet.
+func (s *InboundPacketStream) Remaining() int {
+	return s.body.remaining
+}
+
+// Body returns a reader for the unread remainder of the packet body, which also implements io.ByteReader.
+// It returns io.EOF at the end of the packet and ErrTruncatedPacket if the connection ends before.
+func (s *InboundPacketStream) Body() io.Reader {
+	return s.body
+}
+
+// ReadVarInt reads a variable-length 32-bit integer of up to 5 bytes from the packet body.
+func (s *InboundPacketStream) ReadVarInt() (int32, error) {
+	return ReadVarInt(s.body)
+}
+
+// Buffer reads the unread remainder of the body into an InboundPacket, e.g. to read fields of a small packet
+// using the InboundPacket methods.
+func (s *InboundPacketStream) Buffer() (*InboundPacket, error) {
+	prefix := AppendVarInt(nil, s.id)
+	p := &InboundPacket{id: s.id, body: getBuffer(len(prefix) + s.body.remaining)}
+	copy(p.body, prefix)
+
+	if _, err := io.ReadFull(s.body, p.body[len(prefix):]); err != nil {
+		p.Release()
+		return nil, err
+	}
+
+	p.src = bytes.NewReader(p.body[len(prefix):])
+	p.reader = getReader(p.src)
+
+	return p, nil
+}
+
+// Close discards the unread remainder of the body, so the next packet can be read from the connection.
+// Closing a stream multiple times has no effect.
+func (s *InboundPacketStream) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	_, err := io.Copy(io.Discard, s.body)
+	if err != nil {
+		err = fmt.Errorf("failed to discard packet body: %w", err)
+	}
+
+	if s.done != nil {
+		err = errors.Join(err, s.done())
+	}
+
+	return err
+}
+
+// bodyReader reads the body of a packet from a reader, stopping at the end of the packet.
+type bodyReader struct {
+	r         io.Reader
+	length    int
+	remaining int
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.EOF
+	}
+
+	if len(p) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.r.Read(p)
+	b.remaining -= n
+
+	if errors.Is(err, io.EOF) && b.remaining > 0 {
+		err = &ErrTruncatedPacket{Want: b.length, Got: b.length - b.remaining, Err: io.ErrUnexpectedEOF}
+	}
+
+	return n, err
+}
+
+func (b *bodyReader) ReadByte() (byte, error) {
+	var buf [1]byte
+	if _, err := io.ReadFull(b, buf[:]); err != nil {
+		return 0, err
+	}
+
+	return buf[0], nil
+}
@@ -0,0 +1,46 @@
+package packet
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// MaxStringByteLength is the maximum number of UTF-8 bytes of a string of MaxStringLength UTF-16 code units.
+const MaxStringByteLength = MaxStringLength*3 + 3
+
+// ErrInvalidUTF8 is returned when a string is not valid UTF-8.
+var ErrInvalidUTF8 = errors.New("string is not valid utf-8")
+
+// maxStringBytes returns the maximum number of UTF-8 bytes of a string of maxUnits UTF-16 code units,
+// which vanilla uses to reject a string before decoding it.
+func maxStringBytes(maxUnits int) int {
+	return maxUnits*3 + 3
+}
+
+// utf16Len returns the number of UTF-16 code units of a string, which is how the protocol limits string lengths.
+// Characters outside the basic multilingual plane are encoded as a surrogate pair and count as two units.
+func utf16Len(s string) int {
+	var n int
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+
+	return n
+}
+
+// checkString checks whether a string is valid UTF-8 and does not exceed maxUnits UTF-16 code units.
+func checkString(s string, maxUnits int) error {
+	if !utf8.ValidString(s) {
+		return ErrInvalidUTF8
+	}
+
+	if units := utf16Len(s); units > maxUnits {
+		return &ErrStringTooLong{Length: units, Max: maxUnits}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUTF16Len(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"mclib", 5},
+		{"§6gold", 6},
+		{"€", 1},
+		{"\uffff", 1},
+		{"\U00010000", 2},
+		{"😱😱", 4},
+		{"a😱b", 4},
+	}
+
+	for _, tt := range tests {
+		if got := utf16Len(tt.s); got != tt.want {
+			t.Errorf("utf16Len(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWriteStringN(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		ok   bool
+	}{
+		{"ascii", strings.Repeat("a", 16), true},
+		{"ascii too long", strings.Repeat("a", 17), false},
+		{"two bytes", strings.Repeat("§", 16), true},
+		{"two bytes too long", strings.Repeat("§", 17), false},
+		{"three bytes", strings.Repeat("€", 16), true},
+		{"three bytes too long", strings.Repeat("€", 17), false},
+		{"astral", strings.Repeat("😱", 8), true},
+		{"astral too long", strings.Repeat("😱", 8) + "a", false},
+		{"astral split at the limit", strings.Repeat("a", 15) + "😱", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewOutboundPacket(0x00)
+			err := p.WriteStringN(tt.s, 16)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if want := append(AppendVarInt(nil, int32(len(tt.s))), tt.s...); !bytes.Equal(p.body, want) {
+					t.Errorf("body = % x, want % x", p.body, want)
+				}
+				return
+			}
+
+			var tooLong *ErrStringTooLong
+			if !errors.As(err, &tooLong) {
+				t.Fatalf("error = %v, want *ErrStringTooLong", err)
+			}
+			if tooLong.Max != 16 || tooLong.Length != utf16Len(tt.s) {
+				t.Errorf("length = %d, max = %d, want %d and 16", tooLong.Length, tooLong.Max, utf16Len(tt.s))
+			}
+			if len(p.body) != 0 {
+				t.Errorf("rejected string was written: % x", p.body)
+			}
+		})
+	}
+}
+
+func TestWriteStringInvalidUTF8(t *testing.T) {
+	for _, s := range []string{"\xff", "a\xc3", "\xed\xa0\x80"} {
+		if err := NewOutboundPacket(0x00).WriteString(s); !errors.Is(err, ErrInvalidUTF8) {
+			t.Errorf("WriteString(%q) error = %v, want ErrInvalidUTF8", s, err)
+		}
+	}
+}
+
+// stringPacket returns an inbound packet whose body is a string with the given length prefix and bytes.
+func stringPacket(t *testing.T, length int, raw string) *InboundPacket {
+	t.Helper()
+
+	body := append(AppendVarInt(nil, int32(length)), raw...)
+	p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(encodePacket(0x00, body)), MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(p.Release)
+
+	return p
+}
+
+func TestReadStringN(t *testing.T) {
+	for _, s := range []string{
+		"",
+		strings.Repeat("a", 16),
+		strings.Repeat("€", 16),
+		strings.Repeat("😱", 8),
+		strings.Repeat("€", 15) + "a",
+	} {
+		got, err := stringPacket(t, len(s), s).ReadStringN(16)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", s, err)
+		}
+		if got != s {
+			t.Errorf("ReadStringN() = %q, want %q", got, s)
+		}
+	}
+}
+
+func TestReadStringNTooLong(t *testing.T) {
+	// 17 three-byte characters fit into the 3 * 16 + 3 bytes of the length check but exceed 16 characters
+	s := strings.Repeat("€", 17)
+	_, err := stringPacket(t, len(s), s).ReadStringN(16)
+
+	var tooLong *ErrStringTooLong
+	if !errors.As(err, &tooLong) || tooLong.Length != 17 || tooLong.Max != 16 {
+		t.Errorf("error = %v, want a string of 17 characters exceeding 16", err)
+	}
+
+	// the length prefix is rejected before reading the string
+	s = strings.Repeat("a", maxStringBytes(16)+1)
+	_, err = stringPacket(t, len(s), s).ReadStringN(16)
+	if !errors.As(err, &tooLong) || tooLong.Length != 52 || tooLong.Max != 51 {
+		t.Errorf("error = %v, want a string of 52 bytes exceeding 51", err)
+	}
+
+	var readErr *ErrPacketRead
+	if !errors.As(err, &readErr) || readErr.ID != 0x00 {
+		t.Errorf("error = %v, want *ErrPacketRead", err)
+	}
+}
+
+func TestReadStringInvalidUTF8(t *testing.T) {
+	for _, s := range []string{"\xff", "a\xc3", "\xed\xa0\x80", "\xf0\x9f\x98"} {
+		if _, err := stringPacket(t, len(s), s).ReadString(); !errors.Is(err, ErrInvalidUTF8) {
+			t.Errorf("ReadString(%q) error = %v, want ErrInvalidUTF8", s, err)
+		}
+	}
+}
+
+func TestReadStringTruncated(t *testing.T) {
+	if _, err := stringPacket(t, 5, "mcl").ReadString(); err == nil {
+		t.Error("ReadString() of a truncated string did not fail")
+	}
+}
@@ -0,0 +1,36 @@
+package packet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ParseUUID parses a UUID in the dashed (8-4-4-4-12) or undashed form.
+func ParseUUID(s string) ([16]byte, error) {
+	var uuid [16]byte
+
+	undashed := s
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return uuid, fmt.Errorf("invalid uuid: %s", s)
+		}
+		undashed = strings.ReplaceAll(s, "-", "")
+	}
+
+	if len(undashed) != 32 {
+		return uuid, fmt.Errorf("invalid uuid length: %s", s)
+	}
+
+	if _, err := hex.Decode(uuid[:], []byte(undashed)); err != nil {
+		return uuid, fmt.Errorf("invalid uuid: %s", s)
+	}
+
+	return uuid, nil
+}
+
+// FormatUUID formats a UUID in the dashed form.
+func FormatUUID(uuid [16]byte) string {
+	s := hex.EncodeToString(uuid[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
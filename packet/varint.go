@@ -0,0 +1,115 @@
+package packet
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	// MaxVarIntLen is the maximum number of bytes of an encoded VarInt.
+	MaxVarIntLen = 5
+	// MaxVarLongLen is the maximum number of bytes of an encoded VarLong.
+	MaxVarLongLen = 10
+
+	segmentBits byte = 0x7F
+	continueBit byte = 0x80
+)
+
+var (
+	// ErrVarIntTooLong is returned if a VarInt continues beyond MaxVarIntLen bytes.
+	ErrVarIntTooLong = errors.New("varint is longer than 5 bytes")
+	// ErrVarLongTooLong is returned if a VarLong continues beyond MaxVarLongLen bytes.
+	ErrVarLongTooLong = errors.New("varlong is longer than 10 bytes")
+)
+
+// AppendVarInt appends the Minecraft VarInt encoding of n to buf.
+// Negative values are encoded in their 32-bit two's complement form and always occupy 5 bytes.
+// https://wiki.vg/Protocol#VarInt_and_VarLong
+func AppendVarInt(buf []byte, n int32) []byte {
+	return appendVarUint(buf, uint64(uint32(n)))
+}
+
+// AppendVarLong appends the Minecraft VarLong encoding of n to buf.
+// Negative values are encoded in their 64-bit two's complement form and always occupy 10 bytes.
+func AppendVarLong(buf []byte, n int64) []byte {
+	return appendVarUint(buf, uint64(n))
+}
+
+// VarIntSize returns the number of bytes the VarInt encoding of n occupies.
+func VarIntSize(n int32) int {
+	size := 1
+	for u := uint32(n); u >= uint32(continueBit); u >>= 7 {
+		size++
+	}
+
+	return size
+}
+
+// VarLongSize returns the number of bytes the VarLong encoding of n occupies.
+func VarLongSize(n int64) int {
+	size := 1
+	for u := uint64(n); u >= uint64(continueBit); u >>= 7 {
+		size++
+	}
+
+	return size
+}
+
+// appendVarUint appends the 7 bits per byte encoding of an unsigned integer to buf.
+func appendVarUint(buf []byte, u uint64) []byte {
+	for u >= uint64(continueBit) {
+		buf = append(buf, byte(u)&segmentBits|continueBit)
+		u >>= 7
+	}
+
+	return append(buf, byte(u))
+}
+
+// ReadVarInt reads a Minecraft VarInt of at most 5 bytes.
+// Negative values are restored by truncating the decoded value to 32 bits.
+// Encodings longer than 5 bytes are rejected with ErrVarIntTooLong.
+func ReadVarInt(r io.ByteReader) (int32, error) {
+	n, _, err := ReadVarIntFrom(r)
+	return n, err
+}
+
+// ReadVarIntFrom reads a Minecraft VarInt like ReadVarInt and additionally returns the number of bytes consumed.
+func ReadVarIntFrom(r io.ByteReader) (int32, int, error) {
+	u, size, err := readVarUint(r, MaxVarIntLen, ErrVarIntTooLong)
+	return int32(uint32(u)), size, err
+}
+
+// ReadVarLong reads a Minecraft VarLong of at most 10 bytes.
+// Encodings longer than 10 bytes are rejected with ErrVarLongTooLong.
+func ReadVarLong(r io.ByteReader) (int64, error) {
+	n, _, err := ReadVarLongFrom(r)
+	return n, err
+}
+
+// ReadVarLongFrom reads a Minecraft VarLong like ReadVarLong and additionally returns the number of bytes consumed.
+func ReadVarLongFrom(r io.ByteReader) (int64, int, error) {
+	u, size, err := readVarUint(r, MaxVarLongLen, ErrVarLongTooLong)
+	return int64(u), size, err
+}
+
+// readVarUint reads a 7 bits per byte encoded unsigned integer of at most maxLen bytes
+// and returns it with the number of bytes consumed.
+func readVarUint(r io.ByteReader, maxLen int, tooBig error) (uint64, int, error) {
+	var u uint64
+	for i := 0; i < maxLen; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, i, err
+		}
+
+		u |= uint64(b&segmentBits) << (7 * i)
+		if b&continueBit == 0 {
+			return u, i + 1, nil
+		}
+	}
+
+	return 0, maxLen, tooBig
+}
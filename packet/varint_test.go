@@ -0,0 +1,320 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+// varIntTests are the examples of the protocol documentation.
+// https://wiki.vg/Protocol#VarInt_and_VarLong
+var varIntTests = []struct {
+	n    int32
+	want []byte
+}{
+	{0, []byte{0x00}},
+	{1, []byte{0x01}},
+	{2, []byte{0x02}},
+	{127, []byte{0x7f}},
+	{128, []byte{0x80, 0x01}},
+	{255, []byte{0xff, 0x01}},
+	{25565, []byte{0xdd, 0xc7, 0x01}},
+	{2097151, []byte{0xff, 0xff, 0x7f}},
+	{math.MaxInt32, []byte{0xff, 0xff, 0xff, 0xff, 0x07}},
+	{-1, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+	{math.MinInt32, []byte{0x80, 0x80, 0x80, 0x80, 0x08}},
+}
+
+func TestAppendVarInt(t *testing.T) {
+	for _, tt := range varIntTests {
+		got := AppendVarInt(nil, tt.n)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("AppendVarInt(%d) = % x, want % x", tt.n, got, tt.want)
+		}
+		if size := VarIntSize(tt.n); size != len(tt.want) {
+			t.Errorf("VarIntSize(%d) = %d, want %d", tt.n, size, len(tt.want))
+		}
+	}
+}
+
+func TestVarIntRoundTrip(t *testing.T) {
+	values := []int32{math.MinInt32, math.MinInt32 + 1, -1 << 28, -128, -2, -1, math.MaxInt32 - 1, math.MaxInt32}
+	// every power of two and its neighbours covers the boundaries between encoding lengths
+	for shift := 0; shift < 31; shift++ {
+		values = append(values, 1<<shift-1, 1<<shift, 1<<shift+1)
+	}
+
+	for _, n := range values {
+		got, size, err := ReadVarIntFrom(bytes.NewReader(AppendVarInt(nil, n)))
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", n, err)
+		}
+		if got != n || size != VarIntSize(n) {
+			t.Errorf("%d: decoded %d from %d bytes, want %d bytes", n, got, size, VarIntSize(n))
+		}
+	}
+}
+
+func TestWriteVarIntNegative(t *testing.T) {
+	// the protocol version -1 is sent in the handshake if the version is not set
+	p := NewOutboundPacket(HandshakeID)
+	p.WriteVarInt(-1)
+
+	got, err := p.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []byte{0x06, 0x00, 0xff, 0xff, 0xff, 0xff, 0x0f}
+	if !bytes.Equal(got, want) {
+		t.Errorf("packet = % x, want % x", got, want)
+	}
+}
+
+func TestReadVarIntInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want error
+	}{
+		{"six bytes", []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, ErrVarIntTooLong},
+		{"ten bytes", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, ErrVarIntTooLong},
+		{"continued fifth byte", []byte{0xff, 0xff, 0xff, 0xff, 0x8f}, ErrVarIntTooLong},
+		{"empty", nil, io.EOF},
+		{"truncated", []byte{0x80, 0x80}, io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadVarInt(bytes.NewReader(tt.raw))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadVarIntTruncatesTo32Bits(t *testing.T) {
+	// the unused high bits of the fifth byte are dropped like the Notchian server does
+	got, err := ReadVarInt(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x7f}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != -1 {
+		t.Errorf("got %d, want -1", got)
+	}
+}
+
+// continued checks whether the first n bytes of raw all have the continue bit set.
+func continued(raw []byte, n int) bool {
+	if len(raw) < n {
+		return false
+	}
+
+	for _, b := range raw[:n] {
+		if b&continueBit == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func FuzzVarInt(f *testing.F) {
+	for _, tt := range varIntTests {
+		f.Add(tt.want)
+	}
+	f.Add([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x00})
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		n, size, err := ReadVarIntFrom(bytes.NewReader(raw))
+		if err != nil {
+			// only encodings continued beyond the fifth byte are too long
+			if errors.Is(err, ErrVarIntTooLong) && !continued(raw, MaxVarIntLen) {
+				t.Fatalf("% x: rejected a VarInt ending within 5 bytes", raw)
+			}
+			return
+		}
+		if continued(raw, MaxVarIntLen) {
+			t.Fatalf("% x: accepted a VarInt longer than 5 bytes", raw)
+		}
+
+		if size > MaxVarIntLen {
+			t.Fatalf("% x: consumed %d bytes", raw, size)
+		}
+
+		// decode(encode(n)) == n for every decoded value
+		decoded, err := ReadVarInt(bytes.NewReader(AppendVarInt(nil, n)))
+		if err != nil || decoded != n {
+			t.Fatalf("%d: round trip decoded %d, %v", n, decoded, err)
+		}
+	})
+}
+
+func FuzzVarIntRoundTrip(f *testing.F) {
+	for _, tt := range varIntTests {
+		f.Add(tt.n)
+	}
+
+	f.Fuzz(func(t *testing.T, n int32) {
+		encoded := AppendVarInt(nil, n)
+		if len(encoded) > MaxVarIntLen || len(encoded) != VarIntSize(n) {
+			t.Fatalf("%d: encoded into %d bytes, size %d", n, len(encoded), VarIntSize(n))
+		}
+
+		got, err := ReadVarInt(bytes.NewReader(encoded))
+		if err != nil || got != n {
+			t.Fatalf("%d: decoded %d, %v", n, got, err)
+		}
+	})
+}
+
+// varLongTests are the examples of the protocol documentation.
+var varLongTests = []struct {
+	n    int64
+	want []byte
+}{
+	{0, []byte{0x00}},
+	{1, []byte{0x01}},
+	{127, []byte{0x7f}},
+	{128, []byte{0x80, 0x01}},
+	{math.MaxInt32, []byte{0xff, 0xff, 0xff, 0xff, 0x07}},
+	{math.MaxInt64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+	{-1, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	{math.MinInt32, []byte{0x80, 0x80, 0x80, 0x80, 0xf8, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	{math.MinInt64, []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+}
+
+func TestAppendVarLong(t *testing.T) {
+	for _, tt := range varLongTests {
+		got := AppendVarLong(nil, tt.n)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("AppendVarLong(%d) = % x, want % x", tt.n, got, tt.want)
+		}
+		if size := VarLongSize(tt.n); size != len(tt.want) {
+			t.Errorf("VarLongSize(%d) = %d, want %d", tt.n, size, len(tt.want))
+		}
+	}
+}
+
+func TestVarLongPacketRoundTrip(t *testing.T) {
+	values := []int64{math.MinInt64, math.MinInt32 - 1, math.MinInt32, -1, 0, math.MaxInt32, math.MaxInt32 + 1, math.MaxInt64}
+	for shift := 0; shift < 63; shift += 7 {
+		values = append(values, 1<<shift-1, 1<<shift)
+	}
+
+	out := NewOutboundPacket(0x01)
+	for _, n := range values {
+		out.WriteVarLong(n)
+	}
+	raw, err := out.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err := NewInboundPacketFromReaderLimit(bytes.NewReader(raw), MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	for _, n := range values {
+		got, err := p.ReadVarLong()
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", n, err)
+		}
+		if got != n {
+			t.Errorf("decoded %d, want %d", got, n)
+		}
+	}
+}
+
+func TestReadVarLongInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want error
+	}{
+		{"eleven bytes", append(bytes.Repeat([]byte{0x80}, 10), 0x00), ErrVarLongTooLong},
+		{"continued tenth byte", bytes.Repeat([]byte{0xff}, 10), ErrVarLongTooLong},
+		{"truncated", []byte{0xff, 0xff, 0xff}, io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadVarLong(bytes.NewReader(tt.raw))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzVarLongRoundTrip(f *testing.F) {
+	for _, tt := range varLongTests {
+		f.Add(tt.n)
+	}
+
+	f.Fuzz(func(t *testing.T, n int64) {
+		encoded := AppendVarLong(nil, n)
+		if len(encoded) > MaxVarLongLen || len(encoded) != VarLongSize(n) {
+			t.Fatalf("%d: encoded into %d bytes, size %d", n, len(encoded), VarLongSize(n))
+		}
+
+		got, err := ReadVarLong(bytes.NewReader(encoded))
+		if err != nil || got != n {
+			t.Fatalf("%d: decoded %d, %v", n, got, err)
+		}
+	})
+}
+
+func TestAppendVarIntKeepsPrefix(t *testing.T) {
+	dst := []byte{0xca, 0xfe}
+	got := AppendVarLong(AppendVarInt(dst, 300), 300)
+
+	want := []byte{0xca, 0xfe, 0xac, 0x02, 0xac, 0x02}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestReadVarIntFromConsumed(t *testing.T) {
+	// bytes following the VarInt are not consumed
+	r := bytes.NewReader([]byte{0xdd, 0xc7, 0x01, 0xff})
+	n, size, err := ReadVarIntFrom(r)
+	if err != nil || n != 25565 || size != 3 || r.Len() != 1 {
+		t.Errorf("got %d from %d bytes, %d bytes left, error %v", n, size, r.Len(), err)
+	}
+
+	r = bytes.NewReader([]byte{0x80, 0x80, 0x04, 0xff})
+	l, size, err := ReadVarLongFrom(r)
+	if err != nil || l != 1<<16 || size != 3 || r.Len() != 1 {
+		t.Errorf("got %d from %d bytes, %d bytes left, error %v", l, size, r.Len(), err)
+	}
+}
+
+func FuzzVarLong(f *testing.F) {
+	for _, tt := range varLongTests {
+		f.Add(tt.want)
+	}
+	f.Add(append(bytes.Repeat([]byte{0x80}, 10), 0x00))
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		n, size, err := ReadVarLongFrom(bytes.NewReader(raw))
+		if err != nil {
+			if errors.Is(err, ErrVarLongTooLong) && !continued(raw, MaxVarLongLen) {
+				t.Fatalf("% x: rejected a VarLong ending within 10 bytes", raw)
+			}
+			return
+		}
+		if continued(raw, MaxVarLongLen) || size > MaxVarLongLen {
+			t.Fatalf("% x: accepted a VarLong of %d bytes", raw, size)
+		}
+
+		decoded, err := ReadVarLong(bytes.NewReader(AppendVarLong(nil, n)))
+		if err != nil || decoded != n {
+			t.Fatalf("%d: round trip decoded %d, %v", n, decoded, err)
+		}
+	})
+}
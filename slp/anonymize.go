@@ -0,0 +1,205 @@
+package slp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// HashedFaviconPrefix prefixes the favicon hash of an anonymized Response.
+const HashedFaviconPrefix = "sha256:"
+
+// AnonymizeOptions configures how a Response is anonymized.
+type AnonymizeOptions struct {
+	// Key is the HMAC key used to hash the names and UUIDs in the player sample,
+	// so the same player can still be correlated across responses anonymized with the same key.
+	// If Key is empty, the player sample is cleared.
+	Key []byte
+
+	// HashFavicon replaces the favicon by HashedFaviconPrefix followed by its IconHash instead of dropping it.
+	HashFavicon bool
+
+	// StripDescription removes all text, events and insertions from the description
+	// while keeping its component structure and formatting.
+	StripDescription bool
+}
+
+// Anonymize returns a copy of the Response without player names, UUIDs and the favicon.
+// The original Response is not modified. Raw is dropped, since it contains the original data.
+func (r *Response) Anonymize(opts AnonymizeOptions) *Response {
+	res := *r
+	res.Raw = nil
+	res.Extra = cloneExtra(r.Extra)
+	res.Warnings = anonymizeWarnings(r.Warnings)
+	res.Description.Description = r.Description.Description.clone(opts.StripDescription)
+
+	res.Players.Sample = nil
+	if len(opts.Key) > 0 && r.Players.Sample != nil {
+		res.Players.Sample = make([]Player, len(r.Players.Sample))
+		for i, player := range r.Players.Sample {
+			res.Players.Sample[i] = player.anonymize(opts.Key)
+		}
+	}
+
+	res.Favicon = ""
+	if opts.HashFavicon && r.Favicon != "" {
+		if hash, err := r.IconHash(); err == nil {
+			res.Favicon = HashedFaviconPrefix + hash
+		}
+	}
+
+	if r.ForgeModInfo != nil {
+		modInfo := *r.ForgeModInfo
+		modInfo.ModList = slices.Clone(modInfo.ModList)
+		res.ForgeModInfo = &modInfo
+	}
+
+	if r.ForgeData != nil {
+		forgeData := *r.ForgeData
+		forgeData.Channels = slices.Clone(forgeData.Channels)
+		forgeData.Mods = slices.Clone(forgeData.Mods)
+		res.ForgeData = &forgeData
+	}
+
+	if r.ModpackData != nil {
+		modpackData := *r.ModpackData
+		res.ModpackData = &modpackData
+	}
+
+	return &res
+}
+
+// cloneExtra returns a deep copy of the unmapped fields of a Response.
+func cloneExtra(extra map[string]json.RawMessage) map[string]json.RawMessage {
+	if extra == nil {
+		return nil
+	}
+
+	cloned := make(map[string]json.RawMessage, len(extra))
+	for key, value := range extra {
+		cloned[key] = slices.Clone(value)
+	}
+
+	return cloned
+}
+
+// anonymizeWarnings returns a copy of the warnings with the details of warnings about the player sample removed,
+// which may contain names (e.g., `players.sample[0] is a name: "Notch"` becomes "players.sample[0] is a name").
+func anonymizeWarnings(warnings []string) []string {
+	if warnings == nil {
+		return nil
+	}
+
+	anonymized := make([]string, len(warnings))
+	for i, warning := range warnings {
+		if strings.HasPrefix(warning, "players.sample") {
+			warning, _, _ = strings.Cut(warning, ":")
+		}
+		anonymized[i] = warning
+	}
+
+	return anonymized
+}
+
+// anonymize replaces the name and UUID of the Player by their HMAC-SHA256 hashes.
+// The name is replaced by the first 16 hex characters of its hash, the maximum length of a player name,
+// and the UUID by a UUID made of the first 16 bytes of its hash.
+func (p Player) anonymize(key []byte) Player {
+	nameHash := hmacSHA256(key, []byte(p.Name))
+
+	id := []byte(p.ID)
+	if uuid, err := p.UUID(); err == nil {
+		id = uuid[:]
+	}
+
+	var uuid UUID
+	copy(uuid[:], hmacSHA256(key, id))
+
+	return Player{
+		Name: hex.EncodeToString(nameHash)[:16],
+		ID:   uuid.String(),
+	}
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// clone returns a deep copy of the ChatComponent.
+// If strip is set, all text, events and insertions are removed. Legacy formatting codes in the text are kept.
+func (c ChatComponent) clone(strip bool) ChatComponent {
+	if strip {
+		c.Text = legacyCodes(c.Text)
+		c.Fallback = ""
+		c.Insertion = ""
+		c.ClickEvent = nil
+		c.HoverEvent = nil
+	}
+
+	if c.ClickEvent != nil {
+		event := *c.ClickEvent
+		c.ClickEvent = &event
+	}
+
+	if c.HoverEvent != nil {
+		event := *c.HoverEvent
+		event.Contents = event.Contents.clone()
+		event.Value = event.Value.clone()
+		c.HoverEvent = &event
+	}
+
+	c.Extra = cloneComponents(c.Extra, strip)
+	c.With = cloneComponents(c.With, strip)
+
+	return c
+}
+
+// clone returns a deep copy of the HoverContents.
+func (h *HoverContents) clone() *HoverContents {
+	if h == nil {
+		return nil
+	}
+
+	contents := HoverContents{Raw: slices.Clone(h.Raw)}
+	if h.Component != nil {
+		component := *h.Component
+		component.Description = h.Component.Description.clone(false)
+		contents.Component = &component
+	}
+
+	return &contents
+}
+
+// legacyCodes returns only the legacy formatting codes contained in a text.
+func legacyCodes(text string) string {
+	var codes []rune
+	runes := []rune(text)
+	for i := 0; i < len(runes)-1; i++ {
+		if runes[i] == LegacyPrefix {
+			codes = append(codes, runes[i], runes[i+1])
+			i++
+		}
+	}
+
+	return string(codes)
+}
+
+// cloneComponents returns a deep copy of a list of components.
+func cloneComponents(components []Description, strip bool) []Description {
+	if components == nil {
+		return nil
+	}
+
+	cloned := make([]Description, len(components))
+	for i, component := range components {
+		cloned[i] = component
+		cloned[i].Description = component.Description.clone(strip)
+	}
+
+	return cloned
+}
@@ -0,0 +1,121 @@
+package slp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleResponse = `{"version":{"name":"Paper 1.20.4","protocol":765},` +
+	`"players":{"max":20,"online":3,"sample":[{"name":"Notch","id":"069a79f4-44e9-4726-a5be-fca90e38aaf5"},"jeb_",42]},` +
+	`"description":{"text":"A Minecraft Server","hoverEvent":{"action":"show_text","contents":"hello"}},` +
+	`"favicon":"data:image/png;base64,iVBORw0KGgo=","preventsChatReports":true,"custom":{"owner":"admin"}}`
+
+func TestAnonymize(t *testing.T) {
+	names := []string{"Notch", "jeb_", "069a79f4"}
+
+	for _, opts := range []AnonymizeOptions{
+		{},
+		{Key: []byte("secret")},
+		{Key: []byte("secret"), HashFavicon: true, StripDescription: true},
+	} {
+		r, err := NewResponse(sampleResponse)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		original, _ := NewResponse(sampleResponse)
+
+		if !strings.Contains(strings.Join(r.Warnings, "\n"), "jeb_") {
+			t.Fatalf("warnings %q do not contain the bare name, the test does not cover them", r.Warnings)
+		}
+
+		res := r.Anonymize(opts)
+
+		encoded, err := json.Marshal(res)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var extra []string
+		for key, value := range res.Extra {
+			extra = append(extra, key+"="+string(value))
+		}
+
+		for _, name := range names {
+			for field, value := range map[string]string{
+				"json":     string(encoded),
+				"warnings": strings.Join(res.Warnings, "\n"),
+				"extra":    strings.Join(extra, "\n"),
+				"raw":      string(res.Raw),
+			} {
+				if strings.Contains(value, name) {
+					t.Errorf("%+v: %s of the copy contains %q: %s", opts, field, name, value)
+				}
+			}
+		}
+
+		// modifying the copy must not modify the original
+		for key := range res.Extra {
+			for i := range res.Extra[key] {
+				res.Extra[key][i] = 'x'
+			}
+		}
+		for i := range res.Warnings {
+			res.Warnings[i] = ""
+		}
+		if len(res.Players.Sample) > 0 {
+			res.Players.Sample[0].Name = "changed"
+		}
+		res.Description.Description.Text = "changed"
+
+		if !reflect.DeepEqual(r, original) {
+			t.Errorf("%+v: the original response was modified:\n%+v\nwant\n%+v", opts, r, original)
+		}
+	}
+}
+
+func TestAnonymizeWarnings(t *testing.T) {
+	warnings := []string{
+		`players.sample[1] is a name: "jeb_"`,
+		`players.sample[2] is not an object: 42`,
+		`players.online is a string: "3"`,
+	}
+
+	want := []string{
+		"players.sample[1] is a name",
+		"players.sample[2] is not an object",
+		`players.online is a string: "3"`,
+	}
+	if got := anonymizeWarnings(warnings); !reflect.DeepEqual(got, want) {
+		t.Errorf("anonymizeWarnings() = %q, want %q", got, want)
+	}
+}
+
+func TestAnonymizeSampleHashes(t *testing.T) {
+	r, err := NewResponse(sampleResponse)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the same key produces the same hashes, so players can be correlated
+	first := r.Anonymize(AnonymizeOptions{Key: []byte("secret")})
+	second := r.Anonymize(AnonymizeOptions{Key: []byte("secret")})
+	other := r.Anonymize(AnonymizeOptions{Key: []byte("other")})
+
+	if len(first.Players.Sample) != 2 {
+		t.Fatalf("sample = %+v, want 2 players", first.Players.Sample)
+	}
+	if !reflect.DeepEqual(first.Players.Sample, second.Players.Sample) {
+		t.Errorf("samples differ for the same key: %+v and %+v", first.Players.Sample, second.Players.Sample)
+	}
+	if reflect.DeepEqual(first.Players.Sample, other.Players.Sample) {
+		t.Error("samples are equal for different keys")
+	}
+	if len(first.Players.Sample[0].Name) != 16 {
+		t.Errorf("name = %q, want 16 hex characters", first.Players.Sample[0].Name)
+	}
+
+	if res := r.Anonymize(AnonymizeOptions{}); res.Players.Sample != nil {
+		t.Errorf("sample = %+v, want it cleared without a key", res.Players.Sample)
+	}
+}
@@ -0,0 +1,102 @@
+package slp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ColorMode determines which escape sequences are used to render colors in a terminal.
+type ColorMode int
+
+const (
+	TrueColor ColorMode = iota // 24-bit colors
+	Color16                    // the 16 standard terminal colors
+)
+
+const ansiReset = "\x1b[0m"
+
+// ansiColors maps the named colors to their ANSI foreground color codes.
+var ansiColors = map[string]int{
+	"black":        30,
+	"dark_blue":    34,
+	"dark_green":   32,
+	"dark_aqua":    36,
+	"dark_red":     31,
+	"dark_purple":  35,
+	"gold":         33,
+	"gray":         37,
+	"dark_gray":    90,
+	"blue":         94,
+	"green":        92,
+	"aqua":         96,
+	"red":          91,
+	"light_purple": 95,
+	"yellow":       93,
+	"white":        97,
+}
+
+// ANSI renders the Description with ANSI escape sequences using 24-bit colors.
+func (d *Description) ANSI() string {
+	return d.Description.ANSIMode(TrueColor)
+}
+
+// ANSIMode renders the Description with ANSI escape sequences using the given ColorMode.
+func (d *Description) ANSIMode(mode ColorMode) string {
+	return d.Description.ANSIMode(mode)
+}
+
+// ANSI renders the ChatComponent with ANSI escape sequences using 24-bit colors.
+func (c *ChatComponent) ANSI() string {
+	return c.ANSIMode(TrueColor)
+}
+
+// ANSIMode renders the ChatComponent with ANSI escape sequences using the given ColorMode.
+// Legacy formatting codes inside the text are translated as well.
+// The output always ends with a reset of all attributes.
+func (c *ChatComponent) ANSIMode(mode ColorMode) string {
+	var b strings.Builder
+	var current style
+
+	for _, seg := range c.segments() {
+		if seg.style != current {
+			b.WriteString(seg.style.ansi(mode))
+			current = seg.style
+		}
+		b.WriteString(seg.text)
+	}
+	b.WriteString(ansiReset)
+
+	return b.String()
+}
+
+// ansi returns the escape sequence that switches a terminal to the style.
+func (s style) ansi(mode ColorMode) string {
+	codes := []string{"0"}
+
+	if c, ok := parseColor(s.color); ok {
+		if mode == Color16 {
+			codes = append(codes, strconv.Itoa(ansiColors[NearestNamedColor(s.color)]))
+		} else {
+			codes = append(codes, fmt.Sprintf("38;2;%d;%d;%d", c.r, c.g, c.b))
+		}
+	}
+
+	if s.bold {
+		codes = append(codes, "1")
+	}
+	if s.italic {
+		codes = append(codes, "3")
+	}
+	if s.underlined {
+		codes = append(codes, "4")
+	}
+	if s.obfuscated {
+		codes = append(codes, "5")
+	}
+	if s.strikethrough {
+		codes = append(codes, "9")
+	}
+
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
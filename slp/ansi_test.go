@@ -0,0 +1,91 @@
+package slp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestANSIMode(t *testing.T) {
+	tests := []struct {
+		name string
+		c    ChatComponent
+		mode ColorMode
+		want string
+	}{
+		{
+			name: "plain",
+			c:    ChatComponent{Text: "plain"},
+			want: "plain\x1b[0m",
+		},
+		{
+			name: "true color",
+			c:    ChatComponent{Text: "gold", Color: "gold", Bold: true},
+			want: "\x1b[0;38;2;255;170;0;1mgold\x1b[0m",
+		},
+		{
+			name: "16 colors",
+			c:    ChatComponent{Text: "gold", Color: "gold", Bold: true},
+			mode: Color16,
+			want: "\x1b[0;33;1mgold\x1b[0m",
+		},
+		{
+			name: "hex in 16 colors",
+			c:    ChatComponent{Text: "red", Color: "#fe5050"},
+			mode: Color16,
+			want: "\x1b[0;91mred\x1b[0m",
+		},
+		{
+			name: "decorations",
+			c:    ChatComponent{Text: "x", Italic: true, Underlined: true, Obfuscated: true, Strikethrough: true},
+			want: "\x1b[0;3;4;5;9mx\x1b[0m",
+		},
+		{
+			name: "nested extra",
+			c: ChatComponent{Text: "a", Color: "red", Extra: []Description{
+				{Description: ChatComponent{Text: "b", Extra: []Description{
+					{Description: ChatComponent{Text: "c", Bold: true}},
+				}}},
+				{Description: ChatComponent{Text: "d", Color: "green"}},
+			}},
+			mode: Color16,
+			want: "\x1b[0;91mab\x1b[0;91;1mc\x1b[0;92md\x1b[0m",
+		},
+		{
+			name: "legacy codes",
+			c:    ChatComponent{Text: "§6gold §lbold§r plain"},
+			mode: Color16,
+			want: "\x1b[0;33mgold \x1b[0;33;1mbold\x1b[0m plain\x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.ANSIMode(tt.mode); got != tt.want {
+				t.Errorf("ANSIMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentsDeeplyNested(t *testing.T) {
+	const depth = 100000
+
+	root := ChatComponent{Text: "a"}
+	current := &root
+	for i := 1; i < depth; i++ {
+		current.Extra = []Description{{Description: ChatComponent{Text: "a"}}}
+		current = &current.Extra[0].Description
+	}
+	current.Bold = true
+
+	want := strings.Repeat("a", depth)
+	if got := root.Clean(); got != want {
+		t.Errorf("Clean() returned %d bytes, want %d", len(got), len(want))
+	}
+	if got := root.ANSIMode(Color16); got != strings.Repeat("a", depth-1)+"\x1b[0;1ma\x1b[0m" {
+		t.Errorf("ANSIMode() returned an unexpected %d bytes", len(got))
+	}
+	if got := root.HTML(); !strings.HasSuffix(got, `a<span style="font-weight:bold">a</span>`) {
+		t.Errorf("HTML() ends with %q", got[max(0, len(got)-50):])
+	}
+}
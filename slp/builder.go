@@ -0,0 +1,137 @@
+package slp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TextBuilder builds styled chat components, e.g. for a server MOTD:
+//
+//	motd, err := slp.NewText("Hello ").Color("gold").Bold().
+//		Append(slp.NewText("world").Color("#ff00ff")).
+//		Newline().
+//		Append(slp.NewText("second line")).
+//		Build()
+type TextBuilder struct {
+	component ChatComponent
+	newlines  int
+	err       error
+}
+
+// NewText creates a new TextBuilder for a component with the given text.
+// Line breaks in the text count towards the line limit like those added by Newline.
+func NewText(text string) *TextBuilder {
+	b := &TextBuilder{component: ChatComponent{Text: text}, newlines: strings.Count(text, "\n")}
+	b.checkLines()
+
+	return b
+}
+
+// NewTranslatable creates a new TextBuilder for a translatable component.
+// The placeholders of the translation are substituted with the given arguments.
+func NewTranslatable(key string, with ...*TextBuilder) *TextBuilder {
+	b := &TextBuilder{component: ChatComponent{Translate: key}}
+	for _, arg := range with {
+		if arg.err != nil {
+			b.setErr(arg.err)
+		}
+		b.newlines += arg.newlines
+		b.component.With = append(b.component.With, Description{Description: arg.component})
+	}
+	b.checkLines()
+
+	return b
+}
+
+// Fallback sets the text displayed by 1.19.1+ clients if the translation key of the component is unknown.
+func (b *TextBuilder) Fallback(fallback string) *TextBuilder {
+	b.component.Fallback = fallback
+	return b
+}
+
+// Color sets the color of the component. It can be a lowercase named color or a hex color in the "#RRGGBB" format.
+func (b *TextBuilder) Color(color string) *TextBuilder {
+	if _, ok := parseColor(color); !ok {
+		b.setErr(fmt.Errorf("invalid color: %q", color))
+		return b
+	}
+
+	b.component.Color = color
+	return b
+}
+
+// Bold makes the component bold.
+func (b *TextBuilder) Bold() *TextBuilder {
+	b.component.Bold = true
+	return b
+}
+
+// Italic makes the component italic.
+func (b *TextBuilder) Italic() *TextBuilder {
+	b.component.Italic = true
+	return b
+}
+
+// Underlined makes the component underlined.
+func (b *TextBuilder) Underlined() *TextBuilder {
+	b.component.Underlined = true
+	return b
+}
+
+// Strikethrough makes the component struck through.
+func (b *TextBuilder) Strikethrough() *TextBuilder {
+	b.component.Strikethrough = true
+	return b
+}
+
+// Obfuscated makes the component obfuscated.
+func (b *TextBuilder) Obfuscated() *TextBuilder {
+	b.component.Obfuscated = true
+	return b
+}
+
+// Append appends a child component, which inherits the style of the component.
+func (b *TextBuilder) Append(child *TextBuilder) *TextBuilder {
+	if child.err != nil {
+		b.setErr(child.err)
+	}
+
+	b.newlines += child.newlines
+	b.component.Extra = append(b.component.Extra, Description{Description: child.component})
+	b.checkLines()
+
+	return b
+}
+
+// Newline starts the next line of the MOTD.
+func (b *TextBuilder) Newline() *TextBuilder {
+	b.newlines++
+	b.component.Extra = append(b.component.Extra, Description{Description: ChatComponent{Text: "\n"}})
+	b.checkLines()
+
+	return b
+}
+
+// Build returns the built component as a Description.
+// It returns an error if an invalid color was used or the description has more than two lines.
+func (b *TextBuilder) Build() (Description, error) {
+	if b.err != nil {
+		return Description{}, b.err
+	}
+
+	return Description{Description: b.component}, nil
+}
+
+// checkLines records an error if the component exceeds the number of displayed MOTD lines.
+func (b *TextBuilder) checkLines() {
+	if b.newlines >= MaxDescriptionLines {
+		b.setErr(fmt.Errorf("description cannot have more than %d lines", MaxDescriptionLines))
+	}
+}
+
+// setErr records the first error that occurred while building.
+func (b *TextBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
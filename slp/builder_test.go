@@ -0,0 +1,53 @@
+package slp
+
+import "testing"
+
+func TestTextBuilderColor(t *testing.T) {
+	tests := []struct {
+		color string
+		ok    bool
+	}{
+		{"gold", true},
+		{"light_purple", true},
+		{"#ff00ff", true},
+		{"#FF00FF", true},
+		{"GOLD", false},
+		{"Gold", false},
+		{"orange", false},
+		{"#ff00f", false},
+		{"#gg0000", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		_, err := NewText("text").Color(tt.color).Build()
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("Color(%q): error = %v, want ok = %t", tt.color, err, tt.ok)
+		}
+	}
+}
+
+func TestTextBuilderLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder func() *TextBuilder
+		ok      bool
+	}{
+		{"two lines", func() *TextBuilder { return NewText("first").Newline().Append(NewText("second")) }, true},
+		{"line break in text", func() *TextBuilder { return NewText("first\nsecond") }, true},
+		{"three lines", func() *TextBuilder { return NewText("a").Newline().Append(NewText("b")).Newline() }, false},
+		{"line breaks in text", func() *TextBuilder { return NewText("a\nb\nc") }, false},
+		{"line breaks in children", func() *TextBuilder { return NewText("a\n").Append(NewText("b\nc")) }, false},
+		{"line break in text and newline", func() *TextBuilder { return NewText("a\nb").Newline() }, false},
+		{"line breaks in arguments", func() *TextBuilder { return NewTranslatable("key", NewText("a\nb\nc")) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder().Build()
+			if ok := err == nil; ok != tt.ok {
+				t.Errorf("error = %v, want ok = %t", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package slp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Canonical returns a stable serialization of the Response for deduplication.
+// Object keys are sorted, insignificant whitespace is removed, the favicon is excluded
+// and the volatile fields (online players, sample and latency) are zeroed,
+// so two responses of the same unchanged server serialize identically.
+func (r *Response) Canonical() ([]byte, error) {
+	res := *r
+	res.Favicon = ""
+	res.Players.Online = 0
+	res.Players.Sample = nil
+	res.Latency = 0
+
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+
+	// decoding into generic values and encoding them again sorts all object keys
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode canonical JSON: %w", err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Fingerprint returns the hex encoded SHA-256 hash of the canonical serialization of the Response.
+// It returns an empty string if the Response cannot be serialized.
+func (r *Response) Fingerprint() string {
+	canonical, err := r.Canonical()
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.Sum256(canonical)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,73 @@
+package slp
+
+import (
+	"bytes"
+	"testing"
+)
+
+// canonicalPayloads are the same status response with differently ordered keys and whitespace.
+var canonicalPayloads = []string{
+	`{"version":{"name":"Paper 1.20.4","protocol":765},"players":{"max":20,"online":3},` +
+		`"description":{"text":"A ","extra":[{"text":"Server","color":"gold","bold":true}]},` +
+		`"enforcesSecureChat":true,"customData":{"b":[1,{"y":2,"x":1}],"a":"§a<&>"},"isModded":false}`,
+	`{ "isModded" : false, "customData": {"a":"§a<&>", "b":[1, {"x":1, "y":2}]},
+	  "enforcesSecureChat": true,
+	  "description": {"extra":[{"bold":true,"color":"gold","text":"Server"}],"text":"A "},
+	  "players": {"online":17, "max":20, "sample":[{"name":"Notch","id":"069a79f4-44e9-4726-a5be-fca90e38aaf5"}]},
+	  "version": {"protocol":765, "name":"Paper 1.20.4"}, "favicon":"data:image/png;base64,AAAA" }`,
+}
+
+func TestCanonicalStable(t *testing.T) {
+	var want []byte
+	var fingerprint string
+
+	for i, raw := range canonicalPayloads {
+		for run := 0; run < 10; run++ {
+			res, err := ParseResponse(raw, ParseOptions{})
+			if err != nil {
+				t.Fatalf("payload %d: unexpected error: %v", i, err)
+			}
+			res.Latency = run
+
+			got, err := res.Canonical()
+			if err != nil {
+				t.Fatalf("payload %d: unexpected error: %v", i, err)
+			}
+
+			if want == nil {
+				want, fingerprint = got, res.Fingerprint()
+				continue
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("payload %d, run %d: canonical form\n%s\ndiffers from\n%s", i, run, got, want)
+			}
+			if got := res.Fingerprint(); got != fingerprint {
+				t.Errorf("payload %d, run %d: fingerprint = %s, want %s", i, run, got, fingerprint)
+			}
+		}
+	}
+
+	const canonical = `{"customData":{"a":"§a<&>","b":[1,{"x":1,"y":2}]},` +
+		`"description":{"extra":[{"bold":true,"color":"gold","text":"Server"}],"text":"A "},` +
+		`"enforcesSecureChat":true,"isModded":false,"players":{"max":20,"online":0},` +
+		`"version":{"name":"Paper 1.20.4","protocol":765}}`
+	if string(want) != canonical {
+		t.Errorf("canonical form = %s, want %s", want, canonical)
+	}
+}
+
+func TestCanonicalDiffers(t *testing.T) {
+	base, err := ParseResponse(canonicalPayloads[0], ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changed, err := ParseResponse(bytes.Replace([]byte(canonicalPayloads[0]), []byte(`"max":20`), []byte(`"max":21`), 1), ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if base.Fingerprint() == changed.Fingerprint() {
+		t.Error("responses with a different player limit have the same fingerprint")
+	}
+}
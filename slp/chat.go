@@ -0,0 +1,46 @@
+package slp
+
+// SignaturePolicy summarizes how a server handles chat message signatures.
+type SignaturePolicy int
+
+const (
+	// SignaturesDisabled is reported by servers without secure chat support (before 1.19.1).
+	SignaturesDisabled SignaturePolicy = iota
+	// SignaturesOptional is reported by servers that accept unsigned chat messages.
+	SignaturesOptional
+	// SignaturesEnforced is reported by servers that require signed chat messages.
+	SignaturesEnforced
+	// SignaturesBlocked is reported by servers that strip signatures to prevent chat reports.
+	SignaturesBlocked
+)
+
+// String returns the name of the SignaturePolicy.
+func (p SignaturePolicy) String() string {
+	switch p {
+	case SignaturesOptional:
+		return "optional"
+	case SignaturesEnforced:
+		return "enforced"
+	case SignaturesBlocked:
+		return "blocked"
+	}
+
+	return "disabled"
+}
+
+// ChatSignaturePolicy summarizes the secure chat related fields of the Response.
+func (r *Response) ChatSignaturePolicy() SignaturePolicy {
+	if r.PreventsChatReports {
+		return SignaturesBlocked
+	}
+
+	if r.EnforcesSecureChat {
+		return SignaturesEnforced
+	}
+
+	if r.secureChatReported {
+		return SignaturesOptional
+	}
+
+	return SignaturesDisabled
+}
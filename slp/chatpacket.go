@@ -0,0 +1,60 @@
+package slp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sch8ill/mclib/packet"
+)
+
+// NBTChatProtocol is the first protocol version (1.20.3) sending chat components as NBT instead of JSON.
+const NBTChatProtocol = 765
+
+// maxChatLength is the maximum length of a chat component sent as JSON, in characters.
+const maxChatLength = 262144
+
+// WriteChat writes a chat component to a packet in the format used by the given protocol version:
+// JSON for protocols before 1.20.3 and network NBT since then.
+func WriteChat(p *packet.OutboundPacket, c ChatComponent, protocol int32) error {
+	if protocol >= NBTChatProtocol {
+		nbt, err := c.MarshalNBT()
+		if err != nil {
+			return fmt.Errorf("failed to encode chat component as nbt: %w", err)
+		}
+
+		p.WriteBytes(nbt)
+		return nil
+	}
+
+	raw, err := json.Marshal(&c)
+	if err != nil {
+		return fmt.Errorf("failed to encode chat component as json: %w", err)
+	}
+
+	return p.WriteStringN(string(raw), maxChatLength)
+}
+
+// ReadChat reads a chat component from a packet in the format used by the given protocol version:
+// JSON for protocols before 1.20.3 and network NBT since then.
+func ReadChat(p *packet.InboundPacket, protocol int32) (ChatComponent, error) {
+	if protocol >= NBTChatProtocol {
+		tag, err := p.ReadNBT()
+		if err != nil {
+			return ChatComponent{}, fmt.Errorf("failed to read chat component: %w", err)
+		}
+
+		return ChatComponentFromNBT(tag)
+	}
+
+	raw, err := p.ReadStringN(maxChatLength)
+	if err != nil {
+		return ChatComponent{}, fmt.Errorf("failed to read chat component: %w", err)
+	}
+
+	var desc Description
+	if err := desc.unmarshal([]byte(raw), 0); err != nil {
+		return ChatComponent{}, err
+	}
+
+	return desc.Description, nil
+}
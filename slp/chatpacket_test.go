@@ -0,0 +1,95 @@
+package slp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sch8ill/mclib/packet"
+)
+
+func TestReadChatDisconnectFixture(t *testing.T) {
+	// a disconnect sent by a 1.20.4 server in the configuration state right after the login,
+	// whose reason is NBT unlike the reason of the login disconnect, which is still JSON
+	raw, err := os.ReadFile("testdata/disconnect/1.20.4_configuration.hex")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		t.Fatalf("invalid hex: %v", err)
+	}
+
+	p, err := packet.NewInboundPacketFromReaderLimit(bytes.NewReader(b), packet.MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	if p.ID() != 0x01 {
+		t.Fatalf("packet id = %#x, want 0x01", p.ID())
+	}
+
+	c, err := ReadChat(p, 765)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.Translate != "disconnect.genericReason" || len(c.With) != 1 {
+		t.Errorf("component = %+v, want a generic reason with one argument", c)
+	}
+	if want := "Internal Exception: java.io.IOException: Connection reset by peer"; c.Clean() != want {
+		t.Errorf("Clean() = %q, want %q", c.Clean(), want)
+	}
+}
+
+func TestChatPacketRoundTrip(t *testing.T) {
+	want := ChatComponent{Text: "Kicked: ", Color: "red", Extra: []Description{{Description: ChatComponent{Text: "AFK", Bold: true}}}}
+
+	for _, protocol := range []int32{NBTChatProtocol - 1, NBTChatProtocol} {
+		out := packet.NewOutboundPacket(0x00)
+		if err := WriteChat(out, want, protocol); err != nil {
+			t.Fatalf("protocol %d: unexpected error: %v", protocol, err)
+		}
+
+		raw, err := out.AppendTo(nil)
+		if err != nil {
+			t.Fatalf("protocol %d: unexpected error: %v", protocol, err)
+		}
+
+		p, err := packet.NewInboundPacketFromReaderLimit(bytes.NewReader(raw), packet.MaxPacketLength)
+		if err != nil {
+			t.Fatalf("protocol %d: unexpected error: %v", protocol, err)
+		}
+
+		got, err := ReadChat(p, protocol)
+		p.Release()
+		if err != nil {
+			t.Fatalf("protocol %d: unexpected error: %v", protocol, err)
+		}
+		assertSameComponent(t, got, want)
+	}
+}
+
+func TestReadChatRejectsDeepNBT(t *testing.T) {
+	out := packet.NewOutboundPacket(0x00)
+	out.WriteBytes([]byte{packet.TagList})
+	out.WriteBytes(bytes.Repeat([]byte{packet.TagList, 0, 0, 0, 1}, packet.MaxNBTDepth+2))
+
+	raw, err := out.AppendTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err := packet.NewInboundPacketFromReaderLimit(bytes.NewReader(raw), packet.MaxPacketLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Release()
+
+	if _, err := ReadChat(p, NBTChatProtocol); err == nil || !strings.Contains(err.Error(), "max depth") {
+		t.Errorf("error = %v, want a max depth error", err)
+	}
+}
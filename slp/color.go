@@ -0,0 +1,101 @@
+package slp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// rgb represents a 24-bit color.
+type rgb struct {
+	r, g, b uint8
+}
+
+// namedColors maps the names of the Minecraft chat colors to their RGB values.
+// https://minecraft.wiki/w/Formatting_codes#Color_codes
+var namedColors = map[string]rgb{
+	"black":        {0x00, 0x00, 0x00},
+	"dark_blue":    {0x00, 0x00, 0xAA},
+	"dark_green":   {0x00, 0xAA, 0x00},
+	"dark_aqua":    {0x00, 0xAA, 0xAA},
+	"dark_red":     {0xAA, 0x00, 0x00},
+	"dark_purple":  {0xAA, 0x00, 0xAA},
+	"gold":         {0xFF, 0xAA, 0x00},
+	"gray":         {0xAA, 0xAA, 0xAA},
+	"dark_gray":    {0x55, 0x55, 0x55},
+	"blue":         {0x55, 0x55, 0xFF},
+	"green":        {0x55, 0xFF, 0x55},
+	"aqua":         {0x55, 0xFF, 0xFF},
+	"red":          {0xFF, 0x55, 0x55},
+	"light_purple": {0xFF, 0x55, 0xFF},
+	"yellow":       {0xFF, 0xFF, 0x55},
+	"white":        {0xFF, 0xFF, 0xFF},
+}
+
+// colorOrder lists the named colors in the order of their legacy formatting codes.
+var colorOrder = []string{
+	"black", "dark_blue", "dark_green", "dark_aqua", "dark_red", "dark_purple", "gold", "gray",
+	"dark_gray", "blue", "green", "aqua", "red", "light_purple", "yellow", "white",
+}
+
+// ColorRGB returns the RGB value of a named Minecraft chat color (e.g. "gold").
+func ColorRGB(name string) (r, g, b uint8, ok bool) {
+	c, ok := namedColors[strings.ToLower(name)]
+	return c.r, c.g, c.b, ok
+}
+
+// NearestNamedColor returns the name of the named color closest to a hex color in the "#RRGGBB" format.
+// Named colors are returned unchanged. It returns an empty string if the color is invalid.
+// It can be used to downgrade RGB colors for clients before 1.16.
+func NearestNamedColor(hex string) string {
+	c, ok := parseColor(hex)
+	if !ok {
+		return ""
+	}
+
+	return nearestColor(c)
+}
+
+// RGB resolves the color of the ChatComponent, which can be a named color or a hex color.
+func (c *ChatComponent) RGB() (r, g, b uint8, ok bool) {
+	color, ok := parseColor(c.Color)
+	return color.r, color.g, color.b, ok
+}
+
+// parseColor resolves a named color or a hex color in the "#RRGGBB" format.
+// Named colors have to be lowercase like the Notchian client expects, e.g. "GOLD" is rejected.
+func parseColor(color string) (rgb, bool) {
+	if c, ok := namedColors[color]; ok {
+		return c, true
+	}
+
+	if len(color) != 7 || color[0] != '#' {
+		return rgb{}, false
+	}
+
+	n, err := strconv.ParseUint(color[1:], 16, 32)
+	if err != nil {
+		return rgb{}, false
+	}
+
+	return rgb{uint8(n >> 16), uint8(n >> 8), uint8(n)}, true
+}
+
+// nearestColor returns the name of the named color closest to the given color.
+func nearestColor(c rgb) string {
+	nearest := colorOrder[0]
+	best := -1
+	for _, name := range colorOrder {
+		n := namedColors[name]
+		dr := int(c.r) - int(n.r)
+		dg := int(c.g) - int(n.g)
+		db := int(c.b) - int(n.b)
+
+		dist := dr*dr + dg*dg + db*db
+		if best < 0 || dist < best {
+			nearest = name
+			best = dist
+		}
+	}
+
+	return nearest
+}
@@ -0,0 +1,75 @@
+package slp
+
+import "testing"
+
+// vanillaPalette is the palette of the named chat colors with their legacy codes.
+// https://minecraft.wiki/w/Formatting_codes#Color_codes
+var vanillaPalette = []struct {
+	code    rune
+	name    string
+	r, g, b uint8
+}{
+	{'0', "black", 0, 0, 0},
+	{'1', "dark_blue", 0, 0, 170},
+	{'2', "dark_green", 0, 170, 0},
+	{'3', "dark_aqua", 0, 170, 170},
+	{'4', "dark_red", 170, 0, 0},
+	{'5', "dark_purple", 170, 0, 170},
+	{'6', "gold", 255, 170, 0},
+	{'7', "gray", 170, 170, 170},
+	{'8', "dark_gray", 85, 85, 85},
+	{'9', "blue", 85, 85, 255},
+	{'a', "green", 85, 255, 85},
+	{'b', "aqua", 85, 255, 255},
+	{'c', "red", 255, 85, 85},
+	{'d', "light_purple", 255, 85, 255},
+	{'e', "yellow", 255, 255, 85},
+	{'f', "white", 255, 255, 255},
+}
+
+func TestVanillaPalette(t *testing.T) {
+	if len(namedColors) != len(vanillaPalette) || len(colorOrder) != len(vanillaPalette) {
+		t.Fatalf("%d named colors, %d ordered colors, want %d", len(namedColors), len(colorOrder), len(vanillaPalette))
+	}
+
+	for i, want := range vanillaPalette {
+		r, g, b, ok := ColorRGB(want.name)
+		if !ok || r != want.r || g != want.g || b != want.b {
+			t.Errorf("ColorRGB(%q) = %d, %d, %d, %t, want %d, %d, %d", want.name, r, g, b, ok, want.r, want.g, want.b)
+		}
+
+		if colorOrder[i] != want.name {
+			t.Errorf("color of code %c = %q, want %q", want.code, colorOrder[i], want.name)
+		}
+		if got := parseLegacy("§"+string(want.code)+"x", style{}); len(got) != 1 || got[0].style.color != want.name {
+			t.Errorf("legacy code %c resolved to %+v, want %q", want.code, got, want.name)
+		}
+
+		if got := NearestNamedColor(want.name); got != want.name {
+			t.Errorf("NearestNamedColor(%q) = %q", want.name, got)
+		}
+	}
+}
+
+func TestNearestNamedColor(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want string
+	}{
+		{"#000000", "black"},
+		{"#ffffff", "white"},
+		{"#FFAA00", "gold"},
+		{"#fe5050", "red"},
+		{"#0000a0", "dark_blue"},
+		{"#808080", "gray"},
+		{"#123", ""},
+		{"ffaa00", ""},
+		{"GOLD", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NearestNamedColor(tt.hex); got != tt.want {
+			t.Errorf("NearestNamedColor(%q) = %q, want %q", tt.hex, got, tt.want)
+		}
+	}
+}
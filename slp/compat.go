@@ -0,0 +1,122 @@
+package slp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// IsPlaceholderProtocol checks whether a protocol number is a placeholder rather than a real protocol version.
+// Proxies and maintenance plugins send -1 or the max int32 value to make every client show the server as outdated
+// or to signal that they accept any version.
+func IsPlaceholderProtocol(protocol int) bool {
+	return protocol == -1 || protocol == math.MaxInt32
+}
+
+// Compatible checks whether a client using the given protocol number can join the server.
+// The following conventions are taken into account:
+//   - placeholder protocols (-1, 0x7FFFFFFF) sent by proxies are compatible unless the version name advertises
+//     a version range that does not include the client
+//   - proxies using ViaVersion or similar mirror the protocol of the handshake and advertise a version range
+//     in the version name
+//   - otherwise the protocol numbers have to match exactly, unless the version name advertises a range
+//     including the client
+//
+// The returned reason describes how the result was determined.
+func (r *Response) Compatible(protocol int) (bool, string) {
+	server := int(r.Version.Protocol)
+	min, max, ranged := r.Version.Range()
+
+	if IsPlaceholderProtocol(server) {
+		if !ranged || ProtocolVersions(protocol) == nil {
+			return true, fmt.Sprintf("server sends placeholder protocol %d", server)
+		}
+
+		if protocolInRange(protocol, min, max) {
+			return true, fmt.Sprintf("server sends placeholder protocol %d and supports %s", server, versionRange(min, max))
+		}
+		return false, fmt.Sprintf("server sends placeholder protocol %d but only supports %s", server, versionRange(min, max))
+	}
+
+	if server == protocol {
+		if ranged && min != max {
+			return true, fmt.Sprintf("server mirrors the handshake protocol and supports %s", versionRange(min, max))
+		}
+		return true, fmt.Sprintf("protocol %d matches", protocol)
+	}
+
+	if ranged && min != max && protocolInRange(protocol, min, max) {
+		return true, fmt.Sprintf("server supports %s", versionRange(min, max))
+	}
+
+	return false, fmt.Sprintf("server uses protocol %d%s, client uses protocol %d%s",
+		server, protocolNames(server), protocol, protocolNames(protocol))
+}
+
+// protocolInRange checks whether one of the versions using a protocol number lies within a version range.
+func protocolInRange(protocol int, min, max string) bool {
+	for _, version := range ProtocolVersions(protocol) {
+		if compareVersions(version, min) >= 0 && compareVersions(version, max) <= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compareVersions compares two version names like "1.20.4" component-wise.
+// An "x" component matches any value and a missing component is treated as zero.
+// It returns -1 if a is older than b, 1 if a is newer than b and 0 otherwise.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart := versionPart(aParts, i)
+		bPart := versionPart(bParts, i)
+		if aPart == "x" || bPart == "x" {
+			return 0
+		}
+
+		aNum, aErr := strconv.Atoi(aPart)
+		bNum, bErr := strconv.Atoi(bPart)
+		if aErr != nil || bErr != nil {
+			return strings.Compare(aPart, bPart)
+		}
+
+		if aNum < bNum {
+			return -1
+		}
+		if aNum > bNum {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// versionPart returns the i-th component of a split version name or "0" if it is missing.
+func versionPart(parts []string, i int) string {
+	if i >= len(parts) {
+		return "0"
+	}
+	return parts[i]
+}
+
+// versionRange formats a version range for use in a compatibility reason.
+func versionRange(min, max string) string {
+	if min == max {
+		return min
+	}
+	return min + "-" + max
+}
+
+// protocolNames formats the names of the versions using a protocol number for use in a compatibility reason.
+func protocolNames(protocol int) string {
+	versions := ProtocolVersions(protocol)
+	if versions == nil {
+		return ""
+	}
+	return " (" + versionRange(versions[0], versions[len(versions)-1]) + ")"
+}
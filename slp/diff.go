@@ -0,0 +1,111 @@
+package slp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Change represents a changed field between two responses.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// DiffOptions configures which fields are compared by Diff.
+type DiffOptions struct {
+	// Volatile includes fields that change constantly: the online player count, the player sample and the latency.
+	Volatile bool
+}
+
+// Diff compares the Response to an older Response of the same server and returns the meaningful changes
+// in a deterministic order. Volatile fields are ignored.
+func (r *Response) Diff(old *Response) []Change {
+	return r.DiffWithOptions(old, DiffOptions{})
+}
+
+// DiffWithOptions compares the Response to an older Response of the same server using the given DiffOptions
+// and returns the changes in a deterministic order.
+// A nil Response is treated as empty: compared to nil, e.g. on the first ping, every field is reported
+// as added with an empty old value. Compared the other way around, every field is reported as removed.
+func (r *Response) DiffWithOptions(old *Response, opts DiffOptions) []Change {
+	oldFields := old.diffFields(opts)
+	newFields := r.diffFields(opts)
+
+	// a response compared to nil reports every field, including empty ones
+	all := (r == nil) != (old == nil)
+
+	var changes []Change
+	for i, field := range newFields {
+		if all || field.value != oldFields[i].value {
+			changes = append(changes, Change{Field: field.name, Old: oldFields[i].value, New: field.value})
+		}
+	}
+
+	return changes
+}
+
+// diffField represents a named field value compared by Diff.
+type diffField struct {
+	name  string
+	value string
+}
+
+// diffFields returns the compared field values of the Response in a fixed order.
+// All values of a nil Response are empty.
+func (r *Response) diffFields(opts DiffOptions) []diffField {
+	if r == nil {
+		fields := new(Response).diffFields(opts)
+		for i := range fields {
+			fields[i].value = ""
+		}
+		return fields
+	}
+
+	var forgeMods int
+	if r.IsModded() {
+		forgeMods = len(r.Mods())
+	}
+
+	fields := []diffField{
+		{"version.name", r.Version.Name},
+		{"version.protocol", strconv.Itoa(int(r.Version.Protocol))},
+		{"description", r.Description.Clean()},
+		{"players.max", strconv.Itoa(int(r.Players.Max))},
+		{"favicon", r.faviconHash()},
+		{"forge.mods", strconv.Itoa(forgeMods)},
+		{"enforcesSecureChat", strconv.FormatBool(r.EnforcesSecureChat)},
+		{"previewsChat", strconv.FormatBool(r.PreviewsChat)},
+		{"preventsChatReports", strconv.FormatBool(r.PreventsChatReports)},
+	}
+
+	if opts.Volatile {
+		names := make([]string, len(r.Players.Sample))
+		for i, player := range r.Players.Sample {
+			names[i] = player.Name
+		}
+
+		fields = append(fields,
+			diffField{"players.online", strconv.Itoa(int(r.Players.Online))},
+			diffField{"players.sample", strings.Join(names, ",")},
+			diffField{"latency", strconv.Itoa(r.Latency)},
+		)
+	}
+
+	return fields
+}
+
+// faviconHash returns the hash of the favicon, an empty string if there is none
+// or the hash of the raw favicon string if it cannot be decoded.
+func (r *Response) faviconHash() string {
+	if r.Favicon == "" {
+		return ""
+	}
+
+	hash, err := r.IconHash()
+	if err != nil {
+		return HashIcon([]byte(r.Favicon))
+	}
+
+	return hash
+}
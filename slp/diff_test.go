@@ -0,0 +1,52 @@
+package slp
+
+import "testing"
+
+func TestDiffNil(t *testing.T) {
+	res := &Response{Version: Version{Name: "1.20.4", Protocol: 765}}
+
+	added := res.Diff(nil)
+	if len(added) != len(res.diffFields(DiffOptions{})) {
+		t.Fatalf("got %d changes, want one per field: %+v", len(added), added)
+	}
+	for _, change := range added {
+		if change.Old != "" {
+			t.Errorf("change %+v is not an addition", change)
+		}
+	}
+
+	removed := (*Response)(nil).DiffWithOptions(res, DiffOptions{Volatile: true})
+	if len(removed) != len(res.diffFields(DiffOptions{Volatile: true})) {
+		t.Errorf("got %d changes, want one per field: %+v", len(removed), removed)
+	}
+	for _, change := range removed {
+		if change.New != "" {
+			t.Errorf("change %+v is not a removal", change)
+		}
+	}
+
+	if changes := (*Response)(nil).Diff(nil); len(changes) != 0 {
+		t.Errorf("changes between nil responses: %+v", changes)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := &Response{Version: Version{Name: "1.20.4", Protocol: 765}}
+	res := &Response{Version: Version{Name: "1.20.6", Protocol: 766}}
+	res.Players.Online = 5
+
+	changes := res.Diff(old)
+	want := []Change{
+		{Field: "version.name", Old: "1.20.4", New: "1.20.6"},
+		{Field: "version.protocol", Old: "765", New: "766"},
+	}
+
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package slp
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// FlexInt is an integer that unmarshalls from numbers, numeric strings and floats.
+// Some server implementations send numeric fields as strings or floats.
+// Values that cannot be interpreted as a number are unmarshalled as zero.
+// FlexInt is always marshalled as a plain integer.
+type FlexInt int
+
+// UnmarshalJSON unmarshalls a number, numeric string or float into a FlexInt.
+func (i *FlexInt) UnmarshalJSON(b []byte) error {
+	n, _ := parseFlexInt(b)
+	*i = FlexInt(n)
+
+	return nil
+}
+
+// parseFlexInt interprets a JSON value as an integer.
+// It reports whether the value was a plain JSON integer.
+func parseFlexInt(b []byte) (int, bool) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || string(b) == "null" {
+		return 0, true
+	}
+
+	if n, err := strconv.ParseInt(string(b), 10, 32); err == nil {
+		return int(n), true
+	}
+
+	text := string(b)
+	if b[0] == '"' {
+		if err := json.Unmarshal(b, &text); err != nil {
+			return 0, false
+		}
+	}
+
+	f, err := strconv.ParseFloat(string(bytes.TrimSpace([]byte(text))), 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, false
+	}
+
+	return int(math.Max(math.Min(math.Trunc(f), math.MaxInt32), math.MinInt32)), false
+}
+
+// flexIntWarnings returns warnings for the FlexInt fields of a response that were not plain integers.
+func flexIntWarnings(fields map[string]json.RawMessage) []string {
+	var warnings []string
+
+	check := func(parent, key string) {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(fields[parent], &obj); err != nil {
+			return
+		}
+
+		raw, ok := obj[key]
+		if !ok {
+			return
+		}
+
+		if _, plain := parseFlexInt(raw); !plain {
+			warnings = append(warnings, parent+"."+key+" is not an integer: "+string(bytes.TrimSpace(raw)))
+		}
+	}
+
+	check("version", "protocol")
+	check("players", "max")
+	check("players", "online")
+
+	return warnings
+}
@@ -0,0 +1,124 @@
+package slp
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestFlexIntUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want FlexInt
+	}{
+		{`20`, 20},
+		{`-1`, -1},
+		{`null`, 0},
+		{`"20"`, 20},
+		{`" 20 "`, 20},
+		{`"-5"`, -5},
+		{`20.9`, 20},
+		{`-20.9`, -20},
+		{`2e3`, 2000},
+		{`"2.5"`, 2},
+		{`2147483648`, math.MaxInt32},
+		{`-2147483649`, math.MinInt32},
+		{`1e400`, 0},
+		{`"Infinity"`, math.MaxInt32},
+		{`"-Infinity"`, math.MinInt32},
+		{`"NaN"`, 0},
+		{`""`, 0},
+		{`"twenty"`, 0},
+		{`true`, 0},
+		{`{}`, 0},
+	}
+
+	for _, tt := range tests {
+		var got FlexInt
+		if err := json.Unmarshal([]byte(tt.raw), &got); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFlexIntFixtures(t *testing.T) {
+	tests := []struct {
+		file                  string
+		protocol, max, online FlexInt
+		warnings              []string
+	}{
+		{
+			file:     "strings.json",
+			protocol: 340, max: 100, online: 7,
+			warnings: []string{
+				`version.protocol is not an integer: "340"`,
+				`players.max is not an integer: "100"`,
+				`players.online is not an integer: " 7 "`,
+			},
+		},
+		{
+			file:     "floats.json",
+			protocol: 765, max: 100, online: 12,
+			warnings: []string{
+				"version.protocol is not an integer: 765.0",
+				"players.max is not an integer: 1.0e2",
+				"players.online is not an integer: 12.7",
+			},
+		},
+		{
+			file:     "out_of_range.json",
+			protocol: 765, max: math.MaxInt32, online: math.MinInt32,
+			warnings: []string{
+				"players.max is not an integer: 99999999999",
+				"players.online is not an integer: -1e20",
+			},
+		},
+		{
+			file:     "invalid.json",
+			protocol: 0, max: math.MaxInt32, online: 0,
+			warnings: []string{
+				`version.protocol is not an integer: "NaN"`,
+				`players.max is not an integer: "Infinity"`,
+				`players.online is not an integer: "twelve"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile("testdata/flexint/" + tt.file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			res, err := ParseResponse(raw, ParseOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.Version.Protocol != tt.protocol || res.Players.Max != tt.max || res.Players.Online != tt.online {
+				t.Errorf("protocol, max, online = %d, %d, %d, want %d, %d, %d",
+					res.Version.Protocol, res.Players.Max, res.Players.Online, tt.protocol, tt.max, tt.online)
+			}
+			if !slices.Equal(res.Warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", res.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestFlexIntMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(struct{ N FlexInt }{42})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != `{"N":42}` {
+		t.Errorf("marshalled %s, want a plain integer", b)
+	}
+}
@@ -0,0 +1,258 @@
+package slp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sch8ill/mclib/packet"
+)
+
+// ForgeIgnoreServerOnly is the mod marker of mods that are only required on the server side,
+// copied from IGNORESERVERONLY in Forge's NetworkConstants.
+const ForgeIgnoreServerOnly = "OHNOES" +
+	"\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631" +
+	"\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631\U0001F631"
+
+// HasChannel checks whether the server reports a network channel with the given resource location.
+func (f *ForgeData) HasChannel(res string) bool {
+	for _, channel := range f.Channels {
+		if channel.Res == res {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mod looks up the mod with the given id.
+// If the id is listed multiple times, the first occurrence is returned.
+func (f *ForgeData) Mod(id string) (*ForgeMod, bool) {
+	for i := range f.Mods {
+		if f.Mods[i].ModID == id {
+			return &f.Mods[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// IsServerOnly checks whether the mod is only required on the server side.
+func (m *ForgeMod) IsServerOnly() bool {
+	return isServerOnlyMarker(m.ModMarker)
+}
+
+// UnmarshalJSON unmarshalls forge data and decodes the compressed "d" field
+// sent by Forge 1.18.2 and newer into Mods and Channels.
+// A malformed "d" field does not fail unmarshalling, Mods and Channels are left as sent in the JSON
+// and the response records a warning instead.
+func (f *ForgeData) UnmarshalJSON(b []byte) error {
+	type forgeData ForgeData
+	if err := json.Unmarshal(b, (*forgeData)(f)); err != nil {
+		return err
+	}
+
+	if f.D == "" {
+		return nil
+	}
+
+	decoded := *f
+	if err := decoded.decodeOptimized(); err != nil {
+		f.decodeErr = fmt.Errorf("failed to decode forge data: %w", err)
+		return nil
+	}
+
+	*f = decoded
+	return nil
+}
+
+// decodeOptimized decodes the compressed "d" field into Mods and Channels.
+func (f *ForgeData) decodeOptimized() error {
+	// the "d" field packs a binary buffer into a string,
+	// where each UTF-16 code unit carries 15 bits of data
+	// and the first two code units carry the length of the buffer:
+	//		truncated     (bool)
+	//		mod count     (uint16)
+	//		mods:
+	//			channel count and server only flag (VarInt)
+	//			mod id                              (string)
+	//			version (only if not server only)   (string)
+	//			channels:
+	//				path     (string)
+	//				version  (string)
+	//				required (bool)
+	//		non mod channel count (VarInt)
+	//		non mod channels:
+	//			resource (string)
+	//			version  (string)
+	//			required (bool)
+	//
+	// https://github.com/MinecraftForge/MinecraftForge/blob/1.20.x/src/main/java/net/minecraftforge/network/ServerStatusPing.java
+
+	buf, err := decodeForgeBuffer(f.D)
+	if err != nil {
+		return err
+	}
+	r := &forgeReader{bytes.NewReader(buf)}
+
+	truncated, err := r.readBool()
+	if err != nil {
+		return err
+	}
+	f.Truncated = f.Truncated || truncated
+
+	modCount, err := r.readShort()
+	if err != nil {
+		return err
+	}
+
+	for range modCount {
+		flags, err := r.readVarInt()
+		if err != nil {
+			return err
+		}
+
+		mod := ForgeMod{ModMarker: ForgeIgnoreServerOnly}
+		if mod.ModID, err = r.readString(); err != nil {
+			return err
+		}
+
+		if flags&1 == 0 {
+			if mod.ModMarker, err = r.readString(); err != nil {
+				return err
+			}
+		}
+		f.Mods = append(f.Mods, mod)
+
+		for range flags >> 1 {
+			channel, err := r.readChannel()
+			if err != nil {
+				return err
+			}
+			channel.Res = mod.ModID + ":" + channel.Res
+			f.Channels = append(f.Channels, channel)
+		}
+	}
+
+	channelCount, err := r.readVarInt()
+	if err != nil {
+		return err
+	}
+
+	for range channelCount {
+		channel, err := r.readChannel()
+		if err != nil {
+			return err
+		}
+		f.Channels = append(f.Channels, channel)
+	}
+
+	return nil
+}
+
+// decodeForgeBuffer unpacks the binary buffer from the compressed "d" field.
+func decodeForgeBuffer(d string) ([]byte, error) {
+	units := []rune(d)
+	if len(units) < 2 {
+		return nil, errors.New("compressed forge data is too short")
+	}
+
+	size := int(units[0]&0x7FFF) | int(units[1]&0x7FFF)<<15
+	buf := make([]byte, 0, size)
+
+	var bits uint64
+	var bitCount int
+	for _, unit := range units[2:] {
+		bits |= uint64(unit&0x7FFF) << bitCount
+		bitCount += 15
+
+		for bitCount >= 8 && len(buf) < size {
+			buf = append(buf, byte(bits))
+			bits >>= 8
+			bitCount -= 8
+		}
+	}
+
+	// the last code unit may carry less than 8 remaining bits
+	if len(buf) < size && bitCount > 0 {
+		buf = append(buf, byte(bits))
+	}
+
+	if len(buf) < size {
+		return nil, fmt.Errorf("compressed forge data is truncated: expected %d bytes, got %d", size, len(buf))
+	}
+
+	return buf, nil
+}
+
+// forgeReader reads the data types used by the compressed forge data.
+type forgeReader struct {
+	*bytes.Reader
+}
+
+func (r *forgeReader) readBool() (bool, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return false, fmt.Errorf("failed to read bool: %w", err)
+	}
+
+	return b != 0, nil
+}
+
+func (r *forgeReader) readShort() (uint16, error) {
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, fmt.Errorf("failed to read short: %w", err)
+	}
+
+	return binary.BigEndian.Uint16(buf), nil
+}
+
+func (r *forgeReader) readVarInt() (int32, error) {
+	n, err := packet.ReadVarInt(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read varint: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *forgeReader) readString() (string, error) {
+	length, err := r.readVarInt()
+	if err != nil {
+		return "", err
+	}
+
+	if length < 0 || int(length) > r.Len() {
+		return "", fmt.Errorf("invalid string length: %d", length)
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", fmt.Errorf("failed to read string: %w", err)
+	}
+
+	return string(buf), nil
+}
+
+func (r *forgeReader) readChannel() (ForgeChannel, error) {
+	var channel ForgeChannel
+	var err error
+
+	if channel.Res, err = r.readString(); err != nil {
+		return channel, err
+	}
+
+	if channel.Version, err = r.readString(); err != nil {
+		return channel, err
+	}
+
+	if channel.Required, err = r.readBool(); err != nil {
+		return channel, err
+	}
+
+	return channel, nil
+}
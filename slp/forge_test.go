@@ -0,0 +1,242 @@
+package slp
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sch8ill/mclib/packet"
+)
+
+// encodeForgeBuffer packs a binary buffer into the compressed "d" field like Forge's ServerStatusPing does.
+func encodeForgeBuffer(buf []byte) string {
+	units := []rune{rune(len(buf) & 0x7FFF), rune(len(buf) >> 15 & 0x7FFF)}
+
+	var bits uint64
+	var bitCount int
+	for _, b := range buf {
+		bits |= uint64(b) << bitCount
+		bitCount += 8
+
+		for bitCount >= 15 {
+			units = append(units, rune(bits&0x7FFF))
+			bits >>= 15
+			bitCount -= 15
+		}
+	}
+	if bitCount > 0 {
+		units = append(units, rune(bits&0x7FFF))
+	}
+
+	return string(units)
+}
+
+// appendForgeString appends a string in the format of the compressed forge data.
+func appendForgeString(buf []byte, s string) []byte {
+	return append(packet.AppendVarInt(buf, int32(len(s))), s...)
+}
+
+func TestForgeIgnoreServerOnly(t *testing.T) {
+	marker, ok := strings.CutPrefix(ForgeIgnoreServerOnly, "OHNOES")
+	if !ok || marker != strings.Repeat("\U0001F631", 17) {
+		t.Errorf("ForgeIgnoreServerOnly = %q, want OHNOES followed by 17 screaming faces", ForgeIgnoreServerOnly)
+	}
+}
+
+func TestForgeDataDecodeOptimized(t *testing.T) {
+	buf := []byte{0x00}           // not truncated
+	buf = append(buf, 0x00, 0x02) // mod count
+	// server only mod without channels
+	buf = append(buf, 0x01)
+	buf = appendForgeString(buf, "serverside")
+	// mod with a version and a channel
+	buf = append(buf, 0x02)
+	buf = appendForgeString(buf, "examplemod")
+	buf = appendForgeString(buf, "1.0.0")
+	buf = appendForgeString(buf, "main")
+	buf = appendForgeString(buf, "1")
+	buf = append(buf, 0x01)
+	// non mod channel
+	buf = append(buf, 0x01)
+	buf = appendForgeString(buf, "minecraft:register")
+	buf = appendForgeString(buf, "FML3")
+	buf = append(buf, 0x00)
+
+	d, err := json.Marshal(encodeForgeBuffer(buf))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var data ForgeData
+	if err := json.Unmarshal([]byte(`{"fmlNetworkVersion":3,"d":`+string(d)+`}`), &data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []ForgeMod{
+		{ModID: "serverside", ModMarker: ForgeIgnoreServerOnly},
+		{ModID: "examplemod", ModMarker: "1.0.0"},
+	}
+	if len(data.Mods) != len(want) || data.Mods[0] != want[0] || data.Mods[1] != want[1] {
+		t.Fatalf("mods = %+v, want %+v", data.Mods, want)
+	}
+	if !data.Mods[0].IsServerOnly() || data.Mods[1].IsServerOnly() {
+		t.Errorf("server only flags are wrong: %+v", data.Mods)
+	}
+
+	channels := []ForgeChannel{
+		{Res: "examplemod:main", Version: "1", Required: true},
+		{Res: "minecraft:register", Version: "FML3"},
+	}
+	if len(data.Channels) != len(channels) || data.Channels[0] != channels[0] || data.Channels[1] != channels[1] {
+		t.Errorf("channels = %+v, want %+v", data.Channels, channels)
+	}
+}
+
+// fmlChannels are the non mod channels registered by every modern Forge server.
+var fmlChannels = []ForgeChannel{
+	{Res: "fml:loginwrapper", Version: "FML3", Required: true},
+	{Res: "fml:handshake", Version: "FML3", Required: true},
+	{Res: "fml:play", Version: "FML3", Required: true},
+	{Res: "minecraft:unregister", Version: "FML3", Required: true},
+	{Res: "minecraft:register", Version: "FML3", Required: true},
+}
+
+func TestForgeDataFixtures(t *testing.T) {
+	tests := []struct {
+		file      string
+		mods      []ForgeMod
+		channels  []ForgeChannel
+		truncated bool
+	}{
+		{
+			file: "1.18.2.json",
+			mods: []ForgeMod{
+				{ModID: "minecraft", ModMarker: "1.18.2"},
+				{ModID: "forge", ModMarker: "40.2.0"},
+				{ModID: "jei", ModMarker: "9.7.2.1001"},
+			},
+			channels: append([]ForgeChannel{
+				{Res: "forge:tier_sorting", Version: "1.0"},
+				{Res: "jei:channel", Version: "9.7.2.1001", Required: true},
+			}, fmlChannels...),
+		},
+		{
+			file: "1.19.2.json",
+			mods: []ForgeMod{
+				{ModID: "minecraft", ModMarker: "1.19.2"},
+				{ModID: "forge", ModMarker: "43.3.0"},
+				{ModID: "spark", ModMarker: ForgeIgnoreServerOnly},
+				{ModID: "create", ModMarker: "0.5.1.f"},
+			},
+			channels: append([]ForgeChannel{
+				{Res: "forge:tier_sorting", Version: "1.0"},
+				{Res: "forge:split", Version: "1.1", Required: true},
+				{Res: "create:main", Version: "3", Required: true},
+			}, fmlChannels...),
+		},
+		{
+			file: "1.20.1.json",
+			mods: []ForgeMod{
+				{ModID: "minecraft", ModMarker: "1.20.1"},
+				{ModID: "forge", ModMarker: "47.2.0"},
+				{ModID: "jei", ModMarker: "15.2.0.27"},
+				{ModID: "ferritecore", ModMarker: ForgeIgnoreServerOnly},
+			},
+			channels: append([]ForgeChannel{
+				{Res: "forge:tier_sorting", Version: "1.0"},
+				{Res: "forge:split", Version: "1.1", Required: true},
+				{Res: "jei:channel", Version: "15.2.0.27", Required: true},
+			}, fmlChannels...),
+			truncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile("testdata/forge/" + tt.file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			res, err := ParseResponse(string(raw), ParseOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(res.Warnings) != 0 {
+				t.Errorf("warnings = %q, want none", res.Warnings)
+			}
+
+			data := res.ForgeData
+			if !slices.Equal(data.Mods, tt.mods) {
+				t.Errorf("mods = %+v, want %+v", data.Mods, tt.mods)
+			}
+			if !slices.Equal(data.Channels, tt.channels) {
+				t.Errorf("channels = %+v, want %+v", data.Channels, tt.channels)
+			}
+			if data.Truncated != tt.truncated {
+				t.Errorf("truncated = %t, want %t", data.Truncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestForgeDataMalformedD(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		mods []ForgeMod
+	}{
+		{
+			name: "too short",
+			json: `{"d":"x"}`,
+		},
+		{
+			name: "truncated buffer",
+			json: `{"d":"d\u0000\u0001"}`,
+		},
+		{
+			name: "mods sent in the json are kept",
+			json: `{"mods":[{"modId":"forge","modmarker":"47.2.0"}],"d":"\u0003\u0000\u0000\u0005"}`,
+			mods: []ForgeMod{{ModID: "forge", ModMarker: "47.2.0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := `{"version":{"name":"1.20.1","protocol":763},"description":"","forgeData":` + tt.json + `}`
+			res, err := ParseResponse(raw, ParseOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(res.ForgeData.Mods) != len(tt.mods) || len(tt.mods) > 0 && res.ForgeData.Mods[0] != tt.mods[0] {
+				t.Errorf("mods = %+v, want %+v", res.ForgeData.Mods, tt.mods)
+			}
+			if len(res.ForgeData.Channels) != 0 {
+				t.Errorf("channels = %+v, want none", res.ForgeData.Channels)
+			}
+
+			var warned bool
+			for _, warning := range res.Warnings {
+				warned = warned || strings.Contains(warning, "failed to decode forge data")
+			}
+			if !warned {
+				t.Errorf("warnings = %q, want a forge data warning", res.Warnings)
+			}
+		})
+	}
+}
+
+func TestForgeReaderRejectsLongVarInt(t *testing.T) {
+	// not truncated, no mods, a non mod channel count continued beyond 5 bytes
+	buf := []byte{0x00, 0x00, 0x00, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}
+
+	data := ForgeData{D: encodeForgeBuffer(buf)}
+	err := data.decodeOptimized()
+	if !errors.Is(err, packet.ErrVarIntTooLong) {
+		t.Errorf("error = %v, want ErrVarIntTooLong", err)
+	}
+}
@@ -0,0 +1,115 @@
+package slp
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// DefaultHTMLClassPrefix is the prefix of the CSS classes used by the HTML renderer.
+const DefaultHTMLClassPrefix = "mc-"
+
+// HTMLOptions configures the HTML rendering of descriptions.
+type HTMLOptions struct {
+	// Classes renders named colors and text decorations as CSS classes instead of inline styles.
+	// Hex colors are always rendered as inline styles.
+	Classes bool
+
+	// ClassPrefix is prepended to every CSS class name.
+	// Obfuscated text is always marked with the prefixed "obfuscated" class (e.g., "mc-obfuscated"),
+	// even without Classes, so it can be animated.
+	ClassPrefix string
+}
+
+// HTML renders the Description as HTML using inline styles.
+func (d *Description) HTML() string {
+	return d.Description.HTMLWithOptions(HTMLOptions{ClassPrefix: DefaultHTMLClassPrefix})
+}
+
+// HTMLWithOptions renders the Description as HTML using the given HTMLOptions.
+func (d *Description) HTMLWithOptions(opts HTMLOptions) string {
+	return d.Description.HTMLWithOptions(opts)
+}
+
+// HTML renders the ChatComponent as HTML using inline styles.
+func (c *ChatComponent) HTML() string {
+	return c.HTMLWithOptions(HTMLOptions{ClassPrefix: DefaultHTMLClassPrefix})
+}
+
+// HTMLWithOptions renders the ChatComponent as HTML using the given HTMLOptions.
+// Every uniformly styled piece of text is wrapped in its own span, newlines are rendered as line breaks
+// and all text is escaped.
+func (c *ChatComponent) HTMLWithOptions(opts HTMLOptions) string {
+	var b strings.Builder
+
+	for _, seg := range c.segments() {
+		text := strings.ReplaceAll(html.EscapeString(seg.text), "\n", "<br>")
+
+		attrs := seg.style.htmlAttributes(opts)
+		if attrs == "" {
+			b.WriteString(text)
+			continue
+		}
+
+		b.WriteString("<span" + attrs + ">" + text + "</span>")
+	}
+
+	return b.String()
+}
+
+// htmlAttributes returns the class and style attributes representing the style.
+func (s style) htmlAttributes(opts HTMLOptions) string {
+	var classes, styles []string
+
+	if c, ok := parseColor(s.color); ok {
+		if _, named := namedColors[s.color]; named && opts.Classes {
+			classes = append(classes, opts.ClassPrefix+strings.ReplaceAll(s.color, "_", "-"))
+		} else {
+			styles = append(styles, fmt.Sprintf("color:#%02x%02x%02x", c.r, c.g, c.b))
+		}
+	}
+
+	var decorations []string
+	if s.underlined {
+		decorations = append(decorations, "underline")
+	}
+	if s.strikethrough {
+		decorations = append(decorations, "line-through")
+	}
+
+	if opts.Classes {
+		if s.bold {
+			classes = append(classes, opts.ClassPrefix+"bold")
+		}
+		if s.italic {
+			classes = append(classes, opts.ClassPrefix+"italic")
+		}
+		for _, decoration := range decorations {
+			classes = append(classes, opts.ClassPrefix+decoration)
+		}
+	} else {
+		if s.bold {
+			styles = append(styles, "font-weight:bold")
+		}
+		if s.italic {
+			styles = append(styles, "font-style:italic")
+		}
+		if len(decorations) > 0 {
+			styles = append(styles, "text-decoration:"+strings.Join(decorations, " "))
+		}
+	}
+
+	if s.obfuscated {
+		classes = append(classes, opts.ClassPrefix+"obfuscated")
+	}
+
+	var attrs string
+	if len(classes) > 0 {
+		attrs += ` class="` + html.EscapeString(strings.Join(classes, " ")) + `"`
+	}
+	if len(styles) > 0 {
+		attrs += ` style="` + strings.Join(styles, ";") + `"`
+	}
+
+	return attrs
+}
@@ -0,0 +1,89 @@
+package slp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLObfuscatedClass(t *testing.T) {
+	c := ChatComponent{Text: "secret", Obfuscated: true}
+
+	tests := []struct {
+		opts HTMLOptions
+		want string
+	}{
+		{HTMLOptions{ClassPrefix: DefaultHTMLClassPrefix}, `class="mc-obfuscated"`},
+		{HTMLOptions{Classes: true, ClassPrefix: "motd-"}, `class="motd-obfuscated"`},
+		{HTMLOptions{}, `class="obfuscated"`},
+	}
+
+	for _, tt := range tests {
+		if got := c.HTMLWithOptions(tt.opts); !strings.Contains(got, tt.want) {
+			t.Errorf("HTMLWithOptions(%+v) = %q, want it to contain %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLWithOptions(t *testing.T) {
+	nested := ChatComponent{
+		Text:  "A ",
+		Color: "gold",
+		Extra: []Description{
+			{Description: ChatComponent{Text: "bold", Bold: true, Extra: []Description{
+				{Description: ChatComponent{Text: " hex", Color: "#123abc"}},
+			}}},
+			{Description: ChatComponent{Text: "\nplain", Color: "white"}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		c    ChatComponent
+		opts HTMLOptions
+		want string
+	}{
+		{
+			name: "escaped text",
+			c:    ChatComponent{Text: `<script>alert("&")</script>`},
+			want: `&lt;script&gt;alert(&#34;&amp;&#34;)&lt;/script&gt;`,
+		},
+		{
+			name: "inline styles",
+			c:    nested,
+			opts: HTMLOptions{ClassPrefix: DefaultHTMLClassPrefix},
+			want: `<span style="color:#ffaa00">A </span>` +
+				`<span style="color:#ffaa00;font-weight:bold">bold</span>` +
+				`<span style="color:#123abc;font-weight:bold"> hex</span>` +
+				`<span style="color:#ffffff"><br>plain</span>`,
+		},
+		{
+			name: "classes",
+			c:    nested,
+			opts: HTMLOptions{Classes: true, ClassPrefix: DefaultHTMLClassPrefix},
+			want: `<span class="mc-gold">A </span>` +
+				`<span class="mc-gold mc-bold">bold</span>` +
+				`<span class="mc-bold" style="color:#123abc"> hex</span>` +
+				`<span class="mc-white"><br>plain</span>`,
+		},
+		{
+			name: "decorations",
+			c:    ChatComponent{Text: "x", Italic: true, Underlined: true, Strikethrough: true, Color: "dark_red"},
+			opts: HTMLOptions{Classes: true, ClassPrefix: "mc-"},
+			want: `<span class="mc-dark-red mc-italic mc-underline mc-line-through">x</span>`,
+		},
+		{
+			name: "escaped class prefix",
+			c:    ChatComponent{Text: "x", Bold: true},
+			opts: HTMLOptions{Classes: true, ClassPrefix: `"><b>`},
+			want: `<span class="&#34;&gt;&lt;b&gt;bold">x</span>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.HTMLWithOptions(tt.opts); got != tt.want {
+				t.Errorf("HTMLWithOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
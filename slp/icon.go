@@ -0,0 +1,172 @@
+package slp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"strings"
+)
+
+const (
+	// IconSize is the width and height a server favicon is required to have.
+	IconSize int = 64
+
+	// MaxFaviconLength is the max length of the favicon data URL, bounded by the max protocol string length.
+	MaxFaviconLength int = 32767
+
+	iconPrefix = "data:image/png;base64,"
+)
+
+var (
+	// ErrNoFavicon is returned when the status response does not contain a favicon.
+	ErrNoFavicon = errors.New("status response does not contain a favicon")
+
+	// ErrIconEncoding is returned when the favicon is not valid base64.
+	ErrIconEncoding = errors.New("favicon is not valid base64")
+
+	// ErrIconImage is returned when the decoded favicon is not a valid image.
+	ErrIconImage = errors.New("favicon is not a valid image")
+)
+
+// IconSizeError is returned when a favicon does not have the required size of 64×64 pixels.
+type IconSizeError struct {
+	Width  int
+	Height int
+}
+
+func (e *IconSizeError) Error() string {
+	return fmt.Sprintf("favicon has to be %dx%d pixels: size: %dx%d", IconSize, IconSize, e.Width, e.Height)
+}
+
+// Icon decodes the favicon string into byte data.
+func (r *Response) Icon() ([]byte, error) {
+	if r.Favicon == "" {
+		return nil, ErrNoFavicon
+	}
+
+	iconBytes, err := base64.StdEncoding.DecodeString(iconData(r.Favicon))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert base64 image to bytes: %w: %w", ErrIconEncoding, err)
+	}
+
+	return iconBytes, nil
+}
+
+// IconImage decodes the favicon into an image.Image.
+// PNG is expected, but JPEG is accepted as well, since some broken plugins send it.
+// If the image does not have the required size, it is returned alongside an IconSizeError.
+func (r *Response) IconImage() (image.Image, error) {
+	iconBytes, err := r.Icon()
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := png.Decode(bytes.NewReader(iconBytes))
+	if err != nil {
+		jpegImg, jpegErr := jpeg.Decode(bytes.NewReader(iconBytes))
+		if jpegErr != nil {
+			return nil, fmt.Errorf("failed to decode favicon: %w: %w", ErrIconImage, err)
+		}
+		img = jpegImg
+	}
+
+	size := img.Bounds().Size()
+	if size.X != IconSize || size.Y != IconSize {
+		return img, &IconSizeError{Width: size.X, Height: size.Y}
+	}
+
+	return img, nil
+}
+
+// SetIcon sets the favicon from an image.
+// Images that are not 64×64 pixels are center-cropped to a square and scaled using nearest-neighbor.
+func (r *Response) SetIcon(img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, resizeIcon(img)); err != nil {
+		return fmt.Errorf("failed to encode favicon: %w", err)
+	}
+
+	favicon := iconPrefix + base64.StdEncoding.EncodeToString(buf.Bytes())
+	if len(favicon) > MaxFaviconLength {
+		return fmt.Errorf("favicon exceeds the max length of %d: length: %d", MaxFaviconLength, len(favicon))
+	}
+
+	r.Favicon = favicon
+	return nil
+}
+
+// SetIconFile sets the favicon from an image file. PNG and JPEG images are supported.
+func (r *Response) SetIconFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open favicon: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return fmt.Errorf("failed to decode favicon: %w: %w", ErrIconImage, err)
+	}
+
+	return r.SetIcon(img)
+}
+
+// resizeIcon center-crops an image to a square and scales it to IconSize using nearest-neighbor.
+func resizeIcon(img image.Image) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() == IconSize && bounds.Dy() == IconSize {
+		return img
+	}
+
+	size := min(bounds.Dx(), bounds.Dy())
+	offsetX := bounds.Min.X + (bounds.Dx()-size)/2
+	offsetY := bounds.Min.Y + (bounds.Dy()-size)/2
+
+	icon := image.NewNRGBA(image.Rect(0, 0, IconSize, IconSize))
+	if size == 0 {
+		return icon
+	}
+
+	for y := range IconSize {
+		for x := range IconSize {
+			icon.Set(x, y, img.At(offsetX+x*size/IconSize, offsetY+y*size/IconSize))
+		}
+	}
+
+	return icon
+}
+
+// IconHash returns the hex encoded SHA-256 hash of the decoded favicon.
+// Differences in the base64 encoding of the same image do not change the hash.
+func (r *Response) IconHash() (string, error) {
+	iconBytes, err := r.Icon()
+	if err != nil {
+		return "", err
+	}
+
+	return HashIcon(iconBytes), nil
+}
+
+// HashIcon returns the hex encoded SHA-256 hash of the given favicon bytes.
+func HashIcon(icon []byte) string {
+	hash := sha256.Sum256(icon)
+	return hex.EncodeToString(hash[:])
+}
+
+// iconData strips the data URL prefix (e.g. "data:image/png;base64,") and any whitespace from a favicon.
+func iconData(favicon string) string {
+	if strings.HasPrefix(favicon, "data:") {
+		if _, data, found := strings.Cut(favicon, ","); found {
+			favicon = data
+		}
+	}
+
+	return strings.Join(strings.Fields(favicon), "")
+}
@@ -0,0 +1,74 @@
+package slp
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// faviconHash is the SHA-256 hash of the PNG in testdata/favicon.txt, as computed by sha256sum.
+const faviconHash = "2ece85f7d38ccc3bc91441fe53792dce7bf5242ca3f44b4230bec4b3f7b087ea"
+
+func TestHashIcon(t *testing.T) {
+	tests := []struct {
+		icon []byte
+		want string
+	}{
+		{nil, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{[]byte("abc"), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+
+	for _, tt := range tests {
+		if got := HashIcon(tt.icon); got != tt.want {
+			t.Errorf("HashIcon(%q) = %s, want %s", tt.icon, got, tt.want)
+		}
+	}
+}
+
+func TestIconHash(t *testing.T) {
+	raw, err := os.ReadFile("testdata/favicon.txt")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	// the fixture is wrapped into lines, which does not change the hash
+	wrapped := strings.TrimSpace(string(raw))
+	data := strings.Join(strings.Fields(strings.TrimPrefix(wrapped, iconPrefix)), "")
+	favicons := []string{wrapped, iconPrefix + data, data}
+
+	for _, favicon := range favicons {
+		res := Response{Favicon: favicon}
+		hash, err := res.IconHash()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if hash != faviconHash {
+			t.Errorf("IconHash() = %s, want %s", hash, faviconHash)
+		}
+	}
+
+	res := Response{Favicon: favicons[0]}
+	if _, err := res.IconImage(); err != nil {
+		t.Errorf("fixture is not a valid favicon: %v", err)
+	}
+}
+
+func TestIconHashErrors(t *testing.T) {
+	var res Response
+	if _, err := res.IconHash(); !errors.Is(err, ErrNoFavicon) {
+		t.Errorf("error = %v, want ErrNoFavicon", err)
+	}
+
+	res.Favicon = iconPrefix + "not base64!"
+	if _, err := res.IconHash(); !errors.Is(err, ErrIconEncoding) {
+		t.Errorf("error = %v, want ErrIconEncoding", err)
+	}
+
+	// the hash covers the decoded bytes, not the base64 text
+	res.Favicon = iconPrefix + base64.StdEncoding.EncodeToString([]byte("abc"))
+	if hash, err := res.IconHash(); err != nil || hash != HashIcon([]byte("abc")) {
+		t.Errorf("IconHash() = %s, %v", hash, err)
+	}
+}
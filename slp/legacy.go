@@ -0,0 +1,218 @@
+package slp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LegacyPrefix is the character introducing a legacy formatting code (e.g. "§6" for gold).
+const LegacyPrefix = '§'
+
+// legacyColorCodes lists the legacy color codes in the order of colorOrder.
+const legacyColorCodes = "0123456789abcdef"
+
+// ParseLegacyText converts a text containing legacy formatting codes into a ChatComponent.
+// Besides the classic codes, hex colors in the "§x§R§R§G§G§B§B" and the "&#RRGGBB" plugin format are recognized.
+// Every uniformly styled piece of text becomes an element of Extra.
+// Malformed codes are kept as literal text.
+func ParseLegacyText(text string) ChatComponent {
+	var c ChatComponent
+	for _, seg := range parseLegacy(text, style{}) {
+		c.Extra = append(c.Extra, Description{Description: seg.component()})
+	}
+
+	return c
+}
+
+// Legacy converts the ChatComponent into a text using legacy formatting codes.
+// Hex colors are encoded in the "§x§R§R§G§G§B§B" format understood by 1.16+ clients through Spigot.
+func (c *ChatComponent) Legacy() string {
+	return c.legacy(false)
+}
+
+// LegacyNamed converts the ChatComponent into a text using legacy formatting codes.
+// Hex colors are replaced by the nearest named color for clients before 1.16.
+func (c *ChatComponent) LegacyNamed() string {
+	return c.legacy(true)
+}
+
+// legacy converts the ChatComponent into a text using legacy formatting codes.
+func (c *ChatComponent) legacy(named bool) string {
+	var b strings.Builder
+	var current style
+
+	for _, seg := range c.segments() {
+		if seg.style != current {
+			b.WriteString(seg.style.legacy(named))
+			current = seg.style
+		}
+		b.WriteString(seg.text)
+	}
+
+	return b.String()
+}
+
+// legacy returns the legacy formatting codes switching to the style.
+func (s style) legacy(named bool) string {
+	var b strings.Builder
+
+	color := s.color
+	if named && strings.HasPrefix(color, "#") {
+		color = NearestNamedColor(color)
+	}
+
+	// a color code resets the formatting, so it has to come first
+	if i := colorIndex(color); i >= 0 {
+		b.WriteRune(LegacyPrefix)
+		b.WriteByte(legacyColorCodes[i])
+	} else if _, ok := parseColor(color); ok {
+		b.WriteRune(LegacyPrefix)
+		b.WriteByte('x')
+		for _, digit := range strings.ToLower(color[1:]) {
+			b.WriteRune(LegacyPrefix)
+			b.WriteRune(digit)
+		}
+	} else {
+		b.WriteRune(LegacyPrefix)
+		b.WriteByte('r')
+	}
+
+	for _, format := range []struct {
+		enabled bool
+		code    byte
+	}{{s.obfuscated, 'k'}, {s.bold, 'l'}, {s.strikethrough, 'm'}, {s.underlined, 'n'}, {s.italic, 'o'}} {
+		if format.enabled {
+			b.WriteRune(LegacyPrefix)
+			b.WriteByte(format.code)
+		}
+	}
+
+	return b.String()
+}
+
+// colorIndex returns the index of a named color in colorOrder or -1.
+func colorIndex(color string) int {
+	for i, name := range colorOrder {
+		if strings.EqualFold(name, color) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseLegacy splits a text containing legacy formatting codes into styled segments.
+// Codes that are unknown or incomplete are kept as literal text.
+func parseLegacy(text string, base style) []segment {
+	if text == "" {
+		return nil
+	}
+
+	if !strings.ContainsRune(text, LegacyPrefix) && !strings.Contains(text, "&#") {
+		return []segment{{text: text, style: base}}
+	}
+
+	var segs []segment
+	var b strings.Builder
+	s := base
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		next, length, ok := s.applyLegacyAt(runes[i:], base)
+		if !ok {
+			b.WriteRune(runes[i])
+			continue
+		}
+
+		if b.Len() > 0 {
+			segs = append(segs, segment{text: b.String(), style: s})
+			b.Reset()
+		}
+		s = next
+		i += length - 1
+	}
+
+	if b.Len() > 0 {
+		segs = append(segs, segment{text: b.String(), style: s})
+	}
+
+	return segs
+}
+
+// applyLegacyAt applies the formatting code at the start of runes to s.
+// It returns the resulting style and the length of the code.
+func (s style) applyLegacyAt(runes []rune, base style) (style, int, bool) {
+	if len(runes) < 2 {
+		return s, 0, false
+	}
+
+	// &#RRGGBB
+	if runes[0] == '&' {
+		if runes[1] != '#' || len(runes) < 8 || !isHex(runes[2:8]) {
+			return s, 0, false
+		}
+
+		return style{color: "#" + strings.ToLower(string(runes[2:8]))}, 8, true
+	}
+
+	if runes[0] != LegacyPrefix {
+		return s, 0, false
+	}
+
+	// §x§R§R§G§G§B§B
+	if unicode.ToLower(runes[1]) == 'x' && len(runes) >= 14 {
+		digits := make([]rune, 0, 6)
+		for i := 2; i < 14; i += 2 {
+			if runes[i] != LegacyPrefix {
+				break
+			}
+			digits = append(digits, runes[i+1])
+		}
+
+		if len(digits) == 6 && isHex(digits) {
+			return style{color: "#" + strings.ToLower(string(digits))}, 14, true
+		}
+	}
+
+	next, ok := s.applyLegacy(unicode.ToLower(runes[1]), base)
+	return next, 2, ok
+}
+
+// applyLegacy returns the style resulting from applying a legacy formatting code to s.
+// A reset restores the base style.
+func (s style) applyLegacy(code rune, base style) (style, bool) {
+	// color codes reset all formatting
+	if i := strings.IndexRune(legacyColorCodes, code); i >= 0 {
+		return style{color: colorOrder[i]}, true
+	}
+
+	switch code {
+	case 'k':
+		s.obfuscated = true
+	case 'l':
+		s.bold = true
+	case 'm':
+		s.strikethrough = true
+	case 'n':
+		s.underlined = true
+	case 'o':
+		s.italic = true
+	case 'r':
+		return base, true
+	default:
+		return s, false
+	}
+
+	return s, true
+}
+
+// isHex checks whether all runes are hexadecimal digits.
+func isHex(runes []rune) bool {
+	for _, r := range runes {
+		if !unicode.Is(unicode.ASCII_Hex_Digit, r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,62 @@
+package slp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// legacyPingHeader introduces the null-delimited legacy ping payload sent by 1.4 - 1.6 servers.
+const legacyPingHeader = "§1\x00"
+
+// ParseLegacy parses the payload of a legacy ping kick packet into a Response marked as Legacy.
+// Two formats are supported:
+//   - 1.4 - 1.6: §1\x00protocol\x00version\x00motd\x00online\x00max
+//   - Beta 1.8 - 1.3: motd§online§max
+//
+// Beta payloads do not contain a version, their protocol is set to -1.
+func ParseLegacy(payload string) (*Response, error) {
+	var motd, online, max string
+	res := &Response{Legacy: true, Raw: []byte(payload)}
+
+	if strings.HasPrefix(payload, legacyPingHeader) {
+		fields := strings.Split(strings.TrimPrefix(payload, legacyPingHeader), "\x00")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("legacy ping payload contains %d fields instead of 5", len(fields))
+		}
+
+		protocol, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid legacy protocol version: %w", err)
+		}
+
+		res.Version = Version{Name: fields[1], Protocol: FlexInt(protocol)}
+		motd, online, max = fields[2], fields[3], fields[4]
+	} else {
+		// the motd may itself contain formatting codes, so the counts are taken from the end
+		fields := strings.Split(payload, string(LegacyPrefix))
+		if len(fields) < 3 {
+			return nil, errors.New("legacy ping payload is missing the player counts")
+		}
+
+		res.Version = Version{Protocol: -1}
+		motd = strings.Join(fields[:len(fields)-2], string(LegacyPrefix))
+		online, max = fields[len(fields)-2], fields[len(fields)-1]
+	}
+
+	onlineCount, err := strconv.Atoi(online)
+	if err != nil {
+		return nil, fmt.Errorf("invalid legacy online player count: %w", err)
+	}
+
+	maxCount, err := strconv.Atoi(max)
+	if err != nil {
+		return nil, fmt.Errorf("invalid legacy max player count: %w", err)
+	}
+
+	res.Players = Players{Online: FlexInt(onlineCount), Max: FlexInt(maxCount)}
+	res.Description = Description{Description: ChatComponent{Text: motd}, plain: true}
+
+	return res, nil
+}
@@ -0,0 +1,108 @@
+package slp
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/sch8ill/mclib/packet"
+)
+
+// legacyKickFixtures are legacy ping responses as received on the wire.
+var legacyKickFixtures = []struct {
+	name        string
+	raw         string
+	version     string
+	protocol    int
+	description string
+	online, max int
+}{
+	{
+		// the 1.4 example of https://wiki.vg/Server_List_Ping#1.4_to_1.5
+		name: "1.4",
+		raw: "ff 00 23 00 a7 00 31 00 00 00 34 00 37 00 00 00" +
+			"31 00 2e 00 34 00 2e 00 32 00 00 00 41 00 20 00" +
+			"4d 00 69 00 6e 00 65 00 63 00 72 00 61 00 66 00" +
+			"74 00 20 00 53 00 65 00 72 00 76 00 65 00 72 00" +
+			"00 00 30 00 00 00 32 00 30",
+		version:     "1.4.2",
+		protocol:    47,
+		description: "A Minecraft Server",
+		online:      0,
+		max:         20,
+	},
+	{
+		name: "1.6",
+		raw: "ff 00 27 00 a7 00 31 00 00 00 37 00 38 00 00 00" +
+			"31 00 2e 00 36 00 2e 00 34 00 00 00 41 00 20 00" +
+			"a7 00 36 00 4d 00 69 00 6e 00 65 00 63 00 72 00" +
+			"61 00 66 00 74 00 a7 00 72 00 20 00 53 00 65 00" +
+			"72 00 76 00 65 00 72 00 00 00 35 00 00 00 32 00" +
+			"30",
+		version:     "1.6.4",
+		protocol:    78,
+		description: "A §6Minecraft§r Server",
+		online:      5,
+		max:         20,
+	},
+	{
+		name: "beta",
+		raw: "ff 00 17 00 41 00 20 00 4d 00 69 00 6e 00 65 00" +
+			"63 00 72 00 61 00 66 00 74 00 20 00 53 00 65 00" +
+			"72 00 76 00 65 00 72 00 a7 00 30 00 a7 00 32 00" +
+			"30",
+		protocol:    -1,
+		description: "A Minecraft Server",
+		online:      0,
+		max:         20,
+	},
+}
+
+func TestParseLegacyFixtures(t *testing.T) {
+	for _, tt := range legacyKickFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := hex.DecodeString(strings.ReplaceAll(tt.raw, " ", ""))
+			if err != nil {
+				t.Fatalf("invalid fixture: %v", err)
+			}
+
+			payload, err := packet.ParseLegacyKick(raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			res, err := ParseLegacy(payload)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !res.Legacy {
+				t.Error("response is not marked as legacy")
+			}
+			if res.Version.Name != tt.version || int(res.Version.Protocol) != tt.protocol {
+				t.Errorf("version = %q (%d), want %q (%d)", res.Version.Name, res.Version.Protocol, tt.version, tt.protocol)
+			}
+			if res.Description.Description.Text != tt.description {
+				t.Errorf("description = %q, want %q", res.Description.Description.Text, tt.description)
+			}
+			if int(res.Players.Online) != tt.online || int(res.Players.Max) != tt.max {
+				t.Errorf("players = %d/%d, want %d/%d", res.Players.Online, res.Players.Max, tt.online, tt.max)
+			}
+		})
+	}
+}
+
+func TestParseLegacyInvalid(t *testing.T) {
+	for _, payload := range []string{
+		"",
+		"A Minecraft Server",
+		"A Minecraft Server§0",
+		"A Minecraft Server§zero§20",
+		"§1\x0047\x001.4.2\x00A Minecraft Server\x000",
+		"§1\x00forty\x001.4.2\x00A Minecraft Server\x000\x0020",
+	} {
+		if _, err := ParseLegacy(payload); err == nil {
+			t.Errorf("ParseLegacy(%q) did not fail", payload)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package slp
+
+// Metrics flattens the Response into labels and numeric values for time series databases like Prometheus.
+// The keys are part of the API and do not change between releases, since they become time series names.
+// Booleans are reported as 0 or 1. The favicon and the player sample are excluded.
+//
+// Labels: version_name, description_plain, chat_signature_policy
+//
+// Values: version_protocol, players_online, players_max, latency_ms, modded, forge_mods,
+// enforces_secure_chat, previews_chat, prevents_chat_reports
+func (r *Response) Metrics() (labels map[string]string, values map[string]float64) {
+	labels = map[string]string{
+		"version_name":          r.Version.Name,
+		"description_plain":     r.Description.CleanCompact(),
+		"chat_signature_policy": r.ChatSignaturePolicy().String(),
+	}
+
+	values = map[string]float64{
+		"version_protocol":      float64(r.Version.Protocol),
+		"players_online":        float64(r.Players.Online),
+		"players_max":           float64(r.Players.Max),
+		"latency_ms":            float64(r.Latency),
+		"modded":                boolMetric(r.IsModded()),
+		"forge_mods":            float64(len(r.Mods())),
+		"enforces_secure_chat":  boolMetric(r.EnforcesSecureChat),
+		"previews_chat":         boolMetric(r.PreviewsChat),
+		"prevents_chat_reports": boolMetric(r.PreventsChatReports),
+	}
+
+	return labels, values
+}
+
+// boolMetric converts a boolean into a metric value.
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package slp
+
+import (
+	"maps"
+	"os"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	raw, err := os.ReadFile("testdata/forge/1.20.1.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	res, err := ParseResponse(string(raw), ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Latency = 42
+	res.EnforcesSecureChat = true
+
+	labels, values := res.Metrics()
+
+	wantLabels := map[string]string{
+		"version_name":          "1.20.1",
+		"description_plain":     "A Minecraft Server",
+		"chat_signature_policy": "enforced",
+	}
+	if !maps.Equal(labels, wantLabels) {
+		t.Errorf("labels = %v, want %v", labels, wantLabels)
+	}
+
+	wantValues := map[string]float64{
+		"version_protocol":      763,
+		"players_online":        1,
+		"players_max":           20,
+		"latency_ms":            42,
+		"modded":                1,
+		"forge_mods":            4,
+		"enforces_secure_chat":  1,
+		"previews_chat":         0,
+		"prevents_chat_reports": 0,
+	}
+	if !maps.Equal(values, wantValues) {
+		t.Errorf("values = %v, want %v", values, wantValues)
+	}
+}
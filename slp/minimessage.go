@@ -0,0 +1,214 @@
+package slp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// miniMessageClickActions lists the click event actions with a MiniMessage representation.
+var miniMessageClickActions = map[string]bool{
+	"open_url":          true,
+	"open_file":         true,
+	"run_command":       true,
+	"suggest_command":   true,
+	"change_page":       true,
+	"copy_to_clipboard": true,
+}
+
+// MiniMessage converts the Description into Adventure's MiniMessage format (e.g. "<gold><bold>Hi</bold></gold>").
+func (d *Description) MiniMessage() string {
+	return d.Description.MiniMessage()
+}
+
+// MiniMessageWithWarnings converts the Description into the MiniMessage format
+// and returns a warning for every event that could not be represented.
+func (d *Description) MiniMessageWithWarnings() (string, []string) {
+	return d.Description.MiniMessageWithWarnings()
+}
+
+// MiniMessage converts the ChatComponent into Adventure's MiniMessage format (e.g. "<gold><bold>Hi</bold></gold>").
+// Events that cannot be represented are dropped.
+func (c *ChatComponent) MiniMessage() string {
+	mm, _ := c.MiniMessageWithWarnings()
+	return mm
+}
+
+// MiniMessageWithWarnings converts the ChatComponent into the MiniMessage format
+// and returns a warning for every event that could not be represented.
+// Nested components are wrapped in nested tags, which are closed in reverse order.
+// Legacy formatting codes inside the text are translated as well and newlines are written as <newline> tags.
+// https://docs.advntr.dev/minimessage/format.html
+func (c *ChatComponent) MiniMessageWithWarnings() (string, []string) {
+	w := new(miniMessageWriter)
+	w.component(c)
+
+	return w.b.String(), w.warnings
+}
+
+// miniMessageWriter writes a ChatComponent tree in the MiniMessage format.
+type miniMessageWriter struct {
+	b        strings.Builder
+	warnings []string
+}
+
+func (w *miniMessageWriter) warn(format string, args ...any) {
+	w.warnings = append(w.warnings, fmt.Sprintf(format, args...))
+}
+
+// component writes a ChatComponent and its extras wrapped in the tags of its own style and events.
+func (w *miniMessageWriter) component(c *ChatComponent) {
+	tags := style{}.inherit(c).miniMessageTags()
+	if c.Font != "" {
+		tags = append(tags, "font:"+miniMessageArg(c.Font))
+	}
+	if c.Insertion != "" {
+		tags = append(tags, "insert:"+miniMessageArg(c.Insertion))
+	}
+	if tag, ok := w.clickTag(c.ClickEvent); ok {
+		tags = append(tags, tag)
+	}
+	if tag, ok := w.hoverTag(c.HoverEvent); ok {
+		tags = append(tags, tag)
+	}
+
+	w.open(tags)
+
+	if c.Translate == "" {
+		w.text(c.Text)
+	} else {
+		w.translation(c)
+	}
+
+	for i := range c.Extra {
+		w.component(&c.Extra[i].Description)
+	}
+
+	w.close(tags)
+}
+
+// text writes a text, translating legacy formatting codes into tags.
+func (w *miniMessageWriter) text(text string) {
+	for _, seg := range parseLegacy(text, style{}) {
+		tags := seg.style.miniMessageTags()
+
+		w.open(tags)
+		w.b.WriteString(escapeMiniMessage(seg.text))
+		w.close(tags)
+	}
+}
+
+// translation writes the translation key of a ChatComponent as a lang tag.
+// The arguments of With are converted into MiniMessage themselves.
+func (w *miniMessageWriter) translation(c *ChatComponent) {
+	tag := "lang:" + miniMessageArg(c.Translate)
+	if c.Fallback != "" {
+		tag = "lang_or:" + miniMessageArg(c.Translate) + ":" + miniMessageArg(c.Fallback)
+	}
+
+	for i := range c.With {
+		arg, warnings := c.With[i].Description.MiniMessageWithWarnings()
+		w.warnings = append(w.warnings, warnings...)
+		tag += ":" + miniMessageArg(arg)
+	}
+
+	w.b.WriteString("<" + tag + ">")
+}
+
+// clickTag returns the click tag representing a ClickEvent.
+func (w *miniMessageWriter) clickTag(event *ClickEvent) (string, bool) {
+	if event == nil {
+		return "", false
+	}
+
+	if !miniMessageClickActions[event.Action] {
+		w.warn("click event action %q cannot be represented in MiniMessage", event.Action)
+		return "", false
+	}
+
+	return "click:" + event.Action + ":" + miniMessageArg(event.Value), true
+}
+
+// hoverTag returns the hover tag representing a HoverEvent.
+// Only show_text events can be represented.
+func (w *miniMessageWriter) hoverTag(event *HoverEvent) (string, bool) {
+	if event == nil {
+		return "", false
+	}
+
+	contents := event.Contents
+	if contents == nil {
+		contents = event.Value
+	}
+
+	if event.Action != "show_text" || contents == nil || contents.Component == nil {
+		w.warn("hover event action %q cannot be represented in MiniMessage", event.Action)
+		return "", false
+	}
+
+	text, warnings := contents.Component.MiniMessageWithWarnings()
+	w.warnings = append(w.warnings, warnings...)
+
+	return "hover:show_text:" + miniMessageArg(text), true
+}
+
+// open writes the opening tags in the given order.
+func (w *miniMessageWriter) open(tags []string) {
+	for _, tag := range tags {
+		w.b.WriteString("<" + tag + ">")
+	}
+}
+
+// close writes the closing tags in reverse order.
+func (w *miniMessageWriter) close(tags []string) {
+	for i := len(tags) - 1; i >= 0; i-- {
+		name, _, _ := strings.Cut(tags[i], ":")
+		w.b.WriteString("</" + name + ">")
+	}
+}
+
+// miniMessageTags returns the tags representing the style.
+// Colors are written by name if possible and as hex colors otherwise. Invalid colors are dropped.
+func (s style) miniMessageTags() []string {
+	var tags []string
+
+	if c, ok := parseColor(s.color); ok {
+		name := strings.ToLower(s.color)
+		if _, named := namedColors[name]; named {
+			tags = append(tags, name)
+		} else {
+			tags = append(tags, fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b))
+		}
+	}
+
+	if s.bold {
+		tags = append(tags, "bold")
+	}
+	if s.italic {
+		tags = append(tags, "italic")
+	}
+	if s.underlined {
+		tags = append(tags, "underlined")
+	}
+	if s.strikethrough {
+		tags = append(tags, "strikethrough")
+	}
+	if s.obfuscated {
+		tags = append(tags, "obfuscated")
+	}
+
+	return tags
+}
+
+// escapeMiniMessage escapes a text so it is not interpreted as tags and replaces newlines by <newline> tags.
+func escapeMiniMessage(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, "<", `\<`)
+
+	return strings.ReplaceAll(text, "\n", "<newline>")
+}
+
+// miniMessageArg quotes a tag argument.
+func miniMessageArg(arg string) string {
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	return "'" + strings.ReplaceAll(arg, "'", `\'`) + "'"
+}
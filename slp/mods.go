@@ -0,0 +1,189 @@
+package slp
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ForgeAnyVersion is the mod marker of mods accepting any client version.
+const ForgeAnyVersion = "ANY"
+
+// forgeIgnoreServerOnlyName is the name of the server only marker, sent literally by some Forge versions.
+const forgeIgnoreServerOnlyName = "IGNORESERVERONLY"
+
+// checksumMarker matches mod markers containing a checksum instead of a version.
+var checksumMarker = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+
+// Mod represents a mod reported by a server, independent of the Forge version.
+type Mod struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// IsModded checks whether the server reports Forge mod data.
+func (r *Response) IsModded() bool {
+	return r.ForgeData != nil || r.ForgeModInfo != nil
+}
+
+// Mods returns the mods reported by the server from either the modern ForgeData or the legacy ForgeModInfo.
+// Mods present in both structures or listed multiple times are only returned once.
+func (r *Response) Mods() []Mod {
+	var mods []Mod
+	seen := make(map[string]bool)
+
+	add := func(id, version string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		mods = append(mods, Mod{ID: id, Version: version})
+	}
+
+	if r.ForgeData != nil {
+		for _, mod := range r.ForgeData.Mods {
+			add(mod.ModID, mod.ModMarker)
+		}
+	}
+
+	if r.ForgeModInfo != nil {
+		for _, mod := range r.ForgeModInfo.ModList {
+			add(mod.ModID, mod.Version)
+		}
+	}
+
+	return mods
+}
+
+// HasMod checks whether the server reports a mod with the given id.
+func (r *Response) HasMod(id string) bool {
+	_, ok := r.mod(id)
+	return ok
+}
+
+// ModVersion returns the version of the mod with the given id or an empty string if the mod is not present.
+func (r *Response) ModVersion(id string) string {
+	mod, _ := r.mod(id)
+	return mod.Version
+}
+
+// HasModVersion checks whether the server reports a mod with the given id at the given version or newer.
+// It returns false if the version of the mod cannot be compared (see CompareModVersions).
+func (r *Response) HasModVersion(id, min string) bool {
+	mod, ok := r.mod(id)
+	if !ok {
+		return false
+	}
+
+	cmp, ok := CompareModVersions(mod.Version, min)
+	return ok && cmp >= 0
+}
+
+// IsServerOnly checks whether the mod is only required on the server side.
+func (m *Mod) IsServerOnly() bool {
+	return isServerOnlyMarker(m.Version)
+}
+
+// CompareModVersions compares two mod versions like "1.20.1-47.2.0" or "0.5.1+mc1.20.1".
+// Versions are split into their components at dots, dashes and underscores and compared component-wise.
+// Numeric components are compared numerically and rank above textual components like "beta".
+// Trailing zeros are ignored and a trailing textual component marks a pre-release.
+// Build metadata following a plus sign is ignored.
+// It returns -1 if a is older than b, 1 if a is newer than b and 0 if they are equal.
+// It returns ok=false if one of the versions is a marker instead of a version (ANY, server only or checksum).
+func CompareModVersions(a, b string) (cmp int, ok bool) {
+	if !isComparableModVersion(a) || !isComparableModVersion(b) {
+		return 0, false
+	}
+
+	aParts := modVersionParts(a)
+	bParts := modVersionParts(b)
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if cmp := compareModVersionPart(aParts[i], bParts[i]); cmp != 0 {
+			return cmp, true
+		}
+	}
+
+	switch {
+	case len(aParts) > len(bParts):
+		return compareModVersionTail(aParts[len(bParts):]), true
+	case len(aParts) < len(bParts):
+		return -compareModVersionTail(bParts[len(aParts):]), true
+	}
+
+	return 0, true
+}
+
+// compareModVersionTail compares the remaining components of the longer of two mod versions
+// with the end of the shorter one. Trailing zeros are ignored and a textual component marks a pre-release
+// (e.g. "1.0-beta" is older than "1.0").
+func compareModVersionTail(tail []string) int {
+	for _, part := range tail {
+		num, err := strconv.Atoi(part)
+		if err != nil {
+			return -1
+		}
+		if num != 0 {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// isComparableModVersion checks whether a mod marker contains a version.
+func isComparableModVersion(version string) bool {
+	return version != "" &&
+		!strings.EqualFold(version, ForgeAnyVersion) &&
+		!isServerOnlyMarker(version) &&
+		!checksumMarker.MatchString(version)
+}
+
+// isServerOnlyMarker checks whether a mod marker marks a mod as only required on the server side.
+func isServerOnlyMarker(marker string) bool {
+	return marker == ForgeIgnoreServerOnly || marker == forgeIgnoreServerOnlyName
+}
+
+// modVersionParts splits a mod version into its components.
+func modVersionParts(version string) []string {
+	version, _, _ = strings.Cut(version, "+")
+
+	return strings.FieldsFunc(strings.ToLower(version), func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	})
+}
+
+// compareModVersionPart compares two components of a mod version.
+func compareModVersionPart(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNum < bNum {
+			return -1
+		}
+		if aNum > bNum {
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return 1
+	case bErr == nil:
+		return -1
+	}
+
+	return strings.Compare(a, b)
+}
+
+// mod looks up the mod with the given id.
+func (r *Response) mod(id string) (Mod, bool) {
+	for _, mod := range r.Mods() {
+		if mod.ID == id {
+			return mod, true
+		}
+	}
+
+	return Mod{}, false
+}
@@ -0,0 +1,151 @@
+package slp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/sch8ill/mclib/packet"
+)
+
+// MarshalNBT encodes the ChatComponent in the network NBT format used for chat components since 1.20.3.
+// Components containing nothing but text are encoded as a string tag, all others as a compound tag.
+// Booleans are encoded as byte tags and extra and with as lists.
+// https://wiki.vg/NBT#Network_NBT_(Java_Edition)
+func (c *ChatComponent) MarshalNBT() ([]byte, error) {
+	if c.isText() {
+		return packet.AppendNBT(nil, c.Text)
+	}
+
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	tag, err := jsonToNBT(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return packet.AppendNBT(nil, tag)
+}
+
+// UnmarshalNBT decodes a ChatComponent from the network NBT format used for chat components since 1.20.3.
+func (c *ChatComponent) UnmarshalNBT(b []byte) error {
+	tag, err := packet.DecodeNBT(b)
+	if err != nil {
+		return err
+	}
+
+	component, err := ChatComponentFromNBT(tag)
+	if err != nil {
+		return err
+	}
+	*c = component
+
+	return nil
+}
+
+// ChatComponentFromNBT converts a decoded NBT tag (see packet.InboundPacket.ReadNBT) into a ChatComponent.
+// Byte tags are converted into booleans and compounds wrapping a single value under an empty key are unwrapped.
+func ChatComponentFromNBT(tag any) (ChatComponent, error) {
+	raw, err := json.Marshal(nbtToJSON(tag))
+	if err != nil {
+		return ChatComponent{}, fmt.Errorf("failed to convert nbt to json: %w", err)
+	}
+
+	var desc Description
+	if err := desc.unmarshal(raw, 0); err != nil {
+		return ChatComponent{}, err
+	}
+
+	return desc.Description, nil
+}
+
+// jsonToNBT converts a JSON value decoded with json.Decoder.UseNumber into an NBT value.
+// Null values in objects are omitted like absent fields.
+func jsonToNBT(value any) (any, error) {
+	switch v := value.(type) {
+	case string, bool:
+		return v, nil
+
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			if n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int32(n), nil
+			}
+			return n, nil
+		}
+
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", v)
+		}
+		return f, nil
+
+	case []any:
+		list := make([]any, len(v))
+		for i, elem := range v {
+			var err error
+			if list[i], err = jsonToNBT(elem); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+
+	case map[string]any:
+		compound := make(map[string]any, len(v))
+		for key, elem := range v {
+			if elem == nil {
+				continue
+			}
+
+			var err error
+			if compound[key], err = jsonToNBT(elem); err != nil {
+				return nil, err
+			}
+		}
+		return compound, nil
+	}
+
+	return nil, fmt.Errorf("cannot encode %T as nbt", value)
+}
+
+// nbtToJSON converts a decoded NBT value into a value that marshals into the JSON form of a chat component.
+func nbtToJSON(tag any) any {
+	switch v := tag.(type) {
+	case int8:
+		// chat components only use byte tags for booleans
+		return v != 0
+
+	case []any:
+		list := make([]any, len(v))
+		for i, elem := range v {
+			list[i] = nbtToJSON(elem)
+		}
+		return list
+
+	case map[string]any:
+		// compounds wrapping an element of a mixed list
+		if elem, ok := v[""]; ok && len(v) == 1 {
+			return nbtToJSON(elem)
+		}
+
+		compound := make(map[string]any, len(v))
+		for key, elem := range v {
+			compound[key] = nbtToJSON(elem)
+		}
+		return compound
+	}
+
+	return tag
+}
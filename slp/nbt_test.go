@@ -0,0 +1,204 @@
+package slp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// nbtVectors are chat components and their network NBT encodings.
+// Vanilla writes compound keys in the order of its codecs, the encoder sorts them.
+var nbtVectors = []struct {
+	name string
+	c    ChatComponent
+	nbt  string
+}{
+	{
+		name: "text",
+		c:    ChatComponent{Text: "Hello"},
+		nbt:  "08 0005 48656c6c6f",
+	},
+	{
+		name: "modified utf-8",
+		c:    ChatComponent{Text: "§6Grüße 😀"},
+		nbt:  "08 0011 c2a7 36 47 72 c3bc c39f 65 20 eda0bd edb880",
+	},
+	{
+		name: "nul character",
+		c:    ChatComponent{Text: "a\x00b"},
+		nbt:  "08 0004 61 c080 62",
+	},
+	{
+		name: "styled",
+		c:    ChatComponent{Text: "Hello", Color: "gold", Bold: true},
+		nbt: "0a" +
+			"01 0004 626f6c64 01" +
+			"08 0005 636f6c6f72 0004 676f6c64" +
+			"08 0004 74657874 0005 48656c6c6f" +
+			"00",
+	},
+	{
+		name: "extra",
+		c: ChatComponent{Extra: []Description{
+			{Description: ChatComponent{Text: "a"}},
+			{Description: ChatComponent{Text: "b", Italic: true}},
+		}},
+		nbt: "0a" +
+			"09 0005 6578747261 0a 00000002" +
+			"08 0004 74657874 0001 61 00" +
+			"01 0006 6974616c6963 01 08 0004 74657874 0001 62 00" +
+			"08 0004 74657874 0000" +
+			"00",
+	},
+}
+
+func decodeNBTHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatalf("invalid hex: %v", err)
+	}
+	return b
+}
+
+func TestMarshalNBT(t *testing.T) {
+	for _, tt := range nbtVectors {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.c.MarshalNBT()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want := decodeNBTHex(t, tt.nbt); !bytes.Equal(got, want) {
+				t.Errorf("MarshalNBT() = % x, want % x", got, want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalNBT(t *testing.T) {
+	for _, tt := range nbtVectors {
+		t.Run(tt.name, func(t *testing.T) {
+			var c ChatComponent
+			if err := c.UnmarshalNBT(decodeNBTHex(t, tt.nbt)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assertSameComponent(t, c, tt.c)
+		})
+	}
+}
+
+func TestUnmarshalNBTVanillaOrder(t *testing.T) {
+	// {text:"Hello",color:"gold",bold:1b} as written by vanilla
+	raw := decodeNBTHex(t, "0a"+
+		"08 0004 74657874 0005 48656c6c6f"+
+		"08 0005 636f6c6f72 0004 676f6c64"+
+		"01 0004 626f6c64 01"+
+		"00")
+
+	var c ChatComponent
+	if err := c.UnmarshalNBT(raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertSameComponent(t, c, ChatComponent{Text: "Hello", Color: "gold", Bold: true})
+}
+
+func TestUnmarshalNBTMixedList(t *testing.T) {
+	// {text:"",extra:["a",{text:"b",italic:1b}]} as written by vanilla,
+	// which wraps the string in a compound under an empty key, since lists cannot mix tag types
+	raw := decodeNBTHex(t, "0a"+
+		"08 0004 74657874 0000"+
+		"09 0005 6578747261 0a 00000002"+
+		"08 0000 0001 61 00"+
+		"08 0004 74657874 0001 62 01 0006 6974616c6963 01 00"+
+		"00")
+
+	var c ChatComponent
+	if err := c.UnmarshalNBT(raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(c.Extra) != 2 || c.Extra[0].Description.Text != "a" || !c.Extra[1].Description.Italic {
+		t.Errorf("extra = %+v, want a and italic b", c.Extra)
+	}
+	if got := c.Clean(); got != "ab" {
+		t.Errorf("Clean() = %q, want %q", got, "ab")
+	}
+}
+
+func TestUnmarshalNBTInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		nbt  string
+	}{
+		{"empty", ""},
+		{"truncated string", "08 0005 4865"},
+		{"trailing bytes", "08 0001 61 00"},
+		{"unknown tag", "0d"},
+		{"number", "03 0000002a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c ChatComponent
+			if err := c.UnmarshalNBT(decodeNBTHex(t, tt.nbt)); err == nil {
+				t.Errorf("decoded %+v from invalid nbt", c)
+			}
+		})
+	}
+}
+
+func TestNBTRoundTrip(t *testing.T) {
+	components := []ChatComponent{
+		{Text: "plain"},
+		{Text: "Welcome ", Color: "#ff8800", Underlined: true, Strikethrough: true, Obfuscated: true},
+		{
+			Translate: "multiplayer.disconnect.banned.reason",
+			With:      []Description{{Description: ChatComponent{Text: "Griefing", Color: "red"}}},
+		},
+		{
+			Text: "A ",
+			Extra: []Description{
+				{Description: ChatComponent{Text: "nested ", Extra: []Description{{Description: ChatComponent{Text: "deeply", Bold: true}}}}},
+				{Description: ChatComponent{Text: "\n§cline two"}},
+			},
+		},
+	}
+
+	for _, want := range components {
+		raw, err := want.MarshalNBT()
+		if err != nil {
+			t.Fatalf("%+v: unexpected error: %v", want, err)
+		}
+
+		var got ChatComponent
+		if err := got.UnmarshalNBT(raw); err != nil {
+			t.Fatalf("%+v: unexpected error: %v", want, err)
+		}
+
+		assertSameComponent(t, got, want)
+	}
+}
+
+// assertSameComponent compares two chat components by their JSON form.
+func assertSameComponent(t *testing.T, got, want ChatComponent) {
+	t.Helper()
+
+	gotJSON, err := json.Marshal(&got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantJSON, err := json.Marshal(&want)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("component = %s, want %s", gotJSON, wantJSON)
+	}
+}
@@ -0,0 +1,480 @@
+package slp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseOptions configures the parsing of status responses.
+type ParseOptions struct {
+	// Lenient coerces mismatching types where possible instead of failing
+	// and records every anomaly in Response.Warnings.
+	Lenient bool
+
+	// Strict rejects responses containing fields unknown to the Response at any depth
+	// or lacking a field vanilla servers always send.
+	Strict bool
+
+	// WithoutRaw skips keeping a copy of the raw response in Response.Raw.
+	WithoutRaw bool
+}
+
+// ErrInvalidResponse is returned when a status response cannot be parsed.
+// Raw contains the response as it was received, unless ParseOptions.WithoutRaw is set.
+type ErrInvalidResponse struct {
+	Raw []byte
+	Err error
+}
+
+func (e *ErrInvalidResponse) Error() string {
+	return fmt.Sprintf("invalid status response: %v", e.Err)
+}
+
+func (e *ErrInvalidResponse) Unwrap() error {
+	return e.Err
+}
+
+// ParseResponse parses a raw SLP response string into a Response struct using the given ParseOptions.
+// If the response cannot be parsed, an ErrInvalidResponse carrying the raw response is returned.
+func ParseResponse[T []byte | string](rawRes T, opts ParseOptions) (*Response, error) {
+	var raw []byte
+	if !opts.WithoutRaw {
+		raw = bytes.Clone([]byte(rawRes))
+	}
+
+	return parse([]byte(rawRes), raw, opts)
+}
+
+// parse parses a raw SLP response and keeps raw, which must not be shared with the caller, in Response.Raw.
+func parse(rawRes, raw []byte, opts ParseOptions) (*Response, error) {
+	payload, warnings, err := trimPayload(rawRes)
+	if err != nil {
+		return nil, &ErrInvalidResponse{Raw: raw, Err: err}
+	}
+
+	if opts.Lenient {
+		var normalizeWarnings []string
+		payload, normalizeWarnings, err = normalize(payload)
+		if err != nil {
+			return nil, &ErrInvalidResponse{Raw: raw, Err: err}
+		}
+		warnings = append(warnings, normalizeWarnings...)
+	}
+
+	res := new(Response)
+	if err := json.Unmarshal(payload, res); err != nil {
+		return nil, &ErrInvalidResponse{Raw: raw, Err: err}
+	}
+	res.Warnings = append(warnings, res.Warnings...)
+	res.Raw = raw
+
+	if opts.Strict {
+		if err := checkStrict(payload); err != nil {
+			return nil, &ErrInvalidResponse{Raw: raw, Err: err}
+		}
+	}
+
+	return res, nil
+}
+
+// Decode reads a raw SLP response from a reader and parses it into a Response struct.
+func Decode(r io.Reader) (*Response, error) {
+	return DecodeWithOptions(r, ParseOptions{})
+}
+
+// DecodeWithOptions reads a raw SLP response from a reader and parses it using the given ParseOptions.
+// The response is decoded while it is read, only a copy for Response.Raw is kept unless WithoutRaw is set.
+// Lenient and strict parsing need the whole response and read it into memory first.
+// The reader is always read to its end.
+func DecodeWithOptions(r io.Reader, opts ParseOptions) (*Response, error) {
+	if opts.Lenient || opts.Strict {
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		kept := raw
+		if opts.WithoutRaw {
+			kept = nil
+		}
+		return parse(raw, kept, opts)
+	}
+
+	var rawBuf *bytes.Buffer
+	if !opts.WithoutRaw {
+		rawBuf = new(bytes.Buffer)
+		r = io.TeeReader(r, rawBuf)
+	}
+
+	res, warnings, err := decodeStream(r)
+	var raw []byte
+	if rawBuf != nil {
+		raw = rawBuf.Bytes()
+	}
+
+	if err != nil {
+		return nil, &ErrInvalidResponse{Raw: raw, Err: err}
+	}
+	res.Warnings = append(warnings, res.Warnings...)
+	res.Raw = raw
+
+	return res, nil
+}
+
+// decodeStream decodes the first JSON value of a status response from a reader like trimPayload
+// and returns warnings for a leading byte order mark and trailing bytes. The reader is read to its end.
+func decodeStream(r io.Reader) (*Response, []string, error) {
+	var warnings []string
+
+	reader := bufio.NewReader(r)
+	// drain the reader in any case, so the raw response is complete
+	defer io.Copy(io.Discard, reader)
+
+	if err := skipSpace(reader); err != nil {
+		return nil, nil, err
+	}
+	if prefix, _ := reader.Peek(len(utf8BOM)); bytes.Equal(prefix, utf8BOM) {
+		warnings = append(warnings, "response starts with a byte order mark")
+		_, _ = reader.Discard(len(utf8BOM))
+	}
+
+	decoder := json.NewDecoder(reader)
+	res := new(Response)
+	if err := decoder.Decode(res); err != nil {
+		return nil, nil, err
+	}
+
+	trailing, err := countTrailing(io.MultiReader(decoder.Buffered(), reader))
+	if err != nil {
+		return nil, nil, err
+	}
+	if trailing > 0 {
+		warnings = append(warnings, fmt.Sprintf("response contains %d trailing bytes", trailing))
+	}
+
+	return res, warnings, nil
+}
+
+// skipSpace discards leading whitespace. An empty reader is not an error, the decoder reports it.
+func skipSpace(r *bufio.Reader) error {
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !isSpace(b) {
+			return r.UnreadByte()
+		}
+	}
+}
+
+// countTrailing counts the bytes following the JSON value up to the last byte that is not whitespace,
+// matching the trailing bytes cut off by trimPayload.
+func countTrailing(r io.Reader) (int64, error) {
+	var count, pending int64
+	buf := make([]byte, 512)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			pending++
+			if !isSpace(b) {
+				count += pending
+				pending = 0
+			}
+		}
+
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// isSpace reports whether b is whitespace as trimmed by bytes.TrimSpace for ASCII input.
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f'
+}
+
+// utf8BOM is the byte order mark some servers prefix the status JSON with.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// trimPayload strips a leading byte order mark and surrounding whitespace from a raw status response
+// and cuts off any bytes trailing the first JSON value.
+// It returns the trimmed response and a warning for every anomaly.
+func trimPayload(raw []byte) ([]byte, []string, error) {
+	var warnings []string
+
+	raw = bytes.TrimSpace(raw)
+	if bytes.HasPrefix(raw, utf8BOM) {
+		warnings = append(warnings, "response starts with a byte order mark")
+		raw = bytes.TrimSpace(raw[len(utf8BOM):])
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	var value json.RawMessage
+	if err := decoder.Decode(&value); err != nil {
+		return nil, nil, err
+	}
+
+	if end := decoder.InputOffset(); end < int64(len(raw)) {
+		warnings = append(warnings, fmt.Sprintf("response contains %d trailing bytes", int64(len(raw))-end))
+		raw = raw[:end]
+	}
+
+	return raw, warnings, nil
+}
+
+// normalize coerces the fields of a malformed status response into their expected types.
+// It returns the normalized response and a warning for every anomaly.
+func normalize(raw []byte) ([]byte, []string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var res map[string]any
+	if err := decoder.Decode(&res); err != nil {
+		return nil, nil, err
+	}
+
+	if res == nil {
+		return nil, nil, errors.New("response is not a JSON object")
+	}
+
+	n := new(normalizer)
+	n.normalizeResponse(res)
+
+	normalized, err := json.Marshal(res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode normalized response: %w", err)
+	}
+
+	return normalized, n.warnings, nil
+}
+
+// normalizer coerces malformed fields and collects warnings about them.
+type normalizer struct {
+	warnings []string
+}
+
+func (n *normalizer) warn(format string, args ...any) {
+	n.warnings = append(n.warnings, fmt.Sprintf(format, args...))
+}
+
+func (n *normalizer) normalizeResponse(res map[string]any) {
+	if version := n.object(res, "version", "version"); version != nil {
+		n.string(version, "name", "version.name")
+		n.int(version, "protocol", "version.protocol", false)
+	}
+
+	if players := n.object(res, "players", "players"); players != nil {
+		if _, ok := players["max"]; !ok {
+			n.warn("players.max is missing")
+		}
+		n.int(players, "max", "players.max", true)
+		n.int(players, "online", "players.online", true)
+		n.sample(players)
+	}
+
+	n.description(res)
+	n.string(res, "favicon", "favicon")
+	n.bool(res, "enforcesSecureChat", "enforcesSecureChat")
+	n.bool(res, "previewsChat", "previewsChat")
+	n.object(res, "modinfo", "modinfo")
+	n.object(res, "forgeData", "forgeData")
+}
+
+// object returns the object stored under key. Values that are not objects are removed.
+func (n *normalizer) object(obj map[string]any, key, path string) map[string]any {
+	v, ok := obj[key]
+	if !ok {
+		return nil
+	}
+
+	value, ok := v.(map[string]any)
+	if !ok {
+		n.warn("%s is not an object: %s", path, describe(v))
+		delete(obj, key)
+		return nil
+	}
+
+	return value
+}
+
+// string coerces the value stored under key into a string.
+func (n *normalizer) string(obj map[string]any, key, path string) {
+	v, ok := obj[key]
+	if !ok {
+		return
+	}
+
+	switch value := v.(type) {
+	case string:
+	case json.Number:
+		n.warn("%s is a number: %s", path, value)
+		obj[key] = value.String()
+	case bool:
+		n.warn("%s is a bool: %t", path, value)
+		obj[key] = strconv.FormatBool(value)
+	default:
+		n.warn("%s is not a string: %s", path, describe(v))
+		delete(obj, key)
+	}
+}
+
+// int coerces the value stored under key into an integer.
+// If nonNegative is set, negative values are replaced by zero.
+func (n *normalizer) int(obj map[string]any, key, path string, nonNegative bool) {
+	v, ok := obj[key]
+	if !ok {
+		return
+	}
+
+	var f float64
+	switch value := v.(type) {
+	case json.Number:
+		var err error
+		if f, err = value.Float64(); err != nil {
+			n.warn("%s is not a valid number: %s", path, value)
+			delete(obj, key)
+			return
+		}
+		if f != math.Trunc(f) {
+			n.warn("%s is not an integer: %s", path, value)
+		}
+	case string:
+		var err error
+		if f, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+			n.warn("%s is not a number: %q", path, value)
+			delete(obj, key)
+			return
+		}
+		n.warn("%s is a string: %q", path, value)
+	default:
+		n.warn("%s is not a number: %s", path, describe(v))
+		delete(obj, key)
+		return
+	}
+
+	if nonNegative && f < 0 {
+		n.warn("%s is negative: %v", path, f)
+		f = 0
+	}
+
+	obj[key] = int64(math.Max(math.Min(math.Trunc(f), math.MaxInt32), math.MinInt32))
+}
+
+// bool coerces the value stored under key into a boolean.
+func (n *normalizer) bool(obj map[string]any, key, path string) {
+	v, ok := obj[key]
+	if !ok {
+		return
+	}
+
+	switch value := v.(type) {
+	case bool:
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			n.warn("%s is not a bool: %q", path, value)
+			delete(obj, key)
+			return
+		}
+		n.warn("%s is a string: %q", path, value)
+		obj[key] = b
+	case json.Number:
+		n.warn("%s is a number: %s", path, value)
+		obj[key] = value.String() != "0"
+	default:
+		n.warn("%s is not a bool: %s", path, describe(v))
+		delete(obj, key)
+	}
+}
+
+// sample removes malformed entries from the player sample.
+// Bare names are turned into players with an empty ID.
+func (n *normalizer) sample(players map[string]any) {
+	v, ok := players["sample"]
+	if !ok {
+		return
+	}
+
+	entries, ok := v.([]any)
+	if !ok {
+		n.warn("players.sample is not an array: %s", describe(v))
+		delete(players, "sample")
+		return
+	}
+
+	sample := make([]any, 0, len(entries))
+	for i, entry := range entries {
+		if name, ok := entry.(string); ok {
+			n.warn("players.sample[%d] is a name: %q", i, name)
+			sample = append(sample, map[string]any{"name": name, "id": ""})
+			continue
+		}
+
+		player, ok := entry.(map[string]any)
+		if !ok {
+			n.warn("players.sample[%d] is not an object: %s", i, describe(entry))
+			continue
+		}
+
+		path := fmt.Sprintf("players.sample[%d]", i)
+		n.string(player, "name", path+".name")
+		sample = append(sample, player)
+	}
+	players["sample"] = sample
+}
+
+// description coerces the description into a string, an object or an array of objects.
+func (n *normalizer) description(res map[string]any) {
+	v, ok := res["description"]
+	if !ok {
+		return
+	}
+
+	switch value := v.(type) {
+	case string, map[string]any, []any:
+	case json.Number:
+		n.warn("description is a number: %s", value)
+		res["description"] = value.String()
+	case bool:
+		n.warn("description is a bool: %t", value)
+		res["description"] = strconv.FormatBool(value)
+	default:
+		n.warn("description is invalid: %s", describe(v))
+		delete(res, "description")
+	}
+}
+
+// describe returns a short description of an unexpected JSON value for warnings.
+func describe(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(value)
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,287 @@
+package slp
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const minimalResponse = `{"version":{"name":"1.20.4","protocol":765},"players":{"max":20,"online":1},"description":"A Minecraft Server"}`
+
+func TestDecodeKeepsRaw(t *testing.T) {
+	raw := "\xEF\xBB\xBF " + minimalResponse + " \n"
+
+	res, err := Decode(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(res.Raw) != raw {
+		t.Errorf("raw = %q, want %q", res.Raw, raw)
+	}
+	if res.Version.Protocol != 765 || res.Description.String() != "A Minecraft Server" {
+		t.Errorf("unexpected response: %+v", res)
+	}
+	if !slices.Equal(res.Warnings, []string{"response starts with a byte order mark"}) {
+		t.Errorf("warnings = %q", res.Warnings)
+	}
+}
+
+func TestDecodeWithoutRaw(t *testing.T) {
+	res, err := DecodeWithOptions(strings.NewReader(minimalResponse), ParseOptions{WithoutRaw: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.Raw != nil {
+		t.Errorf("raw = %q, want nil", res.Raw)
+	}
+}
+
+func TestDecodeMatchesParseResponse(t *testing.T) {
+	tests := []string{
+		minimalResponse,
+		minimalResponse + "garbage",
+		"  " + minimalResponse + " x \n",
+		"\xEF\xBB\xBF" + minimalResponse,
+	}
+
+	for _, raw := range tests {
+		parsed, err := ParseResponse(raw, ParseOptions{})
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", raw, err)
+		}
+
+		decoded, err := Decode(strings.NewReader(raw))
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", raw, err)
+		}
+
+		if !slices.Equal(parsed.Warnings, decoded.Warnings) {
+			t.Errorf("%q: warnings = %q, want %q", raw, decoded.Warnings, parsed.Warnings)
+		}
+		if !bytes.Equal(parsed.Raw, decoded.Raw) {
+			t.Errorf("%q: raw = %q, want %q", raw, decoded.Raw, parsed.Raw)
+		}
+	}
+}
+
+func TestDecodeInvalidKeepsRaw(t *testing.T) {
+	raw := `{"version":{"name":1.5`
+
+	for _, opts := range []ParseOptions{{}, {Lenient: true}, {Strict: true}} {
+		_, err := DecodeWithOptions(strings.NewReader(raw), opts)
+
+		var invalid *ErrInvalidResponse
+		if !errors.As(err, &invalid) {
+			t.Fatalf("%+v: error = %v, want *ErrInvalidResponse", opts, err)
+		}
+		if string(invalid.Raw) != raw {
+			t.Errorf("%+v: raw = %q, want %q", opts, invalid.Raw, raw)
+		}
+	}
+
+	_, err := ParseResponse(raw, ParseOptions{})
+	var invalid *ErrInvalidResponse
+	if !errors.As(err, &invalid) || string(invalid.Raw) != raw {
+		t.Errorf("ParseResponse error = %v, want *ErrInvalidResponse with raw", err)
+	}
+}
+
+func TestTrimFixtures(t *testing.T) {
+	const bom = "response starts with a byte order mark"
+
+	tests := []struct {
+		file     string
+		warnings []string
+	}{
+		{"bom.json", []string{bom}},
+		{"bom_whitespace.json", []string{bom}},
+		{"trailing_brace.json", []string{"response contains 1 trailing bytes"}},
+		{"trailing_object.json", []string{"response contains 10 trailing bytes"}},
+		{"trailing_nul.json", []string{"response contains 1 trailing bytes"}},
+		{"trailing_nul_padding.json", []string{"response contains 4 trailing bytes"}},
+		{"bom_trailing_nul.json", []string{bom, "response contains 1 trailing bytes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile("testdata/trim/" + tt.file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			parsed, err := ParseResponse(raw, ParseOptions{})
+			if err != nil {
+				t.Fatalf("ParseResponse: unexpected error: %v", err)
+			}
+			decoded, err := Decode(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("Decode: unexpected error: %v", err)
+			}
+
+			for _, res := range []*Response{parsed, decoded} {
+				if res.Version.Protocol != 765 || res.Description.String() != "A Minecraft Server" {
+					t.Errorf("unexpected response: %+v", res)
+				}
+				if !slices.Equal(res.Warnings, tt.warnings) {
+					t.Errorf("warnings = %q, want %q", res.Warnings, tt.warnings)
+				}
+				if !bytes.Equal(res.Raw, raw) {
+					t.Errorf("raw = %q, want %q", res.Raw, raw)
+				}
+			}
+		})
+	}
+}
+
+func TestLenientFixtures(t *testing.T) {
+	tests := []struct {
+		file string
+		// defaultErr is set if the payload fails to parse without Lenient
+		defaultErr bool
+
+		version     string
+		protocol    int
+		online, max int
+		description string
+		warnings    []string
+	}{
+		{
+			file:        "players_strings.json",
+			version:     "Spigot 1.8.8",
+			protocol:    47,
+			online:      12,
+			max:         100,
+			description: "§aA Spigot server",
+			warnings:    []string{`players.max is a string: "100"`, `players.online is a string: "12"`},
+		},
+		{
+			file:        "players_negative.json",
+			version:     "BungeeCord 1.8.x-1.20.x",
+			protocol:    -1,
+			description: "§cMaintenance",
+			warnings:    []string{"players.max is missing", "players.online is negative: -1"},
+		},
+		{
+			file:        "numbers.json",
+			defaultErr:  true,
+			version:     "1.16",
+			protocol:    754,
+			online:      3,
+			max:         50,
+			description: "1234",
+			warnings: []string{
+				"version.name is a number: 1.16",
+				`version.protocol is a string: "754"`,
+				"description is a number: 1234",
+			},
+		},
+		{
+			file:       "sample_names.json",
+			defaultErr: true,
+			version:    "Paper 1.20.4",
+			protocol:   765,
+			online:     2,
+			max:        20,
+			warnings: []string{
+				`players.sample[0] is a name: "Notch"`,
+				"players.sample[1] is not an object: null",
+				`enforcesSecureChat is a string: "true"`,
+				"previewsChat is a number: 0",
+			},
+		},
+		{
+			file:        "wrong_types.json",
+			defaultErr:  true,
+			version:     "Velocity 3.3.0",
+			protocol:    765,
+			description: "false",
+			warnings: []string{
+				"players is not an object: array",
+				"description is a bool: false",
+				"favicon is not a string: null",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile("testdata/lenient/" + tt.file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			if _, err := ParseResponse(raw, ParseOptions{}); (err != nil) != tt.defaultErr {
+				t.Errorf("default parsing error = %v, want an error: %t", err, tt.defaultErr)
+			}
+
+			res, err := ParseResponse(raw, ParseOptions{Lenient: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.Version.Name != tt.version || int(res.Version.Protocol) != tt.protocol {
+				t.Errorf("version = %+v, want %q (%d)", res.Version, tt.version, tt.protocol)
+			}
+			if int(res.Players.Online) != tt.online || int(res.Players.Max) != tt.max {
+				t.Errorf("players = %d/%d, want %d/%d", res.Players.Online, res.Players.Max, tt.online, tt.max)
+			}
+			if got := res.Description.String(); got != tt.description {
+				t.Errorf("description = %q, want %q", got, tt.description)
+			}
+			if !slices.Equal(res.Warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", res.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+// faviconResponse returns a status response with a favicon of about 40 KB, like many modded servers send.
+func faviconResponse() []byte {
+	favicon := "data:image/png;base64," + strings.Repeat("iVBORw0KGgoAAAANSUhEUgAAAEAAAABACAYAAACqaXHe", 1000)
+	return []byte(`{"version":{"name":"1.20.4","protocol":765},"players":{"max":20,"online":1},` +
+		`"description":"A Minecraft Server","favicon":"` + favicon + `"}`)
+}
+
+func BenchmarkParseResponse(b *testing.B) {
+	raw := faviconResponse()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(raw)))
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseResponse(raw, ParseOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	raw := faviconResponse()
+	r := bytes.NewReader(raw)
+
+	b.Run("raw", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(raw)))
+		for i := 0; i < b.N; i++ {
+			r.Reset(raw)
+			if _, err := Decode(r); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("without raw", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(raw)))
+		for i := 0; i < b.N; i++ {
+			r.Reset(raw)
+			if _, err := DecodeWithOptions(r, ParseOptions{WithoutRaw: true}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package slp
+
+import "strings"
+
+// AnonymousPlayerName is the name vanilla servers use for players who disabled server listing.
+const AnonymousPlayerName = "Anonymous Player"
+
+// RealSample returns the sample entries that have a valid, non-nil UUID.
+// Servers commonly fake sample entries with nil UUIDs to display text in the player list.
+func (p *Players) RealSample() []Player {
+	var real []Player
+	for _, player := range p.Sample {
+		if player.IsReal() {
+			real = append(real, player)
+		}
+	}
+
+	return real
+}
+
+// IsAnonymized checks whether the sample contains players hidden by the server.
+func (p *Players) IsAnonymized() bool {
+	for _, player := range p.Sample {
+		if player.Name == AnonymousPlayerName {
+			if uuid, err := ParseUUID(player.ID); err == nil && uuid.IsNil() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ContainsPlayer checks whether the real sample contains a player with the given name.
+// The name is matched case-insensitively.
+func (p *Players) ContainsPlayer(name string) bool {
+	for _, player := range p.RealSample() {
+		if strings.EqualFold(player.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UUID parses the ID of the Player.
+func (p *Player) UUID() (UUID, error) {
+	return ParseUUID(p.ID)
+}
+
+// IsReal checks whether the Player has a valid, non-nil UUID.
+func (p *Player) IsReal() bool {
+	uuid, err := p.UUID()
+	return err == nil && !uuid.IsNil()
+}
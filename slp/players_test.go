@@ -0,0 +1,114 @@
+package slp
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestPlayersFakeSample(t *testing.T) {
+	raw, err := os.ReadFile("testdata/players/fake_sample.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	res, err := ParseResponse(raw, ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	real := res.Players.RealSample()
+	if len(real) != 2 || real[0].Name != "Notch" || real[1].Name != "jeb_" {
+		t.Errorf("real sample = %+v, want Notch and jeb_", real)
+	}
+
+	if !res.Players.IsAnonymized() {
+		t.Error("sample with an anonymous player is not anonymized")
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Notch", true},
+		{"notch", true},
+		{"JEB_", true},
+		{"Anonymous Player", false},
+		{"§6§lExample Network", false},
+		{"Dinnerbone", false},
+		{"Grumm", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := res.Players.ContainsPlayer(tt.name); got != tt.want {
+			t.Errorf("ContainsPlayer(%q) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlayersIsAnonymized(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample []Player
+		want   bool
+	}{
+		{"empty", nil, false},
+		{"real players", []Player{{Name: "Notch", ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}}, false},
+		{"anonymous", []Player{{Name: AnonymousPlayerName, ID: "00000000000000000000000000000000"}}, true},
+		{"anonymous name with a real uuid", []Player{{Name: AnonymousPlayerName, ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}}, false},
+		{"anonymous name with an invalid uuid", []Player{{Name: AnonymousPlayerName, ID: "0"}}, false},
+		{"lowercase name", []Player{{Name: "anonymous player", ID: "00000000-0000-0000-0000-000000000000"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Players{Sample: tt.sample}
+			if got := p.IsAnonymized(); got != tt.want {
+				t.Errorf("IsAnonymized() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSampleFixtures(t *testing.T) {
+	tests := []struct {
+		file     string
+		sample   []Player
+		warnings []string
+	}{
+		{file: "empty.json", sample: []Player{}},
+		{file: "null.json", warnings: []string{"players.sample is null"}},
+		{file: "object.json", warnings: []string{"players.sample is an object"}},
+		{file: "string.json", warnings: []string{`players.sample is not an array: "Notch, jeb_"`}},
+		{
+			file:     "names.json",
+			sample:   []Player{{Name: "Notch"}, {Name: "jeb_", ID: "853c80ef-3c37-49fd-aa49-938b674adae6"}},
+			warnings: []string{`players.sample[0] is a name: "Notch"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile("testdata/sample/" + tt.file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			res, err := ParseResponse(raw, ParseOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(res.Players.Sample, tt.sample) || (res.Players.Sample == nil) != (tt.sample == nil) {
+				t.Errorf("sample = %#v, want %#v", res.Players.Sample, tt.sample)
+			}
+			if !slices.Equal(res.Warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", res.Warnings, tt.warnings)
+			}
+			if res.Players.Online != 2 || res.Players.Max != 20 {
+				t.Errorf("players = %d/%d, want 2/20", res.Players.Online, res.Players.Max)
+			}
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package slp
 
 import (
-	"encoding/base64"
+	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -22,26 +23,274 @@ type Response struct {
 	EnforcesSecureChat bool        `json:"enforcesSecureChat,omitempty"`
 	PreviewsChat       bool        `json:"previewsChat,omitempty"`
 
+	// Set by the NoChatReports mod
+	// https://github.com/Aizistral-Studios/No-Chat-Reports
+	PreventsChatReports bool `json:"preventsChatReports,omitempty"`
+
 	// Forge related data
 	// https://wiki.vg/Minecraft_Forge_Handshake#Changes_to_Server_List_Ping
 	ForgeModInfo *LegacyForgeModInfo `json:"modinfo,omitempty"`   // Minecraft Forge 1.7 - 1.12
 	ForgeData    *ForgeData          `json:"forgeData,omitempty"` // Minecraft Forge 1.13 - Current
 
+	// Modpack related data sent by FTB and CurseForge modpack servers
+	ModpackData *ModpackData `json:"modpackData,omitempty"`
+
 	// Latency measured by the client
 	Latency int `json:"latency,omitempty"`
+
+	// Extra contains all top-level fields that are not mapped to a field of the Response.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// Warnings contains the anomalies found while parsing the response.
+	Warnings []string `json:"-"`
+
+	// Raw contains the response exactly as it was received.
+	Raw []byte `json:"-"`
+
+	// Legacy records whether the response was parsed from a pre-Netty legacy ping payload.
+	Legacy bool `json:"-"`
+
+	// secureChatReported records whether the enforcesSecureChat field was present.
+	secureChatReported bool
+}
+
+// responseFields contains the JSON names of all fields mapped by the Response.
+var responseFields = jsonFieldNames(reflect.TypeOf(Response{}))
+
+// UnmarshalJSON unmarshalls a status response.
+// Top-level fields that are not mapped to a field of the Response are collected into Extra.
+func (r *Response) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	// the preventsChatReports field is sent as a bool or an object depending on the mod version
+	type response Response
+	aux := struct {
+		*response
+		PreventsChatReports json.RawMessage `json:"preventsChatReports,omitempty"`
+	}{response: (*response)(r)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	r.PreventsChatReports = parseChatReports(aux.PreventsChatReports)
+	r.Warnings = append(r.Warnings, flexIntWarnings(fields)...)
+	r.Warnings = append(r.Warnings, r.Players.warnings...)
+	if r.ForgeData != nil && r.ForgeData.decodeErr != nil {
+		r.Warnings = append(r.Warnings, r.ForgeData.decodeErr.Error())
+	}
+
+	if r.Description.truncated {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("description exceeds the max component depth of %d", MaxComponentDepth))
+	}
+
+	for key, value := range fields {
+		if strings.EqualFold(key, "enforcesSecureChat") {
+			r.secureChatReported = true
+		}
+
+		if isResponseField(key) {
+			continue
+		}
+
+		if r.Extra == nil {
+			r.Extra = make(map[string]json.RawMessage)
+		}
+		r.Extra[key] = value
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals a Response including the fields collected in Extra.
+func (r Response) MarshalJSON() ([]byte, error) {
+	type response Response
+	b, err := json.Marshal(response(r))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.Extra) == 0 {
+		return b, nil
+	}
+
+	buf := bytes.NewBuffer(b[:len(b)-1])
+	for _, key := range sortedKeys(r.Extra) {
+		if isResponseField(key) {
+			continue
+		}
+
+		rawKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(rawKey)
+		buf.WriteByte(':')
+		buf.Write(r.Extra[key])
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// parseChatReports interprets the preventsChatReports field.
+// Objects are treated as present, since only the NoChatReports mod sends them.
+func parseChatReports(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return false
+	}
+
+	switch raw[0] {
+	case '{':
+		return true
+	case '"':
+		var s string
+		_ = json.Unmarshal(raw, &s)
+		return strings.EqualFold(s, "true")
+	}
+
+	return string(raw) == "true"
+}
+
+// isResponseField checks whether a top-level JSON key is mapped to a field of the Response.
+// The key is matched case-insensitively like encoding/json does.
+func isResponseField(key string) bool {
+	for _, field := range responseFields {
+		if strings.EqualFold(field, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// jsonFieldNames returns the JSON names of all fields of a struct type.
+func jsonFieldNames(t reflect.Type) []string {
+	return sortedKeys(jsonFields(t))
+}
+
+// jsonFields maps the JSON names of the fields of a struct type to the fields.
+// Unlike encoding/json, names are matched case-sensitively.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+
+	return fields
 }
 
 // Version represents the version information in the SLP response.
 type Version struct {
-	Name     string `json:"name"`
-	Protocol int    `json:"protocol"`
+	Name     string  `json:"name"`
+	Protocol FlexInt `json:"protocol"`
 }
 
 // Players represents player information in the SLP response.
 type Players struct {
-	Max    int      `json:"max"`
-	Online int      `json:"online"`
+	Max    FlexInt  `json:"max"`
+	Online FlexInt  `json:"online"`
 	Sample []Player `json:"sample,omitempty"`
+
+	// warnings contains the nonstandard sample shapes found while unmarshalling.
+	warnings []string
+}
+
+// UnmarshalJSON unmarshalls player information.
+// Besides an array of player objects, the sample may be null, an object or an array containing bare names.
+// Nonstandard sample shapes are recorded in the warnings of the Response.
+func (p *Players) UnmarshalJSON(b []byte) error {
+	type players Players
+	aux := struct {
+		*players
+		Sample json.RawMessage `json:"sample"`
+	}{players: (*players)(p)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	p.Sample, p.warnings, err = parseSample(aux.Sample)
+	return err
+}
+
+// parseSample parses the player sample and returns a warning for every nonstandard shape.
+// Bare names are turned into players with an empty ID, all other malformed entries are dropped.
+func parseSample(raw json.RawMessage) ([]Player, []string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil, nil
+	}
+
+	switch raw[0] {
+	case 'n':
+		return nil, []string{"players.sample is null"}, nil
+	case '{':
+		return nil, []string{"players.sample is an object"}, nil
+	case '[':
+	default:
+		return nil, []string{fmt.Sprintf("players.sample is not an array: %.32s", raw)}, nil
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	sample := make([]Player, 0, len(entries))
+	for i, entry := range entries {
+		entry = bytes.TrimSpace(entry)
+		switch entry[0] {
+		case '{':
+			var player Player
+			if err := json.Unmarshal(entry, &player); err != nil {
+				return nil, nil, err
+			}
+			sample = append(sample, player)
+
+		case '"':
+			var name string
+			if err := json.Unmarshal(entry, &name); err != nil {
+				return nil, nil, err
+			}
+			warnings = append(warnings, fmt.Sprintf("players.sample[%d] is a name: %q", i, name))
+			sample = append(sample, Player{Name: name})
+
+		default:
+			warnings = append(warnings, fmt.Sprintf("players.sample[%d] is not an object: %.32s", i, entry))
+		}
+	}
+
+	return sample, warnings, nil
 }
 
 // Player represents an individual player's information in the SLP response.
@@ -76,6 +325,14 @@ type ForgeData struct {
 	Channels          []ForgeChannel `json:"channels"`
 	Mods              []ForgeMod     `json:"mods"`
 	FMLNetworkVersion int            `json:"fmlNetworkVersion"`
+
+	// Forge 1.18.2 and newer compress the mod and channel lists into D
+	// and set Truncated if not all mods fit into the response.
+	Truncated bool   `json:"truncated,omitempty"`
+	D         string `json:"d,omitempty"`
+
+	// decodeErr is the error decoding D, which is reported as a warning of the response.
+	decodeErr error
 }
 
 // ForgeChannel represents a Forge mod channel in ForgeData.
@@ -103,10 +360,45 @@ type LegacyForgeMod struct {
 	Version string `json:"version"`
 }
 
+// ModpackData represents the modpack information in the SLP response.
+type ModpackData struct {
+	ProjectID  int    `json:"projectID"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	IsMetadata bool   `json:"isMetadata"`
+}
+
+// Modpack returns the modpack information or nil if the server does not report a modpack.
+func (r *Response) Modpack() *ModpackData {
+	return r.ModpackData
+}
+
 // Description represents a Description in the SLP response.
 // Description wraps a ChatComponent due to encoding limitations with dynamic JSON in go.
 type Description struct {
 	Description ChatComponent
+
+	// plain records whether the description is represented as a plain string.
+	plain bool
+
+	// truncated records whether components nested deeper than MaxComponentDepth were dropped.
+	truncated bool
+}
+
+// MaxComponentDepth is the maximum nesting depth of chat components.
+// Deeper nested components are dropped when unmarshalling to protect against malicious responses.
+var MaxComponentDepth = 32
+
+// SetPlain sets whether the Description is marshalled as a plain string instead of an object.
+// Descriptions parsed from a plain string are marshalled as a plain string by default.
+// A plain Description is only marshalled as a string as long as it contains nothing but text.
+func (d *Description) SetPlain(plain bool) {
+	d.plain = plain
+}
+
+// IsPlain reports whether the Description is marshalled as a plain string.
+func (d *Description) IsPlain() bool {
+	return d.plain
 }
 
 // String converts the Description into a string.
@@ -114,31 +406,131 @@ func (d *Description) String() string {
 	return d.Description.String()
 }
 
+// Clean converts the Description into a string without any formatting.
+func (d *Description) Clean() string {
+	return d.Description.Clean()
+}
+
+// CleanCompact converts the Description into a string without any formatting
+// and collapses consecutive whitespace into single spaces.
+func (d *Description) CleanCompact() string {
+	return d.Description.CleanCompact()
+}
+
+// Lines splits the Description into its lines.
+func (d *Description) Lines() []ChatComponent {
+	return d.Description.Lines()
+}
+
 // UnmarshalJSON unmarshalls a description into a ChatComponent.
-// The description can be represented as a ChatComponent or string.
+// The description can be represented as a ChatComponent, a string or an array of ChatComponents.
+// An array is unmarshalled into the Extra of an empty ChatComponent.
+// Empty input and null leave the description empty.
 func (d *Description) UnmarshalJSON(b []byte) error {
+	return d.unmarshal(b, 0)
+}
+
+// unmarshal unmarshalls a description nested at the given depth.
+// Components nested deeper than MaxComponentDepth are dropped and the description is marked as truncated.
+func (d *Description) unmarshal(b []byte, depth int) error {
 	// ToDo: translate color/formatting codes to JSON
 	// https://wiki.vg/Chat
 	// https://github.com/Sch8ill/rcon/blob/master/color/color.go
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || string(b) == "null" {
+		return nil
+	}
+
 	if b[0] == '"' {
 		var text string
 		if err := json.Unmarshal(b, &text); err != nil {
 			return err
 		}
 		d.Description.Text = text
+		d.plain = true
 
 		return nil
 	}
 
-	if err := json.Unmarshal(b, &d.Description); err != nil {
+	// some proxies send the description as an array of components
+	if b[0] == '[' {
+		var rawExtra []json.RawMessage
+		if err := json.Unmarshal(b, &rawExtra); err != nil {
+			return err
+		}
+
+		var err error
+		d.Description.Extra, d.truncated, err = unmarshalComponents(rawExtra, depth+1)
+		return err
+	}
+
+	if b[0] != '{' {
+		return fmt.Errorf("description has to be a string, an object or an array: %.32s", b)
+	}
+
+	type chatComponent ChatComponent
+	aux := struct {
+		*chatComponent
+		Extra      []json.RawMessage `json:"extra"`
+		With       []json.RawMessage `json:"with"`
+		HoverEvent json.RawMessage   `json:"hoverEvent"`
+	}{chatComponent: (*chatComponent)(&d.Description)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	var extraTruncated, withTruncated bool
+	var err error
+	if d.Description.Extra, extraTruncated, err = unmarshalComponents(aux.Extra, depth+1); err != nil {
 		return err
 	}
 
+	if d.Description.With, withTruncated, err = unmarshalComponents(aux.With, depth+1); err != nil {
+		return err
+	}
+	d.truncated = extraTruncated || withTruncated
+
+	if len(aux.HoverEvent) > 0 && string(aux.HoverEvent) != "null" {
+		d.Description.HoverEvent = new(HoverEvent)
+		if err := d.Description.HoverEvent.unmarshal(aux.HoverEvent, depth+1); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// unmarshalComponents unmarshalls a list of components nested at the given depth.
+// It reports whether the components were truncated because they exceed MaxComponentDepth.
+func unmarshalComponents(raw []json.RawMessage, depth int) ([]Description, bool, error) {
+	if len(raw) == 0 {
+		return nil, false, nil
+	}
+
+	if depth > MaxComponentDepth {
+		return nil, true, nil
+	}
+
+	var truncated bool
+	components := make([]Description, len(raw))
+	for i, rawComponent := range raw {
+		if err := components[i].unmarshal(rawComponent, depth); err != nil {
+			return nil, false, err
+		}
+		truncated = truncated || components[i].truncated
+	}
+
+	return components, truncated, nil
+}
+
 // MarshalJSON marshals a Description by returning a marshalled ChatComponent.
+// Plain descriptions containing nothing but text are marshalled as a string.
 func (d Description) MarshalJSON() ([]byte, error) {
+	if d.plain && d.Description.isText() {
+		return json.Marshal(d.Description.Text)
+	}
+
 	return json.Marshal(d.Description)
 }
 
@@ -156,16 +548,106 @@ type ChatComponent struct {
 	ClickEvent    *ClickEvent   `json:"clickEvent,omitempty"`
 	HoverEvent    *HoverEvent   `json:"hoverEvent,omitempty"`
 	Extra         []Description `json:"extra,omitempty"`
+
+	// Translate replaces Text with a translated string, whose placeholders are substituted from With.
+	// Fallback is used instead of the raw key if the translation is unknown (1.19.1+).
+	Translate string        `json:"translate,omitempty"`
+	With      []Description `json:"with,omitempty"`
+	Fallback  string        `json:"fallback,omitempty"`
 }
 
 // String converts the ChatComponent into a string.
+// The component tree is traversed iteratively, so deeply nested components cannot exhaust the stack.
 func (c *ChatComponent) String() string {
-	text := c.Text
-	for _, extra := range c.Extra {
-		text += extra.String()
+	type item struct {
+		text      string
+		component *ChatComponent
 	}
 
-	return text
+	var b strings.Builder
+	stack := []item{{component: c}}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if current.component == nil {
+			b.WriteString(current.text)
+			continue
+		}
+		comp := current.component
+
+		var items []item
+		if comp.Translate == "" {
+			items = append(items, item{text: comp.Text})
+		}
+
+		for _, part := range comp.translation() {
+			if part.arg < 0 {
+				items = append(items, item{text: part.text})
+			} else {
+				items = append(items, item{component: &comp.With[part.arg].Description})
+			}
+		}
+
+		for i := range comp.Extra {
+			items = append(items, item{component: &comp.Extra[i].Description})
+		}
+
+		for i := len(items) - 1; i >= 0; i-- {
+			stack = append(stack, items[i])
+		}
+	}
+
+	return b.String()
+}
+
+// isText checks whether the ChatComponent contains nothing but text.
+func (c ChatComponent) isText() bool {
+	c.Text = ""
+	return reflect.ValueOf(c).IsZero()
+}
+
+// Clean converts the ChatComponent into a string without any formatting.
+// Unlike String, Clean removes legacy formatting codes embedded in the text.
+func (c *ChatComponent) Clean() string {
+	var b strings.Builder
+	for _, seg := range c.segments() {
+		b.WriteString(seg.text)
+	}
+
+	return b.String()
+}
+
+// CleanCompact converts the ChatComponent into a string without any formatting
+// and collapses consecutive whitespace into single spaces.
+func (c *ChatComponent) CleanCompact() string {
+	return strings.Join(strings.Fields(c.Clean()), " ")
+}
+
+// Lines splits the ChatComponent at newline characters into one ChatComponent per line.
+// The formatting is preserved across the split, so text following a newline keeps the style
+// it had before, as rendered by the client. Legacy formatting codes are resolved into the
+// style fields of the returned components. A trailing newline results in an empty last line.
+func (c *ChatComponent) Lines() []ChatComponent {
+	lines := []ChatComponent{{}}
+
+	for _, seg := range c.segments() {
+		for i, text := range strings.Split(seg.text, "\n") {
+			if i > 0 {
+				lines = append(lines, ChatComponent{})
+			}
+			if text == "" {
+				continue
+			}
+
+			line := &lines[len(lines)-1]
+			part := segment{text: text, style: seg.style}
+			line.Extra = append(line.Extra, Description{Description: part.component()})
+		}
+	}
+
+	return lines
 }
 
 // ClickEvent represents click event inside a chat component.
@@ -176,43 +658,135 @@ type ClickEvent struct {
 
 // HoverEvent represents a hover event inside a chat component.
 type HoverEvent struct {
-	Action   string `json:"action"`
-	Contents string `json:"contents"`
+	Action   string         `json:"action"`
+	Contents *HoverContents `json:"contents,omitempty"`
+	Value    *HoverContents `json:"value,omitempty"` // used instead of contents before 1.16
 }
 
-// NewResponse parses a raw SLP response string into a Response struct.
-func NewResponse[T []byte | string](rawRes T) (*Response, error) {
-	res := new(Response)
-	if err := json.Unmarshal([]byte(rawRes), &res); err != nil {
-		return nil, err
+// unmarshal unmarshalls a HoverEvent nested at the given depth.
+func (h *HoverEvent) unmarshal(b []byte, depth int) error {
+	aux := struct {
+		Action   string          `json:"action"`
+		Contents json.RawMessage `json:"contents"`
+		Value    json.RawMessage `json:"value"`
+	}{}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	h.Action = aux.Action
+
+	if len(aux.Contents) > 0 {
+		h.Contents = new(HoverContents)
+		if err := h.Contents.unmarshal(aux.Contents, depth); err != nil {
+			return err
+		}
 	}
 
-	return res, nil
+	if len(aux.Value) > 0 {
+		h.Value = new(HoverContents)
+		if err := h.Value.unmarshal(aux.Value, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// String converts the response to a JSON string.
-func (r *Response) String() (string, error) {
-	res, err := json.Marshal(r)
-	if err != nil {
-		return "", fmt.Errorf("failed to convert to JSON: %w", err)
+// ContentsText returns the text shown by the HoverEvent.
+// It returns an empty string if the contents are not a chat component (e.g. an entity or item).
+func (h *HoverEvent) ContentsText() string {
+	contents := h.Contents
+	if contents == nil {
+		contents = h.Value
 	}
 
-	return string(res), nil
+	if contents == nil || contents.Component == nil {
+		return ""
+	}
+
+	return contents.Component.String()
+}
+
+// HoverContents represents the contents of a HoverEvent.
+// The contents can be a string or chat component (show_text) or an entity or item object.
+type HoverContents struct {
+	// Component is set if the contents are a string or chat component.
+	Component *Description
+
+	// Raw contains the contents as they were received.
+	Raw json.RawMessage
+}
+
+// UnmarshalJSON unmarshalls the contents of a HoverEvent.
+// Strings, arrays and objects containing chat component fields are parsed into Component.
+func (h *HoverContents) UnmarshalJSON(b []byte) error {
+	return h.unmarshal(b, 0)
 }
 
-// Icon decodes the favicon string into byte data.
-func (r *Response) Icon() ([]byte, error) {
-	if r.Favicon == "" {
-		return nil, errors.New("status response does not contain a favicon")
+// unmarshal unmarshalls the contents of a HoverEvent nested at the given depth.
+func (h *HoverContents) unmarshal(b []byte, depth int) error {
+	h.Raw = slices.Clone(b)
+
+	if !isChatComponent(b) || depth > MaxComponentDepth {
+		return nil
 	}
 
-	base64Icon := strings.TrimPrefix(r.Favicon, "data:image/png;base64,")
-	iconBytes, err := base64.StdEncoding.DecodeString(base64Icon)
+	h.Component = new(Description)
+	return h.Component.unmarshal(b, depth)
+}
+
+// MarshalJSON marshals the contents of a HoverEvent in the form they were received.
+// Contents without raw data are marshalled from Component.
+func (h HoverContents) MarshalJSON() ([]byte, error) {
+	if len(h.Raw) > 0 {
+		return h.Raw, nil
+	}
+
+	if h.Component != nil {
+		return json.Marshal(h.Component)
+	}
+
+	return []byte("null"), nil
+}
+
+// isChatComponent checks whether a JSON value is a string, an array or an object with chat component fields.
+func isChatComponent(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+
+	if b[0] == '"' || b[0] == '[' {
+		return true
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return false
+	}
+
+	for _, key := range []string{"text", "translate", "extra"} {
+		if _, ok := fields[key]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewResponse parses a raw SLP response string into a Response struct.
+func NewResponse[T []byte | string](rawRes T) (*Response, error) {
+	return ParseResponse(rawRes, ParseOptions{})
+}
+
+// String converts the response to a JSON string.
+func (r *Response) String() (string, error) {
+	res, err := json.Marshal(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert base64 image to bytes: %w", err)
+		return "", fmt.Errorf("failed to convert to JSON: %w", err)
 	}
 
-	return iconBytes, nil
+	return string(res), nil
 }
 
 // formatMinecraftUUID formats the given string as a Minecraft UUID.
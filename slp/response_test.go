@@ -0,0 +1,292 @@
+package slp
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDescriptionUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{`null`, "", true},
+		{`""`, "", true},
+		{`[]`, "", true},
+		{`{}`, "", true},
+		{`"hello"`, "hello", true},
+		{`["hello", {"text":" world"}]`, "hello world", true},
+		{`{"text":"hello","extra":[" world"]}`, "hello world", true},
+		{`{"text":`, "", false},
+		{`["hello"`, "", false},
+		{`42`, "", false},
+		{`true`, "", false},
+	}
+
+	for _, tt := range tests {
+		var d Description
+		err := d.UnmarshalJSON([]byte(tt.raw))
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("%s: error = %v, want ok = %t", tt.raw, err, tt.ok)
+			continue
+		}
+		if got := d.String(); tt.ok && got != tt.want {
+			t.Errorf("%s: description = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+
+	// an empty input does not reach UnmarshalJSON through encoding/json, but must not panic either
+	var d Description
+	if err := d.UnmarshalJSON(nil); err != nil {
+		t.Errorf("empty input: unexpected error: %v", err)
+	}
+}
+
+func TestNewResponseDescriptionRegression(t *testing.T) {
+	for _, description := range []string{`null`, `""`, `[]`, `{}`, `[null]`, `{"extra":null}`, `{"extra":[null]}`} {
+		raw := `{"version":{"name":"1.20.4","protocol":765},"description":` + description + `}`
+		if _, err := NewResponse(raw); err != nil {
+			t.Errorf("description %s: unexpected error: %v", description, err)
+		}
+	}
+}
+
+func FuzzNewResponse(f *testing.F) {
+	for _, seed := range []string{
+		minimalResponse,
+		`{"description":null}`,
+		`{"description":""}`,
+		`{"description":[]}`,
+		`{"description":{}}`,
+		`{"description":{"text":"a","extra":[{"text":"b","extra":["c"]}]}}`,
+		`{"description":{"translate":"%s","with":[{"text":"a"}]}}`,
+		`{"description":`,
+		`{"players":{"max":"20","online":1.5,"sample":[{"name":"a","id":"b"}]}}`,
+		``,
+		`null`,
+		`[]`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		res, err := NewResponse(raw)
+		if err != nil {
+			return
+		}
+
+		// every parsed response can be rendered and serialized again
+		_ = res.Description.String()
+		if _, err := json.Marshal(res); err != nil {
+			t.Fatalf("failed to marshal parsed response: %v", err)
+		}
+	})
+}
+
+// nestedDescription returns a description nested depth levels deep using the given kind of nesting.
+func nestedDescription(depth int, kind byte) string {
+	var open, close string
+	switch kind % 3 {
+	case 0:
+		open, close = `{"text":"a","extra":[`, `]}`
+	case 1:
+		open, close = `[`, `]`
+	default:
+		open, close = `{"translate":"%s","with":[`, `]}`
+	}
+
+	return strings.Repeat(open, depth) + `"leaf"` + strings.Repeat(close, depth)
+}
+
+// componentDepth returns the nesting depth of a component, counting the component itself.
+func componentDepth(c *ChatComponent) int {
+	var depth int
+	for _, children := range [][]Description{c.Extra, c.With} {
+		for i := range children {
+			depth = max(depth, componentDepth(&children[i].Description))
+		}
+	}
+
+	return depth + 1
+}
+
+func TestDescriptionMaxDepth(t *testing.T) {
+	for kind := byte(0); kind < 3; kind++ {
+		// encoding/json rejects input nested deeper than 10000 levels, an object with extra takes two per component
+		for _, depth := range []int{MaxComponentDepth - 1, MaxComponentDepth + 1, 4000} {
+			raw := `{"version":{"name":"1.20.4","protocol":765},"description":` + nestedDescription(depth, kind) + `}`
+			res, err := NewResponse(raw)
+			if err != nil {
+				t.Fatalf("kind %d, depth %d: unexpected error: %v", kind, depth, err)
+			}
+
+			if got := componentDepth(&res.Description.Description); got > MaxComponentDepth+1 {
+				t.Errorf("kind %d, depth %d: parsed %d levels", kind, depth, got)
+			}
+
+			truncated := depth >= MaxComponentDepth
+			warned := slices.ContainsFunc(res.Warnings, func(w string) bool { return strings.Contains(w, "max component depth") })
+			if warned != truncated {
+				t.Errorf("kind %d, depth %d: warnings = %q, want a depth warning: %t", kind, depth, res.Warnings, truncated)
+			}
+
+			_ = res.Description.String()
+		}
+	}
+}
+
+func FuzzNestedDescription(f *testing.F) {
+	f.Add(uint16(1), byte(0))
+	f.Add(uint16(MaxComponentDepth), byte(1))
+	f.Add(uint16(5000), byte(2))
+
+	f.Fuzz(func(t *testing.T, depth uint16, kind byte) {
+		// too deeply nested input may be rejected by encoding/json, but must not panic
+		res, err := NewResponse(`{"description":` + nestedDescription(int(depth), kind) + `}`)
+		if err != nil {
+			return
+		}
+
+		if got := componentDepth(&res.Description.Description); got > MaxComponentDepth+1 {
+			t.Fatalf("parsed %d levels", got)
+		}
+		_ = res.Description.String()
+	})
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		clean   string
+		compact string
+	}{
+		{
+			name:    "legacy string",
+			raw:     `"§6§lHypixel §r§7Network  §c[1.8-1.20]\n§e§lEVENT"`,
+			clean:   "Hypixel Network  [1.8-1.20]\nEVENT",
+			compact: "Hypixel Network [1.8-1.20] EVENT",
+		},
+		{
+			name:    "legacy codes inside json",
+			raw:     `{"text":"§aGreen ","bold":true,"extra":[{"text":"§kmagic§r ","color":"red"},"§x§f§f§0§0§0§0hex"]}`,
+			clean:   "Green magic hex",
+			compact: "Green magic hex",
+		},
+		{
+			name:    "uppercase, unknown and trailing codes",
+			raw:     `{"text":"§Lbold §zkept§"}`,
+			clean:   "bold §zkept§",
+			compact: "bold §zkept§",
+		},
+		{
+			name:    "hex ampersand codes",
+			raw:     `{"text":"&#ff0000Red & &#12345 broken"}`,
+			clean:   "Red & &#12345 broken",
+			compact: "Red & &#12345 broken",
+		},
+		{
+			name:    "translation with legacy arguments",
+			raw:     `{"translate":"disconnect.loginFailedInfo","with":[{"text":"§bInvalid session"}],"extra":[{"text":"\n  §7try again  "}]}`,
+			clean:   "Failed to log in: Invalid session\n  try again  ",
+			compact: "Failed to log in: Invalid session try again",
+		},
+		{
+			name:    "array description",
+			raw:     `["",{"text":"§cA "},{"text":"Server","color":"gold"},"\t§8(1.20)"]`,
+			clean:   "A Server\t(1.20)",
+			compact: "A Server (1.20)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Description
+			if err := json.Unmarshal([]byte(tt.raw), &d); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := d.Description.Clean(); got != tt.clean {
+				t.Errorf("Clean() = %q, want %q", got, tt.clean)
+			}
+			if got := d.Description.CleanCompact(); got != tt.compact {
+				t.Errorf("CleanCompact() = %q, want %q", got, tt.compact)
+			}
+		})
+	}
+}
+
+func TestArrayDescriptionFixtures(t *testing.T) {
+	tests := []struct {
+		file  string
+		clean string
+		// segment is a piece of the description and its expected style
+		segment ChatComponent
+	}{
+		{
+			file:    "components.json",
+			clean:   "        ExampleNetwork [1.8-1.20]\n    SkyBlock & Bedwars now open!",
+			segment: ChatComponent{Text: "Example", Color: "gold", Bold: true},
+		},
+		{
+			file:    "legacy_strings.json",
+			clean:   "Example Network [1.8-1.20]\nSummer event is live!",
+			segment: ChatComponent{Text: "Summer event ", Color: "green"},
+		},
+		{
+			file:    "nested.json",
+			clean:   "Maintenance - back soon (discord.gg/example)",
+			segment: ChatComponent{Text: "discord.gg/example", Color: "blue", Underlined: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile("testdata/bungeecord/" + tt.file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			res, err := ParseResponse(raw, ParseOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := res.Description.Clean(); got != tt.clean {
+				t.Errorf("Clean() = %q, want %q", got, tt.clean)
+			}
+
+			var found bool
+			for _, line := range res.Description.Lines() {
+				for _, part := range line.Extra {
+					found = found || reflect.DeepEqual(part.Description, tt.segment)
+				}
+			}
+			if !found {
+				t.Errorf("no segment %+v in %+v", tt.segment, res.Description.Lines())
+			}
+
+			// the description is marshalled as an object holding the array in extra
+			b, err := json.Marshal(&res.Description)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b[0] != '{' {
+				t.Errorf("marshalled description = %s, want an object", b)
+			}
+
+			var d Description
+			if err := json.Unmarshal(b, &d); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := d.Clean(); got != tt.clean {
+				t.Errorf("Clean() after a round trip = %q, want %q", got, tt.clean)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package slp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// requiredFields lists the paths of the fields every conformant status response contains.
+var requiredFields = [][]string{
+	{"version"},
+	{"version", "name"},
+	{"version", "protocol"},
+	{"players"},
+	{"players", "max"},
+	{"players", "online"},
+	{"description"},
+}
+
+var (
+	descriptionType   = reflect.TypeOf(Description{})
+	chatComponentType = reflect.TypeOf(ChatComponent{})
+	hoverContentsType = reflect.TypeOf(HoverContents{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+)
+
+// ParseStrict parses a raw SLP response string into a Response struct, rejecting every response
+// that does not conform to the format sent by vanilla servers.
+// It fails if the response contains a field unknown to the Response at any depth
+// or lacks one of the fields vanilla servers always send.
+func ParseStrict[T []byte | string](rawRes T) (*Response, error) {
+	return ParseResponse(rawRes, ParseOptions{Strict: true})
+}
+
+// checkStrict checks a raw status response for unknown and missing fields.
+func checkStrict(raw []byte) error {
+	var res any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&res); err != nil {
+		return err
+	}
+
+	var unknown []string
+	collectUnknownFields(res, reflect.TypeOf(Response{}), "", &unknown)
+	if len(unknown) > 0 {
+		return fmt.Errorf("response contains unknown fields: %s", strings.Join(unknown, ", "))
+	}
+
+	for _, path := range requiredFields {
+		if !hasField(res, path) {
+			return fmt.Errorf("response is missing required field: %s", strings.Join(path, "."))
+		}
+	}
+
+	return nil
+}
+
+// collectUnknownFields appends the paths of all object keys in value that are not mapped to a field of t.
+func collectUnknownFields(value any, t reflect.Type, path string, unknown *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case hoverContentsType, rawMessageType:
+		// kept as received
+		return
+
+	case descriptionType:
+		switch v := value.(type) {
+		case []any:
+			for i, elem := range v {
+				collectUnknownFields(elem, descriptionType, fmt.Sprintf("%s[%d]", path, i), unknown)
+			}
+		case map[string]any:
+			collectUnknownFields(v, chatComponentType, path, unknown)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(obj) {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+
+			field, ok := fields[key]
+			if !ok {
+				*unknown = append(*unknown, fieldPath)
+				continue
+			}
+			collectUnknownFields(obj[key], field.Type, fieldPath, unknown)
+		}
+
+	case reflect.Slice, reflect.Array:
+		elems, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, elem := range elems {
+			collectUnknownFields(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i), unknown)
+		}
+
+	case reflect.Map:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(obj) {
+			collectUnknownFields(obj[key], t.Elem(), path+"."+key, unknown)
+		}
+	}
+}
+
+// hasField checks whether the object path is present in a decoded JSON value.
+func hasField(value any, path []string) bool {
+	for _, key := range path {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		if value, ok = obj[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
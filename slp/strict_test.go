@@ -0,0 +1,64 @@
+package slp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseStrictConformant(t *testing.T) {
+	files, err := filepath.Glob("testdata/strict/conformant/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures: %v", err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			if _, err := ParseStrict(raw); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseStrictFailing(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"unknown_top_level.json", "unknown fields: isModded"},
+		{"unknown_version_field.json", "unknown fields: version.brand"},
+		{"unknown_sample_field.json", "unknown fields: players.sample[0].skin"},
+		{"unknown_component_field.json", "unknown fields: description.extra[0].colour"},
+		{"missing_max.json", "missing required field: players.max"},
+		{"missing_protocol.json", "missing required field: version.protocol"},
+		{"missing_description.json", "missing required field: description"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile("testdata/strict/failing/" + tt.file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			_, err = ParseStrict(raw)
+			var invalid *ErrInvalidResponse
+			if !errors.As(err, &invalid) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want an ErrInvalidResponse containing %q", err, tt.want)
+			}
+
+			// the default parsing accepts the response
+			if _, err := ParseResponse(raw, ParseOptions{}); err != nil {
+				t.Errorf("default parsing: unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package slp
+
+// style represents the effective formatting of a piece of text.
+type style struct {
+	color         string
+	bold          bool
+	italic        bool
+	underlined    bool
+	strikethrough bool
+	obfuscated    bool
+}
+
+// segment represents a piece of text with a uniform style.
+type segment struct {
+	text  string
+	style style
+}
+
+// inherit returns the style of a ChatComponent whose parent has the style s.
+func (s style) inherit(c *ChatComponent) style {
+	if c.Color != "" {
+		s.color = c.Color
+	}
+	s.bold = s.bold || c.Bold
+	s.italic = s.italic || c.Italic
+	s.underlined = s.underlined || c.Underlined
+	s.strikethrough = s.strikethrough || c.Strikethrough
+	s.obfuscated = s.obfuscated || c.Obfuscated
+
+	return s
+}
+
+// segments flattens the ChatComponent tree into uniformly styled segments.
+// Legacy formatting codes embedded in the text are resolved.
+// The tree is walked with an explicit stack like String, so deeply nested components cannot exhaust the call stack.
+func (c *ChatComponent) segments() []segment {
+	type item struct {
+		text      string
+		style     style
+		component *ChatComponent
+	}
+
+	var segs []segment
+	stack := []item{{component: c}}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if current.component == nil {
+			segs = append(segs, parseLegacy(current.text, current.style)...)
+			continue
+		}
+		comp := current.component
+		s := current.style.inherit(comp)
+
+		var items []item
+		if comp.Translate == "" {
+			items = append(items, item{text: comp.Text, style: s})
+		}
+
+		for _, part := range comp.translation() {
+			if part.arg < 0 {
+				items = append(items, item{text: part.text, style: s})
+			} else {
+				items = append(items, item{style: s, component: &comp.With[part.arg].Description})
+			}
+		}
+
+		for i := range comp.Extra {
+			items = append(items, item{style: s, component: &comp.Extra[i].Description})
+		}
+
+		for i := len(items) - 1; i >= 0; i-- {
+			stack = append(stack, items[i])
+		}
+	}
+
+	return segs
+}
+
+// component converts the segment into a ChatComponent with an explicit style.
+func (s segment) component() ChatComponent {
+	return ChatComponent{
+		Text:          s.text,
+		Color:         s.style.color,
+		Bold:          s.style.bold,
+		Italic:        s.style.italic,
+		Underlined:    s.style.underlined,
+		Strikethrough: s.style.strikethrough,
+		Obfuscated:    s.style.obfuscated,
+	}
+}
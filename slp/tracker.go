@@ -0,0 +1,105 @@
+package slp
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObservedPlayer represents a player seen in the sample of one or more status responses.
+type ObservedPlayer struct {
+	Name      string
+	ID        string
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// Count is the number of observed responses the player was part of.
+	Count int
+}
+
+// PlayerTracker accumulates the players seen in the samples of consecutive status responses.
+// Servers only send a rotating subset of their online players, so observing a server over time
+// reveals far more players than a single status response.
+// A PlayerTracker is safe for concurrent use. The zero value is an empty PlayerTracker ready to use.
+type PlayerTracker struct {
+	mu      sync.Mutex
+	players map[string]*ObservedPlayer
+}
+
+// NewPlayerTracker creates an empty PlayerTracker.
+func NewPlayerTracker() *PlayerTracker {
+	return &PlayerTracker{players: make(map[string]*ObservedPlayer)}
+}
+
+// Observe records the players in the sample of a status response.
+// Players are deduplicated by their UUID if it is valid and by their name otherwise.
+// Fake entries with a nil UUID or a name containing formatting codes are ignored.
+func (t *PlayerTracker) Observe(res *Response) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.players == nil {
+		t.players = make(map[string]*ObservedPlayer)
+	}
+
+	seen := make(map[string]bool)
+	for _, player := range res.Players.Sample {
+		key, ok := trackingKey(player)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		observed, ok := t.players[key]
+		if !ok {
+			observed = &ObservedPlayer{ID: player.ID, FirstSeen: now}
+			t.players[key] = observed
+		}
+
+		observed.Name = player.Name
+		observed.LastSeen = now
+		observed.Count++
+	}
+}
+
+// Players returns all observed players ordered by the time they were first seen.
+func (t *PlayerTracker) Players() []ObservedPlayer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	players := make([]ObservedPlayer, 0, len(t.players))
+	for _, player := range t.players {
+		players = append(players, *player)
+	}
+
+	slices.SortFunc(players, func(a, b ObservedPlayer) int {
+		if c := a.FirstSeen.Compare(b.FirstSeen); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return players
+}
+
+// trackingKey returns the key a sample entry is deduplicated by.
+// It returns ok=false for fake entries.
+func trackingKey(player Player) (string, bool) {
+	if player.Name == "" || strings.ContainsRune(player.Name, LegacyPrefix) {
+		return "", false
+	}
+
+	uuid, err := player.UUID()
+	if err != nil {
+		return "name:" + strings.ToLower(player.Name), true
+	}
+
+	if uuid.IsNil() {
+		return "", false
+	}
+
+	return "uuid:" + uuid.String(), true
+}
@@ -0,0 +1,30 @@
+package slp
+
+import "testing"
+
+func TestPlayerTrackerZeroValue(t *testing.T) {
+	var tracker PlayerTracker
+	if players := tracker.Players(); len(players) != 0 {
+		t.Fatalf("players = %+v, want none", players)
+	}
+
+	res := new(Response)
+	res.Players.Sample = []Player{
+		{Name: "Notch", ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"},
+		{Name: "jeb_", ID: "853c80ef-3c37-49fd-aa49-938b674adae6"},
+		{Name: "§cfake", ID: "00000000-0000-0000-0000-000000000000"},
+	}
+
+	tracker.Observe(res)
+	tracker.Observe(res)
+
+	players := tracker.Players()
+	if len(players) != 2 {
+		t.Fatalf("players = %+v, want 2", players)
+	}
+	for _, player := range players {
+		if player.Count != 2 {
+			t.Errorf("player %s observed %d times, want 2", player.Name, player.Count)
+		}
+	}
+}
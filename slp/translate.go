@@ -0,0 +1,118 @@
+package slp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// translations contains the en_us translations of the most common multiplayer and disconnect keys.
+// https://minecraft.wiki/w/Resource_pack#Language
+var translations = map[string]string{
+	"disconnect.closed":                                 "Connection closed",
+	"disconnect.disconnected":                           "Disconnected by Server",
+	"disconnect.endOfStream":                            "End of stream",
+	"disconnect.genericReason":                          "%s",
+	"disconnect.kicked":                                 "Was kicked from the game",
+	"disconnect.loginFailed":                            "Failed to log in",
+	"disconnect.loginFailedInfo":                        "Failed to log in: %s",
+	"disconnect.loginFailedInfo.insufficientPrivileges": "Multiplayer is disabled. Please check your Microsoft account settings.",
+	"disconnect.loginFailedInfo.invalidSession":         "Invalid session (Try restarting your game and the launcher)",
+	"disconnect.loginFailedInfo.serversUnavailable":     "The authentication servers are currently not reachable. Please try again.",
+	"disconnect.lost":                                   "Connection Lost",
+	"disconnect.overflow":                               "Buffer overflow",
+	"disconnect.quitting":                               "Quitting",
+	"disconnect.spam":                                   "Kicked for spamming",
+	"disconnect.timeout":                                "Timed out",
+	"multiplayer.disconnect.authservers_down":           "Authentication servers are down. Please try again later, sorry!",
+	"multiplayer.disconnect.banned":                     "You are banned from this server",
+	"multiplayer.disconnect.banned.reason":              "You are banned from this server.\nReason: %s",
+	"multiplayer.disconnect.banned_ip.reason":           "Your IP address is banned from this server.\nReason: %s",
+	"multiplayer.disconnect.duplicate_login":            "You logged in from another location",
+	"multiplayer.disconnect.idling":                     "You have been idle for too long!",
+	"multiplayer.disconnect.illegal_characters":         "Illegal characters in chat",
+	"multiplayer.disconnect.incompatible":               "Incompatible client! Please use %s",
+	"multiplayer.disconnect.invalid_player_data":        "Invalid player data",
+	"multiplayer.disconnect.kicked":                     "Kicked by an operator",
+	"multiplayer.disconnect.name_taken":                 "That name is already taken",
+	"multiplayer.disconnect.not_whitelisted":            "You are not white-listed on this server!",
+	"multiplayer.disconnect.outdated_client":            "Incompatible client! Please use %s",
+	"multiplayer.disconnect.outdated_server":            "Incompatible client! Please use %s",
+	"multiplayer.disconnect.server_full":                "The server is full!",
+	"multiplayer.disconnect.server_shutdown":            "Server closed",
+	"multiplayer.disconnect.unverified_username":        "Failed to verify username!",
+}
+
+// translationPart represents either a literal text or a placeholder referencing an argument of With.
+type translationPart struct {
+	text string
+	arg  int // index into With or -1 for literal text
+}
+
+// translation splits the translated text of the ChatComponent into literal text and placeholders.
+// Fallback or the raw key is used if the translation is unknown.
+// It returns nil if the ChatComponent has no translation key.
+func (c *ChatComponent) translation() []translationPart {
+	if c.Translate == "" {
+		return nil
+	}
+
+	format, ok := translations[c.Translate]
+	if !ok {
+		if c.Fallback == "" {
+			return []translationPart{{text: c.Translate, arg: -1}}
+		}
+		format = c.Fallback
+	}
+
+	return splitTranslation(format, len(c.With))
+}
+
+// splitTranslation splits a translation format into literal text and placeholders.
+// It supports sequential (%s) and positional (%1$s) placeholders as well as escaped percent signs (%%).
+// Placeholders referencing missing arguments are kept as literal text.
+func splitTranslation(format string, args int) []translationPart {
+	var parts []translationPart
+	var literal strings.Builder
+	next := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 >= len(format) {
+			literal.WriteByte(format[i])
+			continue
+		}
+
+		if format[i+1] == '%' {
+			literal.WriteByte('%')
+			i++
+			continue
+		}
+
+		arg, length := -1, 0
+		if format[i+1] == 's' {
+			arg, length = next, 2
+			next++
+		} else if end := strings.Index(format[i+1:], "$s"); end > 0 {
+			if n, err := strconv.Atoi(format[i+1 : i+1+end]); err == nil && n > 0 {
+				arg, length = n-1, end+3
+			}
+		}
+
+		if length == 0 || arg >= args {
+			literal.WriteByte(format[i])
+			continue
+		}
+
+		if literal.Len() > 0 {
+			parts = append(parts, translationPart{text: literal.String(), arg: -1})
+			literal.Reset()
+		}
+		parts = append(parts, translationPart{arg: arg})
+		i += length - 1
+	}
+
+	if literal.Len() > 0 {
+		parts = append(parts, translationPart{text: literal.String(), arg: -1})
+	}
+
+	return parts
+}
@@ -0,0 +1,24 @@
+package slp
+
+import "github.com/sch8ill/mclib/packet"
+
+// UUID represents a player UUID.
+type UUID [16]byte
+
+// NilUUID is the all-zero UUID used by servers for fake player sample entries.
+var NilUUID UUID
+
+// ParseUUID parses a UUID in the dashed (8-4-4-4-12) or undashed form.
+func ParseUUID(s string) (UUID, error) {
+	return packet.ParseUUID(s)
+}
+
+// IsNil checks whether the UUID is the all-zero UUID.
+func (u UUID) IsNil() bool {
+	return u == NilUUID
+}
+
+// String returns the UUID in the dashed form.
+func (u UUID) String() string {
+	return packet.FormatUUID(u)
+}
@@ -0,0 +1,61 @@
+package slp
+
+import "testing"
+
+func TestParseUUID(t *testing.T) {
+	notch := UUID{0x06, 0x9a, 0x79, 0xf4, 0x44, 0xe9, 0x47, 0x26, 0xa5, 0xbe, 0xfc, 0xa9, 0x0e, 0x38, 0xaa, 0xf5}
+
+	tests := []struct {
+		s    string
+		want UUID
+		ok   bool
+	}{
+		{"069a79f4-44e9-4726-a5be-fca90e38aaf5", notch, true},
+		{"069a79f444e94726a5befca90e38aaf5", notch, true},
+		{"069A79F4-44E9-4726-A5BE-FCA90E38AAF5", notch, true},
+		{"00000000-0000-0000-0000-000000000000", NilUUID, true},
+		{"00000000000000000000000000000000", NilUUID, true},
+		{"", UUID{}, false},
+		{"069a79f4-44e9-4726-a5be-fca90e38aaf", UUID{}, false},
+		{"069a79f4444e9-4726-a5be-fca90e38aaf5", UUID{}, false},
+		{"069a79f4-44e9-4726-a5be-fca90e38aa-5", UUID{}, false},
+		{"g69a79f444e94726a5befca90e38aaf5", UUID{}, false},
+		{"{069a79f4-44e9-4726-a5be-fca90e38aaf5}", UUID{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseUUID(tt.s)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("ParseUUID(%q) error = %v, want ok = %t", tt.s, err, tt.ok)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseUUID(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestUUID(t *testing.T) {
+	if !NilUUID.IsNil() {
+		t.Error("NilUUID is not nil")
+	}
+	if got := NilUUID.String(); got != "00000000-0000-0000-0000-000000000000" {
+		t.Errorf("NilUUID.String() = %q", got)
+	}
+
+	uuid, err := ParseUUID("853C80EF3C3749FDAA49938B674ADAE6")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if uuid.IsNil() {
+		t.Error("non-zero uuid is nil")
+	}
+	if got := uuid.String(); got != "853c80ef-3c37-49fd-aa49-938b674adae6" {
+		t.Errorf("String() = %q, want the lowercase dashed form", got)
+	}
+
+	player := Player{Name: "jeb_", ID: uuid.String()}
+	if got, err := player.UUID(); err != nil || got != uuid {
+		t.Errorf("Player.UUID() = %s, %v, want %s", got, err, uuid)
+	}
+}
@@ -0,0 +1,117 @@
+package slp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxDescriptionLines is the number of description lines displayed by the client.
+	MaxDescriptionLines int = 2
+
+	// MaxDescriptionLength is the max length of a protocol string, which bounds the description.
+	MaxDescriptionLength int = 32767
+)
+
+// Severity represents the severity of a Problem.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the name of the Severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+
+	return "info"
+}
+
+// Machine-readable codes of the problems found by Validate.
+const (
+	ProblemFaviconFormat     = "favicon_format"
+	ProblemFaviconEncoding   = "favicon_encoding"
+	ProblemFaviconImage      = "favicon_image"
+	ProblemFaviconSize       = "favicon_size"
+	ProblemSampleUUID        = "sample_uuid"
+	ProblemUnknownProtocol   = "unknown_protocol"
+	ProblemDescriptionLines  = "description_lines"
+	ProblemDescriptionLength = "description_length"
+	ProblemPlayersNegative   = "players_negative"
+	ProblemPlayersOverflow   = "players_overflow"
+)
+
+// Problem represents a violation of a protocol constraint found in a Response.
+type Problem struct {
+	Severity Severity
+	Code     string
+	Message  string
+}
+
+// String returns a human-readable representation of the Problem.
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Code, p.Message)
+}
+
+// Validate checks a successfully parsed Response against the constraints of the protocol.
+// The returned problems can be used to identify servers that send forged or broken responses.
+func Validate(res *Response) []Problem {
+	var problems []Problem
+	add := func(severity Severity, code, format string, args ...any) {
+		problems = append(problems, Problem{Severity: severity, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if res.Favicon != "" {
+		if !strings.HasPrefix(res.Favicon, "data:image/png;base64,") {
+			add(SeverityWarning, ProblemFaviconFormat, "favicon is not a PNG data URL")
+		}
+
+		var sizeErr *IconSizeError
+		_, err := res.IconImage()
+		switch {
+		case errors.As(err, &sizeErr):
+			add(SeverityWarning, ProblemFaviconSize, "%s", sizeErr)
+		case errors.Is(err, ErrIconEncoding):
+			add(SeverityError, ProblemFaviconEncoding, "%s", err)
+		case errors.Is(err, ErrIconImage):
+			add(SeverityError, ProblemFaviconImage, "%s", err)
+		}
+	}
+
+	for i, player := range res.Players.Sample {
+		if _, err := player.UUID(); err != nil {
+			add(SeverityWarning, ProblemSampleUUID, "sample player %d (%q) has an invalid uuid: %q", i, player.Name, player.ID)
+		}
+	}
+
+	if !IsKnownProtocol(int(res.Version.Protocol)) {
+		add(SeverityInfo, ProblemUnknownProtocol, "unknown protocol version: %d", res.Version.Protocol)
+	}
+
+	if lines := len(res.Description.Lines()); lines > MaxDescriptionLines {
+		add(SeverityInfo, ProblemDescriptionLines, "description has %d lines, only %d are displayed", lines, MaxDescriptionLines)
+	}
+
+	if length := utf8.RuneCountInString(res.Description.String()); length > MaxDescriptionLength {
+		add(SeverityError, ProblemDescriptionLength, "description exceeds the max string length: %d", length)
+	}
+
+	if res.Players.Online < 0 || res.Players.Max < 0 {
+		add(SeverityError, ProblemPlayersNegative, "negative player count: %d/%d", res.Players.Online, res.Players.Max)
+	}
+
+	if res.Players.Online > res.Players.Max {
+		add(SeverityInfo, ProblemPlayersOverflow, "more players online than allowed: %d/%d", res.Players.Online, res.Players.Max)
+	}
+
+	return problems
+}
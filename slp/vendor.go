@@ -0,0 +1,96 @@
+package slp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// VendorKey describes how a vendor specific top-level field of the status response is interpreted.
+type VendorKey struct {
+	// Software is reported if the field is present.
+	Software string
+
+	// Build marks the field as containing the build of the software.
+	Build bool
+}
+
+// VendorKeys maps vendor specific top-level fields to their interpretation.
+// Additional keys can be registered by adding them to the map.
+// The map must not be modified concurrently with calls to VendorInfo.
+var VendorKeys = map[string]VendorKey{
+	"purpur":      {Software: "Purpur"},
+	"purpurBuild": {Software: "Purpur", Build: true},
+	"paper":       {Software: "Paper"},
+	"paperBuild":  {Software: "Paper", Build: true},
+}
+
+// Vendor contains the vendor specific data of a status response.
+type Vendor struct {
+	Software string
+	Build    string
+
+	// Values contains the values of all recognized fields.
+	// Fields of objects are flattened into dotted keys (e.g. "paper.build").
+	Values map[string]string
+}
+
+// VendorInfo extracts the vendor specific data from the fields of a Response collected in Extra.
+// It returns nil if the Response does not contain any recognized field.
+// Fields that are not recognized remain accessible through Response.Extra.
+func VendorInfo(res *Response) *Vendor {
+	var vendor *Vendor
+
+	for _, key := range sortedKeys(res.Extra) {
+		vendorKey, ok := VendorKeys[key]
+		if !ok {
+			continue
+		}
+
+		if vendor == nil {
+			vendor = &Vendor{Values: make(map[string]string)}
+		}
+
+		if vendor.Software == "" {
+			vendor.Software = vendorKey.Software
+		}
+
+		flattenVendorValue(vendor.Values, key, res.Extra[key])
+
+		if build, ok := vendor.Values[key]; ok && vendorKey.Build && vendor.Build == "" {
+			vendor.Build = build
+		}
+		if build, ok := vendor.Values[key+".build"]; ok && vendor.Build == "" {
+			vendor.Build = build
+		}
+	}
+
+	return vendor
+}
+
+// flattenVendorValue stores a JSON value in values. Objects are flattened into dotted keys.
+func flattenVendorValue(values map[string]string, key string, raw json.RawMessage) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return
+	}
+
+	switch raw[0] {
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return
+		}
+
+		for field, value := range fields {
+			flattenVendorValue(values, key+"."+field, value)
+		}
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			values[key] = s
+		}
+	default:
+		values[key] = strings.TrimSpace(string(raw))
+	}
+}
@@ -0,0 +1,72 @@
+package slp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// versionPattern matches a single Minecraft version like "1.20.4" or "1.8.x".
+const versionPattern = `\d+\.\d+(?:\.(?:\d+|x))?`
+
+// versionNamePatterns is the table of known version name formats.
+// Each pattern may capture the software brand as "software" and must capture the version range as "min" and "max"
+// or a single version as "min".
+var versionNamePatterns = []*regexp.Regexp{
+	// Velocity 1.7.2-1.21.1, BungeeCord 1.8.x-1.20.x
+	regexp.MustCompile(`^(?:(?P<software>[A-Za-z][\w.\-]*?) )?(?P<min>` + versionPattern + `)\s*-\s*(?P<max>` + versionPattern + `)$`),
+	// BungeeCord 1.8.x, 1.9.x, 1.10.x
+	regexp.MustCompile(`^(?:(?P<software>[A-Za-z][\w.\-]*?) )?(?P<min>` + versionPattern + `)(?:,\s*` + versionPattern + `)*,\s*(?P<max>` + versionPattern + `)$`),
+	// Paper 1.20.4, 1.20.4
+	regexp.MustCompile(`^(?:(?P<software>[A-Za-z][\w.\-]*?) )?(?P<min>` + versionPattern + `)$`),
+	// git-Paper-196 (MC: 1.16.5)
+	regexp.MustCompile(`^git-(?P<software>[A-Za-z]+)-[\w.]+ \(MC: (?P<min>` + versionPattern + `)\)$`),
+}
+
+// Range parses the version name into the range of supported versions.
+// Names advertising a single version return it as both min and max.
+// It returns ok=false if the name does not match any known format.
+func (v *Version) Range() (min, max string, ok bool) {
+	groups, ok := v.parseName()
+	if !ok {
+		return "", "", false
+	}
+
+	min = groups["min"]
+	max = groups["max"]
+	if max == "" {
+		max = min
+	}
+
+	return min, max, true
+}
+
+// Software extracts the software brand from the version name (e.g. "Velocity" from "Velocity 1.7.2-1.21.1").
+// It returns an empty string if the name does not contain a brand or does not match any known format.
+func (v *Version) Software() string {
+	groups, _ := v.parseName()
+	return groups["software"]
+}
+
+// parseName matches the version name against the known version name formats.
+func (v *Version) parseName() (map[string]string, bool) {
+	name := ParseLegacyText(v.Name)
+	clean := strings.TrimSpace(name.CleanCompact())
+
+	for _, pattern := range versionNamePatterns {
+		match := pattern.FindStringSubmatch(clean)
+		if match == nil {
+			continue
+		}
+
+		groups := make(map[string]string)
+		for i, group := range pattern.SubexpNames() {
+			if group != "" {
+				groups[group] = match[i]
+			}
+		}
+
+		return groups, true
+	}
+
+	return nil, false
+}